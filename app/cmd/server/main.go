@@ -13,8 +13,7 @@ import (
 )
 
 func main() {
-	_, srv, cleanup, log := server.Build()
-	defer cleanup()
+	cfg, srv, readiness, cleanup, log := server.Build()
 
 	log.Info("service starting")
 
@@ -31,13 +30,21 @@ func main() {
 
 	log.Info("shutting down service...")
 
+	// Fail readiness first and give load balancers time to stop sending new requests.
+	readiness.Shutdown()
+	time.Sleep(cfg.ShutdownDrainDelay)
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Error("server forced to shutdown", "error", err)
+		cleanup()
 		os.Exit(1)
 	}
 
+	// Stops the outbox relay before closing the broker and database connections.
+	cleanup()
+
 	log.Info("service stopped cleanly")
 }
@@ -2,33 +2,121 @@ package config
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/alfattd/category-service/internal/eventqueue"
 	pkgconfig "github.com/alfattd/category-service/internal/pkg/config"
+	"github.com/alfattd/category-service/internal/pkg/rabbitmq"
+	"github.com/alfattd/category-service/internal/pkg/tracing"
 )
 
+const (
+	StorageDriverPostgres = "postgres"
+	StorageDriverMemory   = "memory"
+)
+
+// MinJWTHMACSecretLength matches the HS256 output size; shorter secrets can be brute-forced from a single token.
+const MinJWTHMACSecretLength = 32
+
 type Config struct {
 	pkgconfig.Base
 
-	RabbitMQUrl string
-	DBHost      string
-	DBPort      string
-	DBUser      string
-	DBPassword  string
-	DBName      string
-	DBSSLMode   string
+	RabbitMQUrl          string
+	RabbitMQQueue        string
+	RabbitMQExchange     string
+	RabbitMQExchangeType string
+	RabbitMQRoutingKey   string
+	RabbitMQMandatory    bool
+
+	StorageDriver string
+	DBHost        string
+	DBPort        string
+	DBUser        string
+	DBPassword    string
+	DBName        string
+	DBSSLMode     string
+
+	CategoryMaxDepth int
+	OutboxEnabled    bool
+	OutboxRetention  time.Duration
+	TraceExporter    string
+	EventFormat      string
+
+	EventQueueEnabled      bool
+	EventQueueSize         int
+	EventQueueWorkers      int
+	EventQueueOverflow     string
+	EventQueueSpillDir     string
+	EventQueueFlushTimeout time.Duration
+
+	ReadinessTimeout   time.Duration
+	ShutdownDrainDelay time.Duration
+
+	AuthEnabled   bool
+	JWTHMACSecret string
+	JWTJWKSFile   string
+	JWTIssuer     string
+	JWTAudience   string
+
+	RateLimitEnabled    bool
+	RateLimitReadRPS    int
+	RateLimitReadBurst  int
+	RateLimitWriteRPS   int
+	RateLimitWriteBurst int
+	// Failed authentications allowed per client IP, refilled per minute, before it gets 429.
+	RateLimitAuthFailuresPerMinute int
+	RateLimitAuthFailureBurst      int
 }
 
 func Load() *Config {
 	return &Config{
 		Base: pkgconfig.LoadBase(),
 
-		RabbitMQUrl: pkgconfig.Env("RABBITMQ_URL", ""),
-		DBHost:      pkgconfig.Env("DB_HOST", ""),
-		DBPort:      pkgconfig.Env("DB_PORT", "5432"),
-		DBName:      pkgconfig.Env("DB_NAME", ""),
-		DBUser:      pkgconfig.Env("DB_USER", ""),
-		DBPassword:  pkgconfig.Env("DB_PASSWORD", ""),
-		DBSSLMode:   pkgconfig.Env("DB_SSLMODE", "disable"),
+		RabbitMQUrl:          pkgconfig.Env("RABBITMQ_URL", ""),
+		RabbitMQQueue:        pkgconfig.EnvOptional("RABBITMQ_QUEUE", "category_events"),
+		RabbitMQExchange:     pkgconfig.Env("RABBITMQ_EXCHANGE", ""),
+		RabbitMQExchangeType: pkgconfig.Env("RABBITMQ_EXCHANGE_TYPE", rabbitmq.ExchangeTopic),
+		RabbitMQRoutingKey:   pkgconfig.Env("RABBITMQ_ROUTING_KEY", rabbitmq.DefaultRoutingKey),
+		RabbitMQMandatory:    pkgconfig.EnvBool("RABBITMQ_MANDATORY", false),
+
+		StorageDriver: pkgconfig.Env("STORAGE_DRIVER", StorageDriverPostgres),
+		DBHost:        pkgconfig.Env("DB_HOST", ""),
+		DBPort:        pkgconfig.Env("DB_PORT", "5432"),
+		DBName:        pkgconfig.Env("DB_NAME", ""),
+		DBUser:        pkgconfig.Env("DB_USER", ""),
+		DBPassword:    pkgconfig.Env("DB_PASSWORD", ""),
+		DBSSLMode:     pkgconfig.Env("DB_SSLMODE", "disable"),
+
+		CategoryMaxDepth: pkgconfig.EnvInt("CATEGORY_MAX_DEPTH", 5),
+		OutboxEnabled:    pkgconfig.EnvBool("OUTBOX_ENABLED", true),
+		OutboxRetention:  pkgconfig.EnvDuration("OUTBOX_RETENTION", 24*time.Hour),
+		TraceExporter:    pkgconfig.Env("TRACE_EXPORTER", tracing.ExporterNone),
+		EventFormat:      pkgconfig.Env("EVENT_FORMAT", rabbitmq.FormatLegacy),
+
+		EventQueueEnabled:      pkgconfig.EnvBool("EVENT_QUEUE_ENABLED", true),
+		EventQueueSize:         pkgconfig.EnvInt("EVENT_QUEUE_SIZE", 1000),
+		EventQueueWorkers:      pkgconfig.EnvInt("EVENT_QUEUE_WORKERS", 1),
+		EventQueueOverflow:     pkgconfig.Env("EVENT_QUEUE_OVERFLOW", eventqueue.OverflowBlock),
+		EventQueueSpillDir:     pkgconfig.Env("EVENT_QUEUE_SPILL_DIR", ""),
+		EventQueueFlushTimeout: pkgconfig.EnvDuration("EVENT_QUEUE_FLUSH_TIMEOUT", 10*time.Second),
+
+		ReadinessTimeout:   pkgconfig.EnvDuration("READINESS_TIMEOUT", 2*time.Second),
+		ShutdownDrainDelay: pkgconfig.EnvDuration("SHUTDOWN_DRAIN_DELAY", 0),
+
+		AuthEnabled:   pkgconfig.EnvBool("AUTH_ENABLED", true),
+		JWTHMACSecret: pkgconfig.Env("JWT_HMAC_SECRET", ""),
+		JWTJWKSFile:   pkgconfig.Env("JWT_JWKS_FILE", ""),
+		JWTIssuer:     pkgconfig.Env("JWT_ISSUER", ""),
+		JWTAudience:   pkgconfig.Env("JWT_AUDIENCE", ""),
+
+		RateLimitEnabled:    pkgconfig.EnvBool("RATE_LIMIT_ENABLED", true),
+		RateLimitReadRPS:    pkgconfig.EnvInt("RATE_LIMIT_READ_RPS", 50),
+		RateLimitReadBurst:  pkgconfig.EnvInt("RATE_LIMIT_READ_BURST", 100),
+		RateLimitWriteRPS:   pkgconfig.EnvInt("RATE_LIMIT_WRITE_RPS", 5),
+		RateLimitWriteBurst: pkgconfig.EnvInt("RATE_LIMIT_WRITE_BURST", 10),
+
+		RateLimitAuthFailuresPerMinute: pkgconfig.EnvInt("RATE_LIMIT_AUTH_FAILURES_PER_MINUTE", 10),
+		RateLimitAuthFailureBurst:      pkgconfig.EnvInt("RATE_LIMIT_AUTH_FAILURE_BURST", 20),
 	}
 }
 
@@ -38,19 +126,119 @@ func (c *Config) Validate() error {
 	}
 
 	fields := []struct{ value, name string }{
-		{c.DBHost, "DB_HOST"},
-		{c.DBPort, "DB_PORT"},
-		{c.DBUser, "DB_USER"},
-		{c.DBPassword, "DB_PASSWORD"},
-		{c.DBName, "DB_NAME"},
 		{c.RabbitMQUrl, "RABBITMQ_URL"},
 	}
+
+	switch c.StorageDriver {
+	case StorageDriverPostgres:
+		fields = append(fields, []struct{ value, name string }{
+			{c.DBHost, "DB_HOST"},
+			{c.DBPort, "DB_PORT"},
+			{c.DBUser, "DB_USER"},
+			{c.DBPassword, "DB_PASSWORD"},
+			{c.DBName, "DB_NAME"},
+		}...)
+	case StorageDriverMemory:
+	default:
+		return fmt.Errorf("STORAGE_DRIVER must be %q or %q", StorageDriverPostgres, StorageDriverMemory)
+	}
+
 	for _, f := range fields {
 		if err := pkgconfig.Required(f.value, f.name); err != nil {
 			return err
 		}
 	}
 
+	if c.CategoryMaxDepth < 1 {
+		return fmt.Errorf("CATEGORY_MAX_DEPTH must be at least 1")
+	}
+
+	if c.OutboxRetention <= 0 {
+		return fmt.Errorf("OUTBOX_RETENTION must be positive")
+	}
+
+	// A timeout that is not positive expires at once, so every readiness check would fail.
+	if c.ReadinessTimeout <= 0 {
+		return fmt.Errorf("READINESS_TIMEOUT must be positive")
+	}
+
+	if c.AuthEnabled && c.JWTHMACSecret == "" && c.JWTJWKSFile == "" {
+		return fmt.Errorf("JWT_HMAC_SECRET or JWT_JWKS_FILE is required when AUTH_ENABLED is true")
+	}
+
+	if c.AuthEnabled && c.JWTHMACSecret != "" && len(c.JWTHMACSecret) < MinJWTHMACSecretLength {
+		return fmt.Errorf("JWT_HMAC_SECRET must be at least %d bytes", MinJWTHMACSecretLength)
+	}
+
+	if c.RateLimitEnabled {
+		limits := []struct {
+			value int
+			name  string
+		}{
+			{c.RateLimitReadRPS, "RATE_LIMIT_READ_RPS"},
+			{c.RateLimitReadBurst, "RATE_LIMIT_READ_BURST"},
+			{c.RateLimitWriteRPS, "RATE_LIMIT_WRITE_RPS"},
+			{c.RateLimitWriteBurst, "RATE_LIMIT_WRITE_BURST"},
+			{c.RateLimitAuthFailuresPerMinute, "RATE_LIMIT_AUTH_FAILURES_PER_MINUTE"},
+			{c.RateLimitAuthFailureBurst, "RATE_LIMIT_AUTH_FAILURE_BURST"},
+		}
+		for _, l := range limits {
+			if l.value < 1 {
+				return fmt.Errorf("%s must be at least 1", l.name)
+			}
+		}
+	}
+
+	switch c.TraceExporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	default:
+		return fmt.Errorf("TRACE_EXPORTER must be %q, %q or %q", tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP)
+	}
+
+	if c.RabbitMQExchange == "" {
+		if c.RabbitMQQueue == "" {
+			return fmt.Errorf("RABBITMQ_QUEUE is required unless RABBITMQ_EXCHANGE is set")
+		}
+	} else {
+		switch c.RabbitMQExchangeType {
+		case rabbitmq.ExchangeTopic, rabbitmq.ExchangeDirect, rabbitmq.ExchangeFanout:
+		default:
+			return fmt.Errorf("RABBITMQ_EXCHANGE_TYPE must be %q, %q or %q", rabbitmq.ExchangeTopic, rabbitmq.ExchangeDirect, rabbitmq.ExchangeFanout)
+		}
+		if c.RabbitMQRoutingKey == "" {
+			return fmt.Errorf("RABBITMQ_ROUTING_KEY is required when RABBITMQ_EXCHANGE is set")
+		}
+	}
+
+	switch c.EventFormat {
+	case rabbitmq.FormatLegacy, rabbitmq.FormatStructured, rabbitmq.FormatBinary:
+	default:
+		return fmt.Errorf("EVENT_FORMAT must be %q, %q or %q", rabbitmq.FormatLegacy, rabbitmq.FormatStructured, rabbitmq.FormatBinary)
+	}
+
+	if c.EventQueueEnabled {
+		if c.EventQueueSize < 1 {
+			return fmt.Errorf("EVENT_QUEUE_SIZE must be at least 1")
+		}
+		if c.EventQueueWorkers < 1 {
+			return fmt.Errorf("EVENT_QUEUE_WORKERS must be at least 1")
+		}
+		// A timeout that is not positive expires at once, so shutdown would drop every queued event.
+		if c.EventQueueFlushTimeout <= 0 {
+			return fmt.Errorf("EVENT_QUEUE_FLUSH_TIMEOUT must be positive")
+		}
+
+		switch c.EventQueueOverflow {
+		case eventqueue.OverflowBlock, eventqueue.OverflowDropOldest:
+		case eventqueue.OverflowSpill:
+			if c.EventQueueSpillDir == "" {
+				return fmt.Errorf("EVENT_QUEUE_SPILL_DIR is required when EVENT_QUEUE_OVERFLOW is %q", eventqueue.OverflowSpill)
+			}
+		default:
+			return fmt.Errorf("EVENT_QUEUE_OVERFLOW must be %q, %q or %q", eventqueue.OverflowBlock, eventqueue.OverflowDropOldest, eventqueue.OverflowSpill)
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// APIKey is a credential for machine callers. Only a hash of the secret is stored;
+// Prefix is its first characters, kept so people can tell their keys apart.
+type APIKey struct {
+	ID         string
+	Name       string
+	Prefix     string
+	Hash       string
+	Scopes     []string
+	ExpiresAt  *time.Time
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// Active reports whether the key may still be used at t.
+func (k *APIKey) Active(t time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || t.Before(*k.ExpiresAt))
+}
+
+type IssueAPIKeyParams struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+// IssuedAPIKey carries the plain secret, which is only available right after issuing.
+type IssuedAPIKey struct {
+	Key    *APIKey
+	Secret string
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, k *APIKey) error
+	// GetByHash returns the key with the given secret hash, revoked and expired keys included.
+	GetByHash(ctx context.Context, hash string) (*APIKey, error)
+	// List returns every key, newest first.
+	List(ctx context.Context) ([]*APIKey, error)
+	// Revoke marks a key as revoked; revoking it again keeps the original time.
+	Revoke(ctx context.Context, id string, at time.Time) error
+	// TouchLastUsed moves last_used_at forward to at; it never moves it back.
+	TouchLastUsed(ctx context.Context, id string, at time.Time) error
+}
+
+type APIKeyService interface {
+	Issue(ctx context.Context, p IssueAPIKeyParams) (*IssuedAPIKey, error)
+	List(ctx context.Context) ([]*APIKey, error)
+	Revoke(ctx context.Context, id string) error
+	// Authenticate resolves a secret to an active key, or fails with ErrInvalidAPIKey.
+	Authenticate(ctx context.Context, secret string) (*APIKey, error)
+}
@@ -2,9 +2,34 @@ package domain
 
 import (
 	"errors"
+	"fmt"
 )
 
 var (
-	ErrNotFound  = errors.New("data not found")
-	ErrDuplicate = errors.New("data already exists")
+	ErrNotFound           = errors.New("data not found")
+	ErrDuplicate          = errors.New("data already exists")
+	ErrInvalidParent      = errors.New("invalid parent category")
+	ErrHasChildren        = errors.New("category has child categories")
+	ErrMaxDepthExceeded   = errors.New("maximum category depth exceeded")
+	ErrPreconditionFailed = errors.New("category version does not match")
+	ErrNotDeleted         = errors.New("category is not deleted")
+	ErrInvalidAPIKey      = errors.New("invalid or expired api key")
+	ErrPatchTestFailed    = errors.New("patch test operation failed")
+	ErrBatchAborted       = errors.New("rolled back because another operation in the batch failed")
+	ErrTxUnsupported      = errors.New("transactions are not supported by the storage driver")
 )
+
+// DuplicateError names the live category that already holds a value that has to be unique.
+// It matches ErrDuplicate.
+type DuplicateError struct {
+	Field string
+	ID    string
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("%s is already used by category %s", e.Field, e.ID)
+}
+
+func (e *DuplicateError) Is(target error) bool {
+	return target == ErrDuplicate
+}
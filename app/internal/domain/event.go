@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	EventCategoryCreated  = "category_created"
+	EventCategoryUpdated  = "category_updated"
+	EventCategoryDeleted  = "category_deleted"
+	EventCategoryMoved    = "category_moved"
+	EventCategoryRestored = "category_restored"
+)
+
+// CategoryEvent is a state change that has to reach CategoryEventPublisher,
+// either right away or later through the outbox, which stores it by its json names.
+// Category is the state after the change, and for a deletion the last state before it.
+// Before is the state an update started from. ChangedFields lists the fields an update changed.
+type CategoryEvent struct {
+	// ID and OccurredAt are set when the change commits and stay the same however often it is published.
+	ID            string    `json:"id"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	Type          string    `json:"type"`
+	Category      *Category `json:"category"`
+	Before        *Category `json:"before,omitempty"`
+	OldParentID   *string   `json:"old_parent_id,omitempty"`
+	ChangedFields []string  `json:"changed_fields,omitempty"`
+}
+
+// Publish hands e to the method of p for its type. ID and OccurredAt are not arguments of those
+// methods, so callers pass them to the publisher in ctx.
+func (e CategoryEvent) Publish(ctx context.Context, p CategoryEventPublisher) error {
+	switch e.Type {
+	case EventCategoryCreated:
+		return p.PublishCategoryCreated(ctx, e.Category)
+	case EventCategoryUpdated:
+		return p.PublishCategoryUpdated(ctx, e.Before, e.Category, e.ChangedFields)
+	case EventCategoryDeleted:
+		return p.PublishCategoryDeleted(ctx, e.Category)
+	case EventCategoryMoved:
+		return p.PublishCategoryMoved(ctx, e.Category, e.OldParentID)
+	case EventCategoryRestored:
+		return p.PublishCategoryRestored(ctx, e.Category)
+	default:
+		return fmt.Errorf("unknown event type %q", e.Type)
+	}
+}
@@ -4,9 +4,17 @@ import (
 	"context"
 )
 
+// PaginationParams selects a page either by number or, when Cursor is set, by keyset.
+// An empty Cursor starts keyset pagination from the first row. Page numbers always
+// come with a total count; keyset pages only count when WithTotal is set.
+// An empty Sort means DefaultCategorySort.
 type PaginationParams struct {
-	Page  int
-	Limit int
+	Page      int
+	Limit     int
+	Cursor    *string
+	WithTotal bool
+	Sort      CategorySort
+	Filter    CategoryFilter
 }
 
 type PaginatedResult[T any] struct {
@@ -15,27 +23,169 @@ type PaginatedResult[T any] struct {
 	Limit      int
 	Total      int
 	TotalPages int
+	NextCursor string
+}
+
+// An empty Slug has one derived from Name.
+type CreateCategoryParams struct {
+	Name     string
+	Slug     string
+	ParentID *string
+}
+
+// Version is the version the caller expects the category to be at; zero skips the check.
+// An empty Slug keeps the current one, unless the name changes and a new one is derived from it.
+type UpdateCategoryParams struct {
+	Name     string
+	Slug     string
+	ParentID *string
+	Version  int64
+}
+
+// PatchCategoryParams holds JSON Patch operations, applied in order to the stored category.
+// A JSON Merge Patch is expressed as replace and remove operations.
+type PatchCategoryParams struct {
+	Ops     []PatchOp
+	Version int64
+}
+
+// PatchOp addresses fields by name rather than JSON Pointer; Value nil stands for JSON null.
+type PatchOp struct {
+	Op    string
+	Path  string
+	From  string
+	Value *string
+}
+
+// BatchParams runs Ops in order. Atomic makes them all-or-nothing in one transaction;
+// otherwise every operation is committed on its own and failures do not stop the rest.
+type BatchParams struct {
+	Ops    []BatchOp
+	Atomic bool
+}
+
+// BatchOp is one create, update or delete. Create reads Name and ParentID, update all fields,
+// delete ID and Version. A zero Version skips the version check as in the single-item calls.
+type BatchOp struct {
+	Op       string
+	ID       string
+	Name     string
+	Slug     string
+	ParentID *string
+	Version  int64
+}
+
+// BatchResult is the outcome of the operation at the same index. Category is nil for deletes.
+type BatchResult struct {
+	Category *Category
+	Err      error
+}
+
+// ImportParams brings the catalog in line with Rows. Mode is one of the Import* modes;
+// with DryRun the changes are only worked out and reported.
+type ImportParams struct {
+	Rows   []ImportRow
+	Mode   string
+	DryRun bool
+}
+
+// ImportRow is one category of an import. Row is its position in the uploaded file, used in
+// error messages. An empty ID creates a category with a new one; an empty Slug is handled
+// like in UpdateCategoryParams.
+type ImportRow struct {
+	Row      int
+	ID       string
+	Name     string
+	Slug     string
+	ParentID *string
+}
+
+// ImportChange is a change an import made or, on a dry run, would make. Row is zero for
+// categories that are deleted because the file leaves them out.
+type ImportChange struct {
+	Row           int
+	Action        string
+	Category      *Category
+	ChangedFields []string
+}
+
+type ImportResult struct {
+	Created   int
+	Updated   int
+	Deleted   int
+	Unchanged int
+	Changes   []ImportChange
+}
+
+// Purge removes the category for good instead of soft-deleting it.
+type DeleteCategoryParams struct {
+	Version int64
+	Purge   bool
 }
 
 type CategoryRepository interface {
 	Create(ctx context.Context, c *Category) error
+	// Update only succeeds when c.Version matches the stored version, and bumps c.Version on success.
 	Update(ctx context.Context, c *Category) error
-	Delete(ctx context.Context, id string) error
+	// Delete soft-deletes a category and returns its last live state; it skips the version check when version is zero.
+	// Apart from Restore and Purge, reads and writes treat soft-deleted categories as missing.
+	Delete(ctx context.Context, id string, version int64) (*Category, error)
+	// Purge removes a category, soft-deleted or not, and returns its last state.
+	Purge(ctx context.Context, id string, version int64) (*Category, error)
+	Restore(ctx context.Context, id string) (*Category, error)
 	GetByID(ctx context.Context, id string) (*Category, error)
+	// GetBySlug finds the live category whose slug is slug or, failing that, the one that used to have it.
+	GetBySlug(ctx context.Context, slug string) (*Category, error)
+	// ListSlugs returns the slugs equal to base or starting with base followed by a hyphen, current
+	// ones of every category, soft-deleted or not, and former ones, mapped to their category.
+	ListSlugs(ctx context.Context, base string) (map[string]string, error)
+	// AddFormerSlug keeps slug resolving to the category id after it got a new one.
+	AddFormerSlug(ctx context.Context, id, slug string) error
+	// LockSlug holds off other writes locking root until the transaction in ctx ends, so a slug
+	// found free after it stays free until the write commits.
+	LockSlug(ctx context.Context, root string) error
 	List(ctx context.Context, p PaginationParams) ([]*Category, error)
-	Count(ctx context.Context) (int, error)
+	// ListAfter returns up to p.Limit categories following after, or from the start when after is nil.
+	// Page and Cursor in p are ignored.
+	ListAfter(ctx context.Context, p PaginationParams, after *CategoryCursor) ([]*Category, error)
+	Count(ctx context.Context, f CategoryFilter) (int, error)
+	ListChildren(ctx context.Context, parentID string) ([]*Category, error)
+	ListAncestors(ctx context.Context, id string) ([]*Category, error)
+	ListAll(ctx context.Context) ([]*Category, error)
+	SubtreeHeight(ctx context.Context, id string) (int, error)
+	Move(ctx context.Context, id string, parentID *string, maxDepth int) (*CategoryMove, error)
+	// LockHierarchy holds off other hierarchy changes, Move included, until the transaction in ctx ends,
+	// so a parent checked after it stays valid until the write commits.
+	LockHierarchy(ctx context.Context) error
 }
 
 type CategoryEventPublisher interface {
 	PublishCategoryCreated(ctx context.Context, c *Category) error
-	PublishCategoryUpdated(ctx context.Context, c *Category) error
-	PublishCategoryDeleted(ctx context.Context, id string) error
+	// PublishCategoryUpdated announces that before became after. before is nil when it is not known.
+	PublishCategoryUpdated(ctx context.Context, before, after *Category, changedFields []string) error
+	// PublishCategoryDeleted announces the deletion of c, as it was last seen.
+	PublishCategoryDeleted(ctx context.Context, c *Category) error
+	PublishCategoryMoved(ctx context.Context, c *Category, oldParentID *string) error
+	PublishCategoryRestored(ctx context.Context, c *Category) error
 }
 
 type CategoryService interface {
-	Create(ctx context.Context, name string) (*Category, error)
-	Update(ctx context.Context, id, name string) (*Category, error)
-	Delete(ctx context.Context, id string) error
+	Create(ctx context.Context, p CreateCategoryParams) (*Category, error)
+	Update(ctx context.Context, id string, p UpdateCategoryParams) (*Category, error)
+	Patch(ctx context.Context, id string, p PatchCategoryParams) (*Category, error)
+	Batch(ctx context.Context, p BatchParams) ([]BatchResult, error)
+	Import(ctx context.Context, p ImportParams) (*ImportResult, error)
+	Delete(ctx context.Context, id string, p DeleteCategoryParams) error
+	Restore(ctx context.Context, id string) (*Category, error)
+	Move(ctx context.Context, id string, parentID *string) (*Category, error)
 	GetByID(ctx context.Context, id string) (*Category, error)
+	// GetBySlug also resolves former slugs; the caller can tell by comparing slug to the category's.
+	GetBySlug(ctx context.Context, slug string) (*Category, error)
 	List(ctx context.Context, p PaginationParams) (*PaginatedResult[*Category], error)
+	Children(ctx context.Context, id string) ([]*Category, error)
+	Ancestors(ctx context.Context, id string) ([]*Category, error)
+	Tree(ctx context.Context) ([]*CategoryNode, error)
+	// Export calls fn for every live category, oldest first, without holding them all in memory.
+	Export(ctx context.Context, fn func(*Category) error) error
+	History(ctx context.Context, id string, p PaginationParams) (*PaginatedResult[*CategoryRevision], error)
 }
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// CategorySort names a listing order; a leading "-" means descending. Ties are broken by id in the same direction.
+type CategorySort string
+
+const (
+	SortNameAsc       CategorySort = "name"
+	SortNameDesc      CategorySort = "-name"
+	SortCreatedAtAsc  CategorySort = "created_at"
+	SortCreatedAtDesc CategorySort = "-created_at"
+	SortUpdatedAtAsc  CategorySort = "updated_at"
+	SortUpdatedAtDesc CategorySort = "-updated_at"
+
+	DefaultCategorySort = SortCreatedAtDesc
+)
+
+var CategorySorts = []CategorySort{
+	SortNameAsc, SortNameDesc,
+	SortCreatedAtAsc, SortCreatedAtDesc,
+	SortUpdatedAtAsc, SortUpdatedAtDesc,
+}
+
+func (s CategorySort) Valid() bool {
+	for _, known := range CategorySorts {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+func (s CategorySort) Desc() bool {
+	return len(s) > 0 && s[0] == '-'
+}
+
+// Field returns the sort key without its direction.
+func (s CategorySort) Field() string {
+	if s.Desc() {
+		return string(s[1:])
+	}
+	return string(s)
+}
+
+// CategoryFilter narrows a listing. Query matches a case-insensitive substring of the name;
+// the time filters are exclusive lower bounds. Zero values disable a filter.
+// Soft-deleted categories are left out unless IncludeDeleted is set.
+type CategoryFilter struct {
+	Query          string
+	CreatedAfter   time.Time
+	UpdatedAfter   time.Time
+	IncludeDeleted bool
+}
+
+// CategoryCursor is a position in a category listing: the sort key of the last row seen and its id.
+type CategoryCursor struct {
+	Sort CategorySort `json:"s"`
+	Name string       `json:"n,omitempty"`
+	Time time.Time    `json:"t"`
+	ID   string       `json:"i"`
+}
+
+func NewCategoryCursor(c *Category, sort CategorySort) CategoryCursor {
+	cursor := CategoryCursor{Sort: sort, ID: c.ID}
+
+	switch sort.Field() {
+	case "name":
+		cursor.Name = c.Name
+	case "updated_at":
+		cursor.Time = c.UpdatedAt
+	default:
+		cursor.Time = c.CreatedAt
+	}
+
+	return cursor
+}
+
+// Encode returns the opaque form handed out to API clients.
+func (c CategoryCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeCategoryCursor(s string) (CategoryCursor, error) {
+	var c CategoryCursor
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, err
+	}
+
+	if c.ID == "" || !c.Sort.Valid() {
+		return c, errors.New("incomplete cursor")
+	}
+
+	return c, nil
+}
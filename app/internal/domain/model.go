@@ -4,9 +4,64 @@ import (
 	"time"
 )
 
+// Names of the category fields a client can change, as they appear in requests and events.
+const (
+	FieldName     = "name"
+	FieldSlug     = "slug"
+	FieldParentID = "parent_id"
+)
+
+const (
+	PatchAdd     = "add"
+	PatchRemove  = "remove"
+	PatchReplace = "replace"
+	PatchMove    = "move"
+	PatchCopy    = "copy"
+	PatchTest    = "test"
+)
+
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+const (
+	// ImportCreateOnly only adds categories and fails on rows whose id already exists.
+	ImportCreateOnly = "create-only"
+	// ImportUpsert adds new categories and updates existing ones, leaving the rest alone.
+	ImportUpsert = "upsert"
+	// ImportReplace works like ImportUpsert and deletes every category the file leaves out.
+	ImportReplace = "replace"
+)
+
+const (
+	ImportCreated = "created"
+	ImportUpdated = "updated"
+	ImportDeleted = "deleted"
+)
+
+// Slug is unique among live categories. Slugs a category had before keep resolving to it.
+// The json names are how snapshots are stored in the outbox and in revisions.
 type Category struct {
-	ID        string
-	Name      string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Slug      string     `json:"slug"`
+	ParentID  *string    `json:"parent_id"`
+	Version   int64      `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+type CategoryNode struct {
+	Category *Category
+	Children []*CategoryNode
+}
+
+// CategoryMove holds a moved category and its state right before the move.
+type CategoryMove struct {
+	Category    *Category
+	Before      *Category
+	OldParentID *string
 }
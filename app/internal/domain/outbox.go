@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type OutboxMessage struct {
+	ID        string        `json:"id"`
+	Event     CategoryEvent `json:"event"`
+	RequestID string        `json:"request_id"`
+	// TraceParent is the W3C trace context of the request that produced the event.
+	TraceParent string    `json:"trace_parent"`
+	Attempts    int       `json:"attempts"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// WithinSnapshot runs fn in a read-only transaction whose reads all see the data as of its start.
+	WithinSnapshot(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// OutboxRepository stores events until the relay has published them.
+type OutboxRepository interface {
+	Add(ctx context.Context, events ...CategoryEvent) error
+	// Claim leases up to limit due messages, oldest first, so no other relay picks them up for lease.
+	// A message is held back while an earlier one for the same category is leased or waiting for its retry.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*OutboxMessage, error)
+	MarkSent(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id string, cause error, retryAt time.Time) error
+	// Release makes claimed messages that were not published due again right away.
+	Release(ctx context.Context, ids ...string) error
+	// DeleteSent deletes up to limit messages sent before before and returns how many it deleted.
+	DeleteSent(ctx context.Context, before time.Time, limit int) (int, error)
+}
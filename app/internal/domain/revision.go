@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	RevisionCreated  = "created"
+	RevisionUpdated  = "updated"
+	RevisionMoved    = "moved"
+	RevisionDeleted  = "deleted"
+	RevisionRestored = "restored"
+	RevisionPurged   = "purged"
+)
+
+// CategoryRevision records one change to a category. Before is nil for creations and restores,
+// After is nil for deletions and purges.
+type CategoryRevision struct {
+	ID         string
+	CategoryID string
+	Action     string
+	Before     *Category
+	After      *Category
+	RequestID  string
+	Actor      string
+	CreatedAt  time.Time
+}
+
+// RevisionRepository stores the change history of categories. Revisions outlive the categories they describe.
+type RevisionRepository interface {
+	Add(ctx context.Context, r *CategoryRevision) error
+	// ListByCategory returns revisions newest first.
+	ListByCategory(ctx context.Context, categoryID string, p PaginationParams) ([]*CategoryRevision, error)
+	CountByCategory(ctx context.Context, categoryID string) (int, error)
+}
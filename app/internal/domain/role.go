@@ -0,0 +1,9 @@
+package domain
+
+// Roles a caller needs to change categories. Reads are public. API key scopes use the same names.
+const (
+	RoleCategoryWrite = "category:write"
+	RoleCategoryAdmin = "category:admin"
+)
+
+var Roles = []string{RoleCategoryWrite, RoleCategoryAdmin}
@@ -0,0 +1,33 @@
+package eventqueue
+
+import "github.com/prometheus/client_golang/prometheus"
+
+type queueMetrics struct {
+	dropped prometheus.Counter
+	spilled prometheus.Counter
+}
+
+func newQueueMetrics() *queueMetrics {
+	return &queueMetrics{
+		dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "category_event_queue_dropped_total",
+			Help: "Number of queued events dropped to make room for newer ones.",
+		}),
+		spilled: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "category_event_queue_spilled_total",
+			Help: "Number of events written to disk because the queue was full.",
+		}),
+	}
+}
+
+// WithMetrics registers the queue metrics, queue depth included, with reg.
+func WithMetrics(reg prometheus.Registerer) Option {
+	return func(q *Queue) {
+		depth := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "category_event_queue_depth",
+			Help: "Number of events waiting to be published, spilled ones included.",
+		}, func() float64 { return float64(q.Depth()) })
+
+		reg.MustRegister(q.metrics.dropped, q.metrics.spilled, depth)
+	}
+}
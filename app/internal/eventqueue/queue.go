@@ -0,0 +1,420 @@
+// Package eventqueue publishes category events in the background, so the request that caused
+// them does not wait for the broker.
+package eventqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/pkg/eventmeta"
+	"github.com/alfattd/category-service/internal/pkg/requestid"
+	"github.com/alfattd/category-service/internal/pkg/tracing"
+	"github.com/google/uuid"
+)
+
+// What Publish does when the queue is full.
+const (
+	OverflowBlock      = "block"
+	OverflowDropOldest = "drop-oldest"
+	OverflowSpill      = "spill"
+)
+
+const (
+	defaultSize    = 1000
+	defaultWorkers = 1
+)
+
+var ErrClosed = errors.New("event queue is closed")
+
+// Queue is a CategoryEventPublisher that queues events in memory and has workers hand them to next.
+// An event keeps the identity, request id and trace it was published with.
+// With more than one worker events may reach the broker out of order.
+type Queue struct {
+	next     domain.CategoryEventPublisher
+	log      *slog.Logger
+	size     int
+	workers  int
+	overflow string
+	spillDir string
+	spill    *spill
+	queue    chan *domain.OutboxMessage
+	metrics  *queueMetrics
+
+	// mu is held for reading while an event is queued, so Close knows no one is still adding to the queue.
+	mu        sync.RWMutex
+	closed    bool
+	closing   chan struct{} // releases publishers blocked on a full queue
+	stop      chan struct{} // tells the workers to drain what is left and return
+	refilled  chan struct{} // closed once nothing more comes back from disk
+	closeOnce sync.Once
+	closeErr  error
+
+	// ctx is what events are published with; Close cancels it once the flush deadline passes.
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ domain.CategoryEventPublisher = (*Queue)(nil)
+
+type Option func(*Queue)
+
+// WithSize sets how many events fit in memory.
+func WithSize(n int) Option {
+	return func(q *Queue) {
+		if n > 0 {
+			q.size = n
+		}
+	}
+}
+
+// WithWorkers sets how many events are published at the same time.
+func WithWorkers(n int) Option {
+	return func(q *Queue) {
+		if n > 0 {
+			q.workers = n
+		}
+	}
+}
+
+// WithOverflow sets the overflow policy. spillDir is where OverflowSpill writes events and is
+// ignored by the other policies.
+func WithOverflow(policy, spillDir string) Option {
+	return func(q *Queue) {
+		q.overflow = policy
+		q.spillDir = spillDir
+	}
+}
+
+// New starts the workers. With OverflowSpill, events left on disk by an earlier run are published first.
+func New(next domain.CategoryEventPublisher, log *slog.Logger, opts ...Option) (*Queue, error) {
+	q := &Queue{
+		next:     next,
+		log:      log,
+		size:     defaultSize,
+		workers:  defaultWorkers,
+		overflow: OverflowBlock,
+		metrics:  newQueueMetrics(),
+		closing:  make(chan struct{}),
+		stop:     make(chan struct{}),
+		refilled: make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	switch q.overflow {
+	case OverflowBlock, OverflowDropOldest:
+	case OverflowSpill:
+		s, err := openSpill(q.spillDir)
+		if err != nil {
+			return nil, err
+		}
+		q.spill = s
+	default:
+		return nil, fmt.Errorf("unknown overflow policy %q", q.overflow)
+	}
+
+	q.queue = make(chan *domain.OutboxMessage, q.size)
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+
+	for range q.workers {
+		q.wg.Add(1)
+		go q.work()
+	}
+
+	if q.spill == nil {
+		close(q.refilled)
+	} else {
+		if n := q.spill.len(); n > 0 {
+			log.Info("publishing events spilled to disk by an earlier run", "count", n, "dir", q.spillDir)
+		}
+
+		go q.refill()
+	}
+
+	return q, nil
+}
+
+func (q *Queue) PublishCategoryCreated(ctx context.Context, c *domain.Category) error {
+	return q.enqueue(ctx, domain.CategoryEvent{Type: domain.EventCategoryCreated, Category: c})
+}
+
+func (q *Queue) PublishCategoryUpdated(ctx context.Context, before, after *domain.Category, changedFields []string) error {
+	return q.enqueue(ctx, domain.CategoryEvent{
+		Type:          domain.EventCategoryUpdated,
+		Category:      after,
+		Before:        before,
+		ChangedFields: changedFields,
+	})
+}
+
+func (q *Queue) PublishCategoryDeleted(ctx context.Context, c *domain.Category) error {
+	return q.enqueue(ctx, domain.CategoryEvent{Type: domain.EventCategoryDeleted, Category: c})
+}
+
+func (q *Queue) PublishCategoryMoved(ctx context.Context, c *domain.Category, oldParentID *string) error {
+	return q.enqueue(ctx, domain.CategoryEvent{Type: domain.EventCategoryMoved, Category: c, OldParentID: oldParentID})
+}
+
+func (q *Queue) PublishCategoryRestored(ctx context.Context, c *domain.Category) error {
+	return q.enqueue(ctx, domain.CategoryEvent{Type: domain.EventCategoryRestored, Category: c})
+}
+
+func (q *Queue) enqueue(ctx context.Context, e domain.CategoryEvent) error {
+	// The request is gone by the time the event is published, so what the publisher reads from ctx travels with it.
+	meta, ok := eventmeta.FromContext(ctx)
+	if !ok {
+		meta = eventmeta.Meta{ID: uuid.NewString(), Time: time.Now()}
+	}
+	e.ID, e.OccurredAt = meta.ID, meta.Time
+
+	m := &domain.OutboxMessage{
+		ID:          e.ID,
+		Event:       e,
+		RequestID:   requestid.FromContext(ctx),
+		TraceParent: tracing.TraceParent(ctx),
+		CreatedAt:   time.Now(),
+	}
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return ErrClosed
+	}
+
+	switch q.overflow {
+	case OverflowDropOldest:
+		q.pushDropOldest(m)
+		return nil
+	case OverflowSpill:
+		spilled, err := q.spill.push(m, q.queue)
+		if err != nil {
+			return fmt.Errorf("failed to spill event: %w", err)
+		}
+		if spilled {
+			q.metrics.spilled.Inc()
+		}
+		return nil
+	default:
+		select {
+		case q.queue <- m:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-q.closing:
+			return ErrClosed
+		}
+	}
+}
+
+func (q *Queue) pushDropOldest(m *domain.OutboxMessage) {
+	for {
+		select {
+		case q.queue <- m:
+			return
+		default:
+		}
+
+		select {
+		case old := <-q.queue:
+			q.metrics.dropped.Inc()
+			q.log.Warn("event queue is full, dropped the oldest event",
+				"type", old.Event.Type,
+				"event_id", old.Event.ID,
+				"request_id", old.RequestID,
+			)
+		default:
+		}
+	}
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	for q.ctx.Err() == nil {
+		select {
+		case m := <-q.queue:
+			q.deliver(m)
+		case <-q.stop:
+			q.drain()
+			return
+		}
+	}
+}
+
+// drain publishes what is queued and what the refill still brings back from disk.
+func (q *Queue) drain() {
+	for q.ctx.Err() == nil {
+		select {
+		case m := <-q.queue:
+			q.deliver(m)
+		case <-q.refilled:
+			select {
+			case m := <-q.queue:
+				q.deliver(m)
+			default:
+				return
+			}
+		case <-q.ctx.Done():
+			return
+		}
+	}
+}
+
+// refill moves spilled events back into the queue, oldest first, whenever it has room.
+// Once the queue is closed it empties the disk before returning, unless the flush deadline passes first.
+func (q *Queue) refill() {
+	defer close(q.refilled)
+
+	for {
+		names, err := q.spill.pending()
+		if err != nil {
+			q.log.Error("failed to list spilled events", "error", err, "dir", q.spillDir)
+		}
+
+		for _, name := range names {
+			m, err := q.spill.read(name)
+			if err != nil {
+				q.log.Error("failed to read spilled event, setting it aside", "error", err, "file", name)
+				q.spill.reject(name)
+				continue
+			}
+
+			select {
+			case q.queue <- m:
+			case <-q.ctx.Done():
+				return
+			}
+
+			if err := q.spill.remove(name); err != nil {
+				q.log.Error("failed to remove spilled event", "error", err, "file", name)
+			}
+		}
+
+		select {
+		case <-q.spill.wake:
+		case <-q.stop:
+			if err != nil || q.spill.len() == 0 {
+				return
+			}
+		case <-q.ctx.Done():
+			return
+		}
+	}
+}
+
+func (q *Queue) deliver(m *domain.OutboxMessage) {
+	ctx := tracing.WithTraceParent(requestid.WithContext(q.ctx, m.RequestID), m.TraceParent)
+	ctx = eventmeta.WithContext(ctx, eventmeta.Meta{ID: m.Event.ID, Time: m.Event.OccurredAt})
+
+	err := m.Event.Publish(ctx, q.next)
+	if err == nil {
+		return
+	}
+
+	// Cut short by Close, the event can still be published by the next run.
+	if q.ctx.Err() != nil && q.spill != nil && q.spill.add(m) == nil {
+		return
+	}
+
+	q.log.Error("failed to publish "+m.Event.Type+" event",
+		"error", err,
+		"event_id", m.Event.ID,
+		"request_id", m.RequestID,
+	)
+}
+
+// Close stops accepting events and publishes what is queued until ctx is done. Whatever is
+// left then is spilled to disk with OverflowSpill and lost otherwise, which the error reports.
+func (q *Queue) Close(ctx context.Context) error {
+	q.closeOnce.Do(func() {
+		q.closeErr = q.close(ctx)
+	})
+	return q.closeErr
+}
+
+func (q *Queue) close(ctx context.Context) error {
+	close(q.closing)
+
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+
+	close(q.stop)
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		// Publishes in progress give up with the context, retries included.
+		q.cancel()
+		<-done
+	}
+	q.cancel()
+	<-q.refilled
+
+	// The workers and the refill have returned, so nothing else touches the queue.
+	var lost int
+	for len(q.queue) > 0 {
+		m := <-q.queue
+		if q.spill == nil || q.spill.add(m) != nil {
+			lost++
+		}
+	}
+
+	if q.spill != nil {
+		if n := q.spill.len(); n > 0 {
+			q.log.Warn("events are left spilled to disk for the next run", "count", n, "dir", q.spillDir)
+		}
+	}
+
+	if lost > 0 {
+		return fmt.Errorf("%d queued events were not published", lost)
+	}
+	return nil
+}
+
+// Depth is the number of events waiting to be published, spilled ones included.
+func (q *Queue) Depth() int {
+	n := len(q.queue)
+	if q.spill != nil {
+		n += q.spill.len()
+	}
+	return n
+}
+
+type Stats struct {
+	Depth    int    `json:"depth"`
+	Capacity int    `json:"capacity"`
+	Spilled  int    `json:"spilled"`
+	Overflow string `json:"overflow"`
+}
+
+func (q *Queue) Stats() Stats {
+	s := Stats{Depth: q.Depth(), Capacity: q.size, Overflow: q.overflow}
+	if q.spill != nil {
+		s.Spilled = q.spill.len()
+	}
+	return s
+}
+
+// Check fails while the overflow policy is in effect, that is while the queue is full or events are spilled.
+func (q *Queue) Check(ctx context.Context) error {
+	if len(q.queue) >= q.size || (q.spill != nil && q.spill.len() > 0) {
+		return fmt.Errorf("event queue is full, %d events waiting", q.Depth())
+	}
+	return nil
+}
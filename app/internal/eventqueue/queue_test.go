@@ -0,0 +1,219 @@
+package eventqueue_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/eventqueue"
+	"github.com/alfattd/category-service/internal/mocks"
+	"github.com/alfattd/category-service/internal/pkg/eventmeta"
+	"github.com/alfattd/category-service/internal/pkg/requestid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+// recorder lets a test hold the worker on the first event and see the order events arrive in.
+type recorder struct {
+	mu      sync.Mutex
+	ids     []string
+	reqs    []string
+	started chan struct{}
+	release chan struct{}
+}
+
+func newRecorder(pub *mocks.MockCategoryEventPublisher) *recorder {
+	r := &recorder{started: make(chan struct{}, 100), release: make(chan struct{})}
+
+	pub.On("PublishCategoryCreated", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		r.started <- struct{}{}
+		<-r.release
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.ids = append(r.ids, args.Get(1).(*domain.Category).ID)
+		r.reqs = append(r.reqs, requestid.FromContext(args.Get(0).(context.Context)))
+	}).Return(nil)
+
+	return r
+}
+
+func (r *recorder) published() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.ids...)
+}
+
+func TestQueue_PublishesInBackgroundWithRequestContext(t *testing.T) {
+	occurredAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	cat := &domain.Category{ID: "abc-123", Name: "Electronics"}
+
+	pub := new(mocks.MockCategoryEventPublisher)
+	published := make(chan context.Context, 1)
+	pub.On("PublishCategoryCreated", mock.Anything, cat).Run(func(args mock.Arguments) {
+		published <- args.Get(0).(context.Context)
+	}).Return(nil)
+
+	q, err := eventqueue.New(pub, testLogger)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(requestid.WithContext(context.Background(), "req-1"))
+	ctx = eventmeta.WithContext(ctx, eventmeta.Meta{ID: "evt-1", Time: occurredAt})
+
+	require.NoError(t, q.PublishCategoryCreated(ctx, cat))
+	// The request is over before the event is published.
+	cancel()
+
+	pubCtx := <-published
+	meta, ok := eventmeta.FromContext(pubCtx)
+	require.True(t, ok)
+	assert.Equal(t, eventmeta.Meta{ID: "evt-1", Time: occurredAt}, meta)
+	assert.Equal(t, "req-1", requestid.FromContext(pubCtx))
+	assert.NoError(t, pubCtx.Err())
+
+	require.NoError(t, q.Close(context.Background()))
+}
+
+func TestQueue_BlockWaitsForRoom(t *testing.T) {
+	pub := new(mocks.MockCategoryEventPublisher)
+	rec := newRecorder(pub)
+
+	q, err := eventqueue.New(pub, testLogger, eventqueue.WithSize(1))
+	require.NoError(t, err)
+
+	require.NoError(t, q.PublishCategoryCreated(context.Background(), &domain.Category{ID: "1"}))
+	<-rec.started
+	require.NoError(t, q.PublishCategoryCreated(context.Background(), &domain.Category{ID: "2"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = q.PublishCategoryCreated(ctx, &domain.Category{ID: "3"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(rec.release)
+	require.NoError(t, q.Close(context.Background()))
+	assert.Equal(t, []string{"1", "2"}, rec.published())
+}
+
+func TestQueue_DropOldestMakesRoomForNewEvents(t *testing.T) {
+	pub := new(mocks.MockCategoryEventPublisher)
+	rec := newRecorder(pub)
+
+	q, err := eventqueue.New(pub, testLogger,
+		eventqueue.WithSize(2),
+		eventqueue.WithOverflow(eventqueue.OverflowDropOldest, ""),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, q.PublishCategoryCreated(context.Background(), &domain.Category{ID: "1"}))
+	<-rec.started
+	for _, id := range []string{"2", "3", "4"} {
+		require.NoError(t, q.PublishCategoryCreated(context.Background(), &domain.Category{ID: id}))
+	}
+	assert.Equal(t, 2, q.Depth())
+
+	close(rec.release)
+	require.NoError(t, q.Close(context.Background()))
+	assert.Equal(t, []string{"1", "3", "4"}, rec.published())
+}
+
+func TestQueue_SpillKeepsOrderAcrossDisk(t *testing.T) {
+	dir := t.TempDir()
+
+	pub := new(mocks.MockCategoryEventPublisher)
+	rec := newRecorder(pub)
+
+	q, err := eventqueue.New(pub, testLogger,
+		eventqueue.WithSize(1),
+		eventqueue.WithOverflow(eventqueue.OverflowSpill, dir),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, q.PublishCategoryCreated(context.Background(), &domain.Category{ID: "1"}))
+	<-rec.started
+	for _, id := range []string{"2", "3", "4"} {
+		require.NoError(t, q.PublishCategoryCreated(context.Background(), &domain.Category{ID: id}))
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, 3, q.Depth())
+	assert.Error(t, q.Check(context.Background()))
+
+	close(rec.release)
+	require.NoError(t, q.Close(context.Background()))
+	assert.Equal(t, []string{"1", "2", "3", "4"}, rec.published())
+	assert.Zero(t, q.Depth())
+}
+
+func TestQueue_SpilledEventsArePublishedByTheNextRun(t *testing.T) {
+	dir := t.TempDir()
+
+	stuck := new(mocks.MockCategoryEventPublisher)
+	stuck.On("PublishCategoryCreated", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return(context.Canceled)
+
+	q, err := eventqueue.New(stuck, testLogger,
+		eventqueue.WithSize(1),
+		eventqueue.WithOverflow(eventqueue.OverflowSpill, dir),
+	)
+	require.NoError(t, err)
+
+	ctx := requestid.WithContext(context.Background(), "req-1")
+	for _, id := range []string{"1", "2", "3"} {
+		require.NoError(t, q.PublishCategoryCreated(ctx, &domain.Category{ID: id}))
+	}
+
+	closeCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.NoError(t, q.Close(closeCtx))
+
+	assert.ErrorIs(t, q.PublishCategoryCreated(ctx, &domain.Category{ID: "4"}), eventqueue.ErrClosed)
+
+	pub := new(mocks.MockCategoryEventPublisher)
+	rec := newRecorder(pub)
+	close(rec.release)
+
+	next, err := eventqueue.New(pub, testLogger, eventqueue.WithOverflow(eventqueue.OverflowSpill, dir))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool { return len(rec.published()) == 3 }, time.Second, 5*time.Millisecond)
+	require.NoError(t, next.Close(context.Background()))
+
+	assert.ElementsMatch(t, []string{"1", "2", "3"}, rec.published())
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	assert.Equal(t, []string{"req-1", "req-1", "req-1"}, rec.reqs)
+	assert.Zero(t, next.Depth())
+}
+
+func TestQueue_CloseReportsEventsLeftAtTheDeadline(t *testing.T) {
+	pub := new(mocks.MockCategoryEventPublisher)
+	pub.On("PublishCategoryCreated", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return(context.Canceled)
+
+	q, err := eventqueue.New(pub, testLogger)
+	require.NoError(t, err)
+
+	for _, id := range []string{"1", "2", "3"} {
+		require.NoError(t, q.PublishCategoryCreated(context.Background(), &domain.Category{ID: id}))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = q.Close(ctx)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 queued events were not published")
+}
@@ -0,0 +1,166 @@
+package eventqueue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alfattd/category-service/internal/domain"
+)
+
+const (
+	spillExt    = ".json"
+	rejectedExt = ".rejected"
+)
+
+// spill keeps events that did not fit in memory as one file each, named by a sequence number
+// so they are read back in the order they were written.
+type spill struct {
+	dir string
+
+	// mu covers count and seq. Publishers hold it while deciding between the queue and the disk,
+	// so no event overtakes one that is spilled.
+	mu    sync.Mutex
+	count int
+	seq   uint64
+
+	wake chan struct{} // signals the refill that a file was written
+}
+
+func openSpill(dir string) (*spill, error) {
+	if dir == "" {
+		return nil, errors.New("spill directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create spill directory: %w", err)
+	}
+
+	s := &spill{dir: dir, wake: make(chan struct{}, 1)}
+
+	names, err := s.pending()
+	if err != nil {
+		return nil, err
+	}
+	s.count = len(names)
+	if len(names) > 0 {
+		last, _ := strconv.ParseUint(strings.TrimSuffix(names[len(names)-1], spillExt), 10, 64)
+		s.seq = last + 1
+	}
+
+	return s, nil
+}
+
+// push sends m to queue unless it is full or older events wait on disk, and spills it otherwise.
+func (s *spill) push(m *domain.OutboxMessage, queue chan<- *domain.OutboxMessage) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count == 0 {
+		select {
+		case queue <- m:
+			return false, nil
+		default:
+		}
+	}
+
+	return true, s.write(m)
+}
+
+// add spills m whatever the queue holds.
+func (s *spill) add(m *domain.OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.write(m)
+}
+
+// write has to be called with mu held. The file is renamed into place so a crash never leaves half an event.
+func (s *spill) write(m *domain.OutboxMessage) error {
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal spilled event: %w", err)
+	}
+
+	name := fmt.Sprintf("%020d%s", s.seq, spillExt)
+	tmp := filepath.Join(s.dir, name+".tmp")
+
+	if err := os.WriteFile(tmp, payload, 0o640); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, name)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	s.seq++
+	s.count++
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// pending lists the spilled files, oldest first.
+func (s *spill) pending() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spill directory: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), spillExt) {
+			names = append(names, e.Name())
+		}
+	}
+	slices.Sort(names)
+
+	return names, nil
+}
+
+func (s *spill) read(name string) (*domain.OutboxMessage, error) {
+	payload, err := os.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		return nil, err
+	}
+
+	var m domain.OutboxMessage
+	if err := json.Unmarshal(payload, &m); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+func (s *spill) remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.count--
+	return os.Remove(filepath.Join(s.dir, name))
+}
+
+// reject renames a file that cannot be read, so it neither blocks the ones after it nor gets lost.
+func (s *spill) reject(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.count--
+	os.Rename(filepath.Join(s.dir, name), filepath.Join(s.dir, name+rejectedExt))
+}
+
+func (s *spill) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.count
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/alfattd/category-service/internal/domain"
+)
+
+type APIKeyHandler struct {
+	service domain.APIKeyService
+}
+
+func NewAPIKeyHandler(service domain.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+func (h *APIKeyHandler) Issue(w http.ResponseWriter, r *http.Request) {
+	var req issueAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, apiErrorResponse{Errors: []string{"invalid request body"}})
+		return
+	}
+
+	issued, err := h.service.Issue(r.Context(), domain.IssueAPIKeyParams{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, apiResponse{
+		Message: "api key issued; the secret is only shown once",
+		Data: issuedAPIKeyResponse{
+			apiKeyResponse: toAPIKeyResponse(issued.Key),
+			Secret:         issued.Secret,
+		},
+	})
+}
+
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.List(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, apiResponse{
+		Data: toAPIKeyResponses(keys),
+	})
+}
+
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if err := h.service.Revoke(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, apiResponse{
+		Message: "api key revoked",
+	})
+}
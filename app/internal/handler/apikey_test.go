@@ -0,0 +1,112 @@
+package handler_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/handler"
+	"github.com/alfattd/category-service/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// ─── Issue ────────────────────────────────────────────────────────────────────
+
+func TestHandlerIssueAPIKey_Success_ReturnsSecretOnce(t *testing.T) {
+	svc := new(mocks.MockAPIKeyService)
+	issued := &domain.IssuedAPIKey{
+		Key:    &domain.APIKey{ID: "key-1", Name: "importer", Prefix: "csk_abcdefgh", Scopes: []string{domain.RoleCategoryWrite}, CreatedAt: time.Now()},
+		Secret: "csk_abcdefghsecret",
+	}
+
+	svc.On("Issue", mock.Anything, domain.IssueAPIKeyParams{Name: "importer", Scopes: []string{domain.RoleCategoryWrite}}).Return(issued, nil)
+
+	h := handler.NewAPIKeyHandler(svc)
+
+	body := bytes.NewBufferString(`{"name":"importer","scopes":["category:write"]}`)
+	r := httptest.NewRequest(http.MethodPost, "/admin/api-keys", body)
+	w := httptest.NewRecorder()
+
+	h.Issue(w, r)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	resp := decodeBody(t, w)
+	data := resp["data"].(map[string]any)
+	assert.Equal(t, "csk_abcdefghsecret", data["secret"])
+	assert.Equal(t, "csk_abcdefgh", data["prefix"])
+	assert.NotContains(t, data, "hash")
+}
+
+func TestHandlerIssueAPIKey_InvalidBody_ReturnsBadRequest(t *testing.T) {
+	svc := new(mocks.MockAPIKeyService)
+	h := handler.NewAPIKeyHandler(svc)
+
+	r := httptest.NewRequest(http.MethodPost, "/admin/api-keys", bytes.NewBufferString(`invalid-json`))
+	w := httptest.NewRecorder()
+
+	h.Issue(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assertErrors(t, decodeBody(t, w))
+	svc.AssertNotCalled(t, "Issue")
+}
+
+// ─── List ─────────────────────────────────────────────────────────────────────
+
+func TestHandlerListAPIKeys_OmitsSecrets(t *testing.T) {
+	svc := new(mocks.MockAPIKeyService)
+	svc.On("List", mock.Anything).Return([]*domain.APIKey{
+		{ID: "key-1", Name: "importer", Prefix: "csk_abcdefgh", Hash: "deadbeef", CreatedAt: time.Now()},
+	}, nil)
+
+	h := handler.NewAPIKeyHandler(svc)
+
+	r := httptest.NewRequest(http.MethodGet, "/admin/api-keys", nil)
+	w := httptest.NewRecorder()
+
+	h.List(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	resp := decodeBody(t, w)
+	data := resp["data"].([]any)
+	assert.Len(t, data, 1)
+	assert.NotContains(t, data[0], "secret")
+	assert.NotContains(t, data[0], "hash")
+}
+
+// ─── Revoke ───────────────────────────────────────────────────────────────────
+
+func TestHandlerRevokeAPIKey_Success(t *testing.T) {
+	svc := new(mocks.MockAPIKeyService)
+	svc.On("Revoke", mock.Anything, "key-1").Return(nil)
+
+	h := handler.NewAPIKeyHandler(svc)
+
+	r := httptest.NewRequest(http.MethodDelete, "/admin/api-keys/key-1", nil)
+	r.SetPathValue("id", "key-1")
+	w := httptest.NewRecorder()
+
+	h.Revoke(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "api key revoked", decodeBody(t, w)["message"])
+}
+
+func TestHandlerRevokeAPIKey_NotFound_Returns404(t *testing.T) {
+	svc := new(mocks.MockAPIKeyService)
+	svc.On("Revoke", mock.Anything, "missing").Return(domain.ErrNotFound)
+
+	h := handler.NewAPIKeyHandler(svc)
+
+	r := httptest.NewRequest(http.MethodDelete, "/admin/api-keys/missing", nil)
+	r.SetPathValue("id", "missing")
+	w := httptest.NewRecorder()
+
+	h.Revoke(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
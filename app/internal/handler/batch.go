@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/alfattd/category-service/internal/domain"
+)
+
+// Batch answers 200 when every operation succeeded. Otherwise a best-effort batch answers
+// 207 Multi-Status, and an atomic batch the status of the operation that rolled it back.
+func (h *CategoryHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	var req batchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, apiErrorResponse{Errors: []string{"invalid request body"}})
+		return
+	}
+
+	ops := make([]domain.BatchOp, len(req.Operations))
+	for i, op := range req.Operations {
+		ops[i] = domain.BatchOp{
+			Op:       op.Op,
+			ID:       op.ID,
+			Name:     op.Name,
+			Slug:     op.Slug,
+			ParentID: op.ParentID,
+			Version:  op.Version,
+		}
+	}
+
+	results, err := h.service.Batch(r.Context(), domain.BatchParams{Ops: ops, Atomic: req.Atomic})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	resp := batchResponse{Atomic: req.Atomic, Results: make([]batchItemResponse, len(results))}
+	status := http.StatusOK
+
+	for i, res := range results {
+		item := batchItemResponse{Index: i, Op: ops[i].Op, ID: ops[i].ID}
+
+		switch {
+		case res.Err != nil:
+			item.Status, item.Errors = errorStatus(res.Err)
+			resp.Failed++
+			if req.Atomic && item.Status != http.StatusFailedDependency {
+				status = item.Status
+			}
+		case ops[i].Op == domain.BatchCreate:
+			item.Status = http.StatusCreated
+		default:
+			item.Status = http.StatusOK
+		}
+
+		if res.Category != nil {
+			c := toCategoryResponse(res.Category)
+			item.ID = c.ID
+			item.Data = &c
+		}
+		if res.Err == nil {
+			resp.Succeeded++
+		}
+
+		resp.Results[i] = item
+	}
+
+	message := "batch applied"
+	switch {
+	case resp.Failed == 0:
+	case req.Atomic:
+		message = "batch rolled back"
+	default:
+		message = "batch partially applied"
+		status = http.StatusMultiStatus
+	}
+
+	writeJSON(w, status, apiResponse{Message: message, Data: resp})
+}
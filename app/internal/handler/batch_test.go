@@ -0,0 +1,107 @@
+package handler_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/handler"
+	"github.com/alfattd/category-service/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func serveBatch(t *testing.T, svc *mocks.MockCategoryService, body string) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodPost, "/categories:batch", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+
+	h.Batch(w, r)
+
+	resp := decodeBody(t, w)
+	data, _ := resp["data"].(map[string]any)
+	return w, data
+}
+
+func TestHandlerBatch_AllSucceeded_ReturnsOK(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	cat := &domain.Category{ID: "new-1", Name: "Electronics", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	svc.On("Batch", mock.Anything, domain.BatchParams{Ops: []domain.BatchOp{
+		{Op: domain.BatchCreate, Name: "Electronics"},
+		{Op: domain.BatchDelete, ID: "old-1", Version: 2},
+	}}).Return([]domain.BatchResult{{Category: cat}, {}}, nil)
+
+	w, data := serveBatch(t, svc, `{"operations":[
+		{"op":"create","name":"Electronics"},
+		{"op":"delete","id":"old-1","version":2}
+	]}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	results := data["results"].([]any)
+	require.Len(t, results, 2)
+
+	first := results[0].(map[string]any)
+	assert.Equal(t, float64(http.StatusCreated), first["status"])
+	assert.Equal(t, "new-1", first["id"])
+	second := results[1].(map[string]any)
+	assert.Equal(t, float64(http.StatusOK), second["status"])
+	assert.Equal(t, "old-1", second["id"])
+	assert.Equal(t, float64(2), data["succeeded"])
+}
+
+func TestHandlerBatch_BestEffortWithFailures_ReturnsMultiStatus(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	cat := &domain.Category{ID: "new-1", Name: "Electronics", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	svc.On("Batch", mock.Anything, mock.Anything).Return([]domain.BatchResult{{Category: cat}, {Err: domain.ErrNotFound}}, nil)
+
+	w, data := serveBatch(t, svc, `{"operations":[{"op":"create","name":"Electronics"},{"op":"delete","id":"missing"}]}`)
+
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	assert.Equal(t, float64(1), data["succeeded"])
+	assert.Equal(t, float64(1), data["failed"])
+
+	failed := data["results"].([]any)[1].(map[string]any)
+	assert.Equal(t, float64(http.StatusNotFound), failed["status"])
+	assert.NotEmpty(t, failed["errors"])
+}
+
+func TestHandlerBatch_AtomicFailure_ReturnsStatusOfFailedOperation(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+
+	svc.On("Batch", mock.Anything, mock.MatchedBy(func(p domain.BatchParams) bool { return p.Atomic })).
+		Return([]domain.BatchResult{{Err: domain.ErrBatchAborted}, {Err: domain.ErrDuplicate}}, nil)
+
+	w, data := serveBatch(t, svc, `{"atomic":true,"operations":[{"op":"create","name":"A"},{"op":"create","name":"A"}]}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	results := data["results"].([]any)
+	assert.Equal(t, float64(http.StatusFailedDependency), results[0].(map[string]any)["status"])
+	assert.Equal(t, float64(http.StatusConflict), results[1].(map[string]any)["status"])
+}
+
+func TestHandlerBatch_TxUnsupported_ReturnsNotImplemented(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	svc.On("Batch", mock.Anything, mock.Anything).Return(nil, domain.ErrTxUnsupported)
+
+	w, _ := serveBatch(t, svc, `{"atomic":true,"operations":[{"op":"create","name":"A"}]}`)
+
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+func TestHandlerBatch_InvalidBody_ReturnsBadRequest(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+
+	w, _ := serveBatch(t, svc, `[]`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNotCalled(t, "Batch")
+}
@@ -0,0 +1,389 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/validator"
+)
+
+const (
+	formatCSV    = "csv"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+
+	maxImportSize = 10 << 20
+
+	// importTimeout bounds reading and answering an import, which can take longer than the
+	// server's timeouts allow for other requests.
+	importTimeout = 5 * time.Minute
+)
+
+var exportColumns = []string{"id", "name", "slug", "parent_id", "version", "created_at", "updated_at"}
+
+var contentTypes = map[string]string{
+	formatCSV:    "text/csv; charset=utf-8",
+	formatJSON:   "application/json",
+	formatNDJSON: "application/x-ndjson",
+}
+
+// Export streams the catalog as it is read. The status line goes out with the first category,
+// so an error after that can only cut the body short; JSON output is then left unterminated.
+func (h *CategoryHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format, ok := catalogFormat(r)
+	if !ok {
+		writeError(w, formatError())
+		return
+	}
+
+	// A large catalog may take longer than the server's write timeout.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	enc := newCatalogEncoder(format, w)
+
+	started := false
+	begin := func() error {
+		started = true
+		w.Header().Set("Content-Type", contentTypes[format])
+		w.Header().Set("Content-Disposition", `attachment; filename="categories.`+format+`"`)
+		return enc.begin()
+	}
+
+	err := h.service.Export(r.Context(), func(c *domain.Category) error {
+		if !started {
+			if err := begin(); err != nil {
+				return err
+			}
+		}
+		return enc.encode(toCategoryResponse(c))
+	})
+	if err != nil {
+		if !started {
+			writeError(w, err)
+		}
+		return
+	}
+
+	if !started {
+		if err := begin(); err != nil {
+			return
+		}
+	}
+	_ = enc.end()
+}
+
+// Import reads the whole file before handing it to the service, which checks it as a whole.
+func (h *CategoryHandler) Import(w http.ResponseWriter, r *http.Request) {
+	format, ok := catalogFormat(r)
+	if !ok {
+		writeError(w, formatError())
+		return
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = domain.ImportCreateOnly
+	}
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Now().Add(importTimeout))
+	_ = rc.SetWriteDeadline(time.Now().Add(importTimeout))
+
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	var rows []domain.ImportRow
+	var err error
+
+	switch format {
+	case formatCSV:
+		rows, err = decodeCSVRows(body)
+	case formatJSON:
+		rows, err = decodeJSONRows(body)
+	case formatNDJSON:
+		rows, err = decodeNDJSONRows(body)
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeJSON(w, http.StatusRequestEntityTooLarge, apiErrorResponse{
+				Errors: []string{fmt.Sprintf("import must not exceed %d bytes", maxImportSize)},
+			})
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	result, err := h.service.Import(r.Context(), domain.ImportParams{Rows: rows, Mode: mode, DryRun: dryRun})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	message := "catalog imported"
+	if dryRun {
+		message = "dry run, nothing was changed"
+	}
+
+	writeJSON(w, http.StatusOK, apiResponse{
+		Message: message,
+		Data:    toImportResponse(result, mode, dryRun),
+	})
+}
+
+func catalogFormat(r *http.Request) (string, bool) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		return formatJSON, true
+	}
+
+	_, ok := contentTypes[format]
+	return format, ok
+}
+
+func formatError() error {
+	errs := &validator.ErrorsValidator{}
+	errs.Add(fmt.Sprintf("format must be one of %s, %s or %s", formatCSV, formatJSON, formatNDJSON))
+	return errs
+}
+
+type catalogEncoder interface {
+	begin() error
+	encode(c categoryResponse) error
+	end() error
+}
+
+func newCatalogEncoder(format string, w io.Writer) catalogEncoder {
+	switch format {
+	case formatCSV:
+		return &csvEncoder{w: csv.NewWriter(w)}
+	case formatNDJSON:
+		return &ndjsonEncoder{enc: json.NewEncoder(w)}
+	default:
+		return &jsonEncoder{w: w}
+	}
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvEncoder) begin() error {
+	return e.w.Write(exportColumns)
+}
+
+func (e *csvEncoder) encode(c categoryResponse) error {
+	parentID := ""
+	if c.ParentID != nil {
+		parentID = *c.ParentID
+	}
+	record := []string{c.ID, c.Name, c.Slug, parentID, strconv.FormatInt(c.Version, 10), c.CreatedAt, c.UpdatedAt}
+	for i, cell := range record {
+		record[i] = escapeCSVCell(cell)
+	}
+	return e.w.Write(record)
+}
+
+// csvFormulaTriggers start a cell that spreadsheets would evaluate as a formula.
+const csvFormulaTriggers = "=+-@\t\r"
+
+// escapeCSVCell defuses formulas the way spreadsheets expect, with a leading apostrophe. Cells
+// that already start with apostrophes before a trigger get one more, so unescapeCSVCell restores
+// them exactly while a name like "'Tis" is left alone both ways.
+func escapeCSVCell(cell string) string {
+	if isEscapedFormula("'" + cell) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func unescapeCSVCell(cell string) string {
+	if isEscapedFormula(cell) {
+		return cell[1:]
+	}
+	return cell
+}
+
+// isEscapedFormula reports whether cell is one or more apostrophes followed by a formula trigger.
+func isEscapedFormula(cell string) bool {
+	rest := strings.TrimLeft(cell, "'")
+	return len(rest) < len(cell) && rest != "" && strings.ContainsRune(csvFormulaTriggers, rune(rest[0]))
+}
+
+func (e *csvEncoder) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonEncoder writes a single array, one element at a time.
+type jsonEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonEncoder) begin() error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonEncoder) encode(c categoryResponse) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	if e.count > 0 {
+		b = append([]byte(","), b...)
+	}
+	e.count++
+
+	_, err = e.w.Write(b)
+	return err
+}
+
+func (e *jsonEncoder) end() error {
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+type ndjsonEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonEncoder) begin() error { return nil }
+
+func (e *ndjsonEncoder) encode(c categoryResponse) error { return e.enc.Encode(c) }
+
+func (e *ndjsonEncoder) end() error { return nil }
+
+// decodeCSVRows expects a header naming the columns; only name is required, and columns other
+// than id, name, slug and parent_id are ignored so that an export can be imported again. Formula
+// escapes are only removed from files with the header of an export, the only ones they were added
+// to; other files are taken as they are. Rows are numbered by the line they start on, as a
+// spreadsheet shows them.
+func decodeCSVRows(body io.Reader) ([]domain.ImportRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, csvError(err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Spreadsheets tend to start UTF-8 files with a byte order mark.
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		header[i] = name
+		columns[name] = i
+	}
+	exported := slices.Equal(header, exportColumns)
+
+	if _, ok := columns["name"]; !ok {
+		return nil, &validator.ErrorsValidator{Messages: []string{"csv header must contain a name column"}}
+	}
+
+	field := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			if exported {
+				return unescapeCSVCell(record[i])
+			}
+			return record[i]
+		}
+		return ""
+	}
+
+	var rows []domain.ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, csvError(err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := domain.ImportRow{Row: line, ID: field(record, "id"), Name: field(record, "name"), Slug: field(record, "slug")}
+		if parentID := field(record, "parent_id"); strings.TrimSpace(parentID) != "" {
+			row.ParentID = &parentID
+		}
+
+		rows = append(rows, row)
+	}
+}
+
+func csvError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &validator.ErrorsValidator{Messages: []string{fmt.Sprintf("row %d: %v", parseErr.StartLine, parseErr.Err)}}
+	}
+	if err == io.EOF {
+		return &validator.ErrorsValidator{Messages: []string{"csv must start with a header row"}}
+	}
+	return err
+}
+
+// decodeJSONRows numbers rows by their position in the array, starting at 1.
+func decodeJSONRows(body io.Reader) ([]domain.ImportRow, error) {
+	var req []importRowRequest
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, err
+		}
+		return nil, invalidBody()
+	}
+
+	rows := make([]domain.ImportRow, len(req))
+	for i, r := range req {
+		rows[i] = domain.ImportRow{Row: i + 1, ID: r.ID, Name: r.Name, Slug: r.Slug, ParentID: r.ParentID}
+	}
+
+	return rows, nil
+}
+
+// decodeNDJSONRows numbers rows by line and skips blank lines.
+func decodeNDJSONRows(body io.Reader) ([]domain.ImportRow, error) {
+	reader := bufio.NewReader(body)
+	errs := &validator.ErrorsValidator{}
+
+	var rows []domain.ImportRow
+	for line := 1; ; line++ {
+		b, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		if b = bytes.TrimSpace(b); len(b) > 0 {
+			var r importRowRequest
+			if jsonErr := json.Unmarshal(b, &r); jsonErr != nil {
+				errs.Add(fmt.Sprintf("row %d: invalid JSON", line))
+			} else {
+				rows = append(rows, domain.ImportRow{Row: line, ID: r.ID, Name: r.Name, Slug: r.Slug, ParentID: r.ParentID})
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if errs.HasErrors() {
+		return nil, errs
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,232 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/handler"
+	"github.com/alfattd/category-service/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func exportCatalog() []*domain.Category {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	parentID := "root-1"
+	return []*domain.Category{
+		{ID: "root-1", Name: "Electronics", Slug: "electronics", Version: 1, CreatedAt: created, UpdatedAt: created},
+		{ID: "child-1", Name: "Phones, Tablets", Slug: "phones-tablets", ParentID: &parentID, Version: 2, CreatedAt: created, UpdatedAt: created},
+	}
+}
+
+func serveExport(svc *mocks.MockCategoryService, query string) *httptest.ResponseRecorder {
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodGet, "/categories/export"+query, nil)
+	w := httptest.NewRecorder()
+
+	h.Export(w, r)
+	return w
+}
+
+func TestHandlerExport_CSV_WritesHeaderAndRows(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	svc.On("Export", mock.Anything).Return(exportCatalog(), nil)
+
+	w := serveExport(svc, "?format=csv")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "id,name,slug,parent_id,version,created_at,updated_at\n"+
+		"root-1,Electronics,electronics,,1,2024-01-02T03:04:05Z,2024-01-02T03:04:05Z\n"+
+		`child-1,"Phones, Tablets",phones-tablets,root-1,2,2024-01-02T03:04:05Z,2024-01-02T03:04:05Z`+"\n", w.Body.String())
+}
+
+func TestHandlerExport_CSV_EscapesFormulas(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	names := []string{"=HYPERLINK(\"http://evil.test\")", "+1", "-1", "@SUM(A1)", "\tTab", "\rReturn", "'=already", "'Tis", "Plain"}
+
+	var catalog []*domain.Category
+	for i, name := range names {
+		catalog = append(catalog, &domain.Category{ID: strconv.Itoa(i), Name: name, Slug: "s", Version: 1, CreatedAt: created, UpdatedAt: created})
+	}
+
+	svc := new(mocks.MockCategoryService)
+	svc.On("Export", mock.Anything).Return(catalog, nil)
+
+	w := serveExport(svc, "?format=csv")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	require.NoError(t, err)
+
+	var exported []string
+	for _, record := range records[1:] {
+		exported = append(exported, record[1])
+	}
+	assert.Equal(t, []string{"'=HYPERLINK(\"http://evil.test\")", "'+1", "'-1", "'@SUM(A1)", "'\tTab", "'\rReturn", "''=already", "'Tis", "Plain"}, exported)
+
+	// Importing the export gives back the original names.
+	var rows []domain.ImportRow
+	for i, name := range names {
+		rows = append(rows, domain.ImportRow{Row: i + 2, ID: strconv.Itoa(i), Name: name, Slug: "s"})
+	}
+	svc.On("Import", mock.Anything, domain.ImportParams{Mode: domain.ImportUpsert, Rows: rows}).Return(&domain.ImportResult{}, nil)
+
+	w = serveImport(svc, "?format=csv&mode=upsert", w.Body.String())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestHandlerExport_JSON_WritesArray(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	svc.On("Export", mock.Anything).Return(exportCatalog(), nil)
+
+	w := serveExport(svc, "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var body []map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body, 2)
+	assert.Equal(t, "child-1", body[1]["id"])
+	assert.Equal(t, "root-1", body[1]["parent_id"])
+}
+
+func TestHandlerExport_EmptyCatalog_WritesEmptyArray(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	svc.On("Export", mock.Anything).Return(nil, nil)
+
+	w := serveExport(svc, "?format=json")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[]\n", w.Body.String())
+}
+
+func TestHandlerExport_NDJSON_WritesOneCategoryPerLine(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	svc.On("Export", mock.Anything).Return(exportCatalog(), nil)
+
+	w := serveExport(svc, "?format=ndjson")
+
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"id":"root-1"`)
+}
+
+func TestHandlerExport_InvalidFormat_ReturnsBadRequest(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+
+	w := serveExport(svc, "?format=xml")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNotCalled(t, "Export")
+}
+
+func serveImport(svc *mocks.MockCategoryService, query, body string) *httptest.ResponseRecorder {
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodPost, "/categories/import"+query, bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+
+	h.Import(w, r)
+	return w
+}
+
+func TestHandlerImport_CSV_NumbersRowsByLine(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	parentID := "root-1"
+
+	svc.On("Import", mock.Anything, domain.ImportParams{Mode: domain.ImportUpsert, Rows: []domain.ImportRow{
+		{Row: 2, ID: "root-1", Name: "Electronics", Slug: "gadgets"},
+		{Row: 3, ID: "", Name: "Phones\nand Tablets", ParentID: &parentID},
+		{Row: 5, ID: "", Name: "Laptops"},
+	}}).Return(&domain.ImportResult{Created: 3}, nil)
+
+	w := serveImport(svc, "?format=csv&mode=upsert",
+		"\ufeffid,name,parent_id,version,slug\n"+
+			"root-1,Electronics,,1,gadgets\n"+
+			"\"\",\"Phones\nand Tablets\",root-1\n"+
+			",Laptops,\n")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	data := decodeBody(t, w)["data"].(map[string]any)
+	assert.Equal(t, "upsert", data["mode"])
+	assert.Equal(t, float64(3), data["created"])
+}
+
+func TestHandlerImport_CSVNotFromExport_KeepsLeadingApostrophes(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	svc.On("Import", mock.Anything, domain.ImportParams{Mode: domain.ImportCreateOnly, Rows: []domain.ImportRow{
+		{Row: 2, Name: "'=quoted"},
+		{Row: 3, Name: "'Tis"},
+	}}).Return(&domain.ImportResult{}, nil)
+
+	w := serveImport(svc, "?format=csv", "name\n'=quoted\n'Tis\n")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestHandlerImport_CSVWithoutNameColumn_ReturnsBadRequest(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+
+	w := serveImport(svc, "?format=csv", "id,title\n1,Electronics\n")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, []any{"csv header must contain a name column"}, decodeBody(t, w)["errors"])
+	svc.AssertNotCalled(t, "Import")
+}
+
+func TestHandlerImport_NDJSONWithInvalidLine_ReturnsRowError(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+
+	w := serveImport(svc, "?format=ndjson", "{\"name\":\"Electronics\"}\n\n{oops\n")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, []any{"row 3: invalid JSON"}, decodeBody(t, w)["errors"])
+}
+
+func TestHandlerImport_DryRun_ReportsPlannedChanges(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	cat := &domain.Category{ID: "root-1", Name: "Electronics"}
+
+	svc.On("Import", mock.Anything, domain.ImportParams{
+		Mode: domain.ImportCreateOnly, DryRun: true, Rows: []domain.ImportRow{{Row: 1, Name: "Electronics"}},
+	}).Return(&domain.ImportResult{Created: 1, Changes: []domain.ImportChange{
+		{Row: 1, Action: domain.ImportCreated, Category: cat},
+	}}, nil)
+
+	w := serveImport(svc, "?dry_run=true", `[{"name":"Electronics"}]`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	resp := decodeBody(t, w)
+	assert.Equal(t, "dry run, nothing was changed", resp["message"])
+
+	data := resp["data"].(map[string]any)
+	assert.Equal(t, true, data["dry_run"])
+	change := data["changes"].([]any)[0].(map[string]any)
+	assert.Equal(t, "created", change["action"])
+	assert.Equal(t, "root-1", change["id"])
+}
+
+func TestHandlerImport_TooLarge_ReturnsRequestEntityTooLarge(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+
+	w := serveImport(svc, "?format=ndjson", strings.Repeat("{\"name\":\"Electronics\"}\n", 1<<19))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	svc.AssertNotCalled(t, "Import")
+}
@@ -2,7 +2,11 @@ package handler
 
 import (
 	"encoding/json"
+	"mime"
 	"net/http"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/validator"
 )
 
 func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -12,12 +16,17 @@ func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	category, err := h.service.Create(r.Context(), req.Name)
+	category, err := h.service.Create(r.Context(), domain.CreateCategoryParams{
+		Name:     req.Name,
+		Slug:     req.Slug,
+		ParentID: req.ParentID,
+	})
 	if err != nil {
 		writeError(w, err)
 		return
 	}
 
+	setETag(w, category)
 	writeJSON(w, http.StatusCreated, apiResponse{
 		Message: "category created",
 		Data:    toCategoryResponse(category),
@@ -33,12 +42,71 @@ func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	category, err := h.service.Update(r.Context(), id, req.Name)
+	version, err := ifMatchVersion(r)
 	if err != nil {
 		writeError(w, err)
 		return
 	}
 
+	category, err := h.service.Update(r.Context(), id, domain.UpdateCategoryParams{
+		Name:     req.Name,
+		Slug:     req.Slug,
+		ParentID: req.ParentID,
+		Version:  version,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, category)
+	writeJSON(w, http.StatusOK, apiResponse{
+		Message: "category updated",
+		Data:    toCategoryResponse(category),
+	})
+}
+
+// Patch accepts a JSON Merge Patch or a JSON Patch, told apart by the Content-Type.
+func (h *CategoryHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var ops []domain.PatchOp
+	var errs *validator.ErrorsValidator
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case mediaTypeMergePatch:
+		ops, errs = decodeMergePatch(r.Body)
+	case mediaTypeJSONPatch:
+		ops, errs = decodeJSONPatch(r.Body)
+	default:
+		w.Header().Set("Accept-Patch", mediaTypeMergePatch+", "+mediaTypeJSONPatch)
+		writeJSON(w, http.StatusUnsupportedMediaType, apiErrorResponse{
+			Errors: []string{"content type must be " + mediaTypeMergePatch + " or " + mediaTypeJSONPatch},
+		})
+		return
+	}
+	if errs != nil {
+		writeError(w, errs)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	category, err := h.service.Patch(r.Context(), id, domain.PatchCategoryParams{
+		Ops:     ops,
+		Version: version,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, category)
 	writeJSON(w, http.StatusOK, apiResponse{
 		Message: "category updated",
 		Data:    toCategoryResponse(category),
@@ -48,12 +116,63 @@ func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
-	if err := h.service.Delete(r.Context(), id); err != nil {
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	purge := r.URL.Query().Get("purge") == "true"
+
+	if err := h.service.Delete(r.Context(), id, domain.DeleteCategoryParams{Version: version, Purge: purge}); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	message := "category deleted"
+	if purge {
+		message = "category purged"
+	}
+
+	writeJSON(w, http.StatusOK, apiResponse{
+		Message: message,
+	})
+}
+
+func (h *CategoryHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	category, err := h.service.Restore(r.Context(), id)
+	if err != nil {
 		writeError(w, err)
 		return
 	}
 
+	setETag(w, category)
 	writeJSON(w, http.StatusOK, apiResponse{
-		Message: "category deleted",
+		Message: "category restored",
+		Data:    toCategoryResponse(category),
+	})
+}
+
+func (h *CategoryHandler) Move(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var req moveCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, apiErrorResponse{Errors: []string{"invalid request body"}})
+		return
+	}
+
+	category, err := h.service.Move(r.Context(), id, req.ParentID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, category)
+	writeJSON(w, http.StatusOK, apiResponse{
+		Message: "category moved",
+		Data:    toCategoryResponse(category),
 	})
 }
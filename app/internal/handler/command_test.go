@@ -42,7 +42,7 @@ func TestHandlerCreate_Success(t *testing.T) {
 	svc := new(mocks.MockCategoryService)
 	cat := &domain.Category{ID: "abc-123", Name: "Electronics", CreatedAt: time.Now(), UpdatedAt: time.Now()}
 
-	svc.On("Create", mock.Anything, "Electronics").Return(cat, nil)
+	svc.On("Create", mock.Anything, domain.CreateCategoryParams{Name: "Electronics"}).Return(cat, nil)
 
 	h := handler.NewCategoryHandler(svc)
 
@@ -97,7 +97,7 @@ func TestHandlerCreate_ValidationErrors_ReturnsBadRequestWithAllErrors(t *testin
 func TestHandlerCreate_ErrDuplicate_ReturnsConflict(t *testing.T) {
 	svc := new(mocks.MockCategoryService)
 
-	svc.On("Create", mock.Anything, "Electronics").Return(nil, domain.ErrDuplicate)
+	svc.On("Create", mock.Anything, domain.CreateCategoryParams{Name: "Electronics"}).Return(nil, domain.ErrDuplicate)
 
 	h := handler.NewCategoryHandler(svc)
 
@@ -112,10 +112,27 @@ func TestHandlerCreate_ErrDuplicate_ReturnsConflict(t *testing.T) {
 	assertErrors(t, resp)
 }
 
+func TestHandlerCreate_DuplicateName_NamesConflictingCategory(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+
+	svc.On("Create", mock.Anything, domain.CreateCategoryParams{Name: "books"}).
+		Return(nil, &domain.DuplicateError{Field: domain.FieldName, ID: "abc-123"})
+
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodPost, "/categories", bytes.NewBufferString(`{"name":"books"}`))
+	w := httptest.NewRecorder()
+
+	h.Create(w, r)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, []any{"name is already used by category abc-123"}, assertErrors(t, decodeBody(t, w)))
+}
+
 func TestHandlerCreate_InternalError_ReturnsInternalServerError(t *testing.T) {
 	svc := new(mocks.MockCategoryService)
 
-	svc.On("Create", mock.Anything, "Electronics").Return(nil, assert.AnError)
+	svc.On("Create", mock.Anything, domain.CreateCategoryParams{Name: "Electronics"}).Return(nil, assert.AnError)
 
 	h := handler.NewCategoryHandler(svc)
 
@@ -130,13 +147,53 @@ func TestHandlerCreate_InternalError_ReturnsInternalServerError(t *testing.T) {
 	assertErrors(t, resp)
 }
 
+func TestHandlerCreate_WithParent_PassesParentID(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	parentID := "parent-1"
+	cat := &domain.Category{ID: "abc-123", Name: "Phones", ParentID: &parentID, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	svc.On("Create", mock.Anything, domain.CreateCategoryParams{Name: "Phones", ParentID: &parentID}).Return(cat, nil)
+
+	h := handler.NewCategoryHandler(svc)
+
+	body := bytes.NewBufferString(`{"name":"Phones","parent_id":"parent-1"}`)
+	r := httptest.NewRequest(http.MethodPost, "/categories", body)
+	w := httptest.NewRecorder()
+
+	h.Create(w, r)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	resp := decodeBody(t, w)
+	data := resp["data"].(map[string]any)
+	assert.Equal(t, parentID, data["parent_id"])
+	svc.AssertExpectations(t)
+}
+
+func TestHandlerCreate_ErrInvalidParent_ReturnsUnprocessableEntity(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+
+	svc.On("Create", mock.Anything, mock.Anything).Return(nil, domain.ErrInvalidParent)
+
+	h := handler.NewCategoryHandler(svc)
+
+	body := bytes.NewBufferString(`{"name":"Phones","parent_id":"not-exist"}`)
+	r := httptest.NewRequest(http.MethodPost, "/categories", body)
+	w := httptest.NewRecorder()
+
+	h.Create(w, r)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	resp := decodeBody(t, w)
+	assertErrors(t, resp)
+}
+
 // ─── Update ───────────────────────────────────────────────────────────────────
 
 func TestHandlerUpdate_Success(t *testing.T) {
 	svc := new(mocks.MockCategoryService)
 	cat := &domain.Category{ID: "abc-123", Name: "New Name", CreatedAt: time.Now(), UpdatedAt: time.Now()}
 
-	svc.On("Update", mock.Anything, "abc-123", "New Name").Return(cat, nil)
+	svc.On("Update", mock.Anything, "abc-123", domain.UpdateCategoryParams{Name: "New Name"}).Return(cat, nil)
 
 	h := handler.NewCategoryHandler(svc)
 
@@ -176,7 +233,7 @@ func TestHandlerUpdate_ValidationErrors_ReturnsBadRequest(t *testing.T) {
 func TestHandlerUpdate_NotFound_Returns404(t *testing.T) {
 	svc := new(mocks.MockCategoryService)
 
-	svc.On("Update", mock.Anything, "not-exist", "New Name").Return(nil, domain.ErrNotFound)
+	svc.On("Update", mock.Anything, "not-exist", domain.UpdateCategoryParams{Name: "New Name"}).Return(nil, domain.ErrNotFound)
 
 	h := handler.NewCategoryHandler(svc)
 
@@ -192,12 +249,199 @@ func TestHandlerUpdate_NotFound_Returns404(t *testing.T) {
 	assertErrors(t, resp)
 }
 
+func TestHandlerUpdate_IfMatch_PassesVersionAndSetsETag(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	cat := &domain.Category{ID: "abc-123", Name: "New Name", Version: 4, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	svc.On("Update", mock.Anything, "abc-123", domain.UpdateCategoryParams{Name: "New Name", Version: 3}).Return(cat, nil)
+
+	h := handler.NewCategoryHandler(svc)
+
+	body := bytes.NewBufferString(`{"name":"New Name"}`)
+	r := httptest.NewRequest(http.MethodPut, "/categories/abc-123", body)
+	r.SetPathValue("id", "abc-123")
+	r.Header.Set("If-Match", `"3"`)
+	w := httptest.NewRecorder()
+
+	h.Update(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	svc.AssertExpectations(t)
+}
+
+func TestHandlerUpdate_StaleVersion_ReturnsPreconditionFailed(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+
+	svc.On("Update", mock.Anything, "abc-123", domain.UpdateCategoryParams{Name: "New Name", Version: 1}).Return(nil, domain.ErrPreconditionFailed)
+
+	h := handler.NewCategoryHandler(svc)
+
+	body := bytes.NewBufferString(`{"name":"New Name"}`)
+	r := httptest.NewRequest(http.MethodPut, "/categories/abc-123", body)
+	r.SetPathValue("id", "abc-123")
+	r.Header.Set("If-Match", `"1"`)
+	w := httptest.NewRecorder()
+
+	h.Update(w, r)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	resp := decodeBody(t, w)
+	assertErrors(t, resp)
+}
+
+func TestHandlerUpdate_MalformedIfMatch_ReturnsPreconditionFailed(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	h := handler.NewCategoryHandler(svc)
+
+	body := bytes.NewBufferString(`{"name":"New Name"}`)
+	r := httptest.NewRequest(http.MethodPut, "/categories/abc-123", body)
+	r.SetPathValue("id", "abc-123")
+	r.Header.Set("If-Match", `W/"abc"`)
+	w := httptest.NewRecorder()
+
+	h.Update(w, r)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	svc.AssertNotCalled(t, "Update")
+}
+
+// ─── Patch ────────────────────────────────────────────────────────────────────
+
+func TestHandlerPatch_MergePatch_PassesOps(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	cat := &domain.Category{ID: "abc-123", Name: "Phones", Version: 3, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	name := "Phones"
+	svc.On("Patch", mock.Anything, "abc-123", domain.PatchCategoryParams{
+		Ops: []domain.PatchOp{
+			{Op: domain.PatchReplace, Path: domain.FieldName, Value: &name},
+			{Op: domain.PatchRemove, Path: domain.FieldParentID},
+		},
+		Version: 2,
+	}).Return(cat, nil)
+
+	h := handler.NewCategoryHandler(svc)
+
+	body := bytes.NewBufferString(`{"parent_id":null,"name":"Phones"}`)
+	r := httptest.NewRequest(http.MethodPatch, "/categories/abc-123", body)
+	r.SetPathValue("id", "abc-123")
+	r.Header.Set("Content-Type", "application/merge-patch+json")
+	r.Header.Set("If-Match", `"2"`)
+	w := httptest.NewRecorder()
+
+	h.Patch(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	svc.AssertExpectations(t)
+}
+
+func TestHandlerPatch_JSONPatch_PassesOps(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	cat := &domain.Category{ID: "abc-123", Name: "Phones", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	oldName, newName := "Mobiles", "Phones"
+	svc.On("Patch", mock.Anything, "abc-123", domain.PatchCategoryParams{
+		Ops: []domain.PatchOp{
+			{Op: domain.PatchTest, Path: domain.FieldName, Value: &oldName},
+			{Op: domain.PatchReplace, Path: domain.FieldName, Value: &newName},
+			{Op: domain.PatchCopy, Path: domain.FieldParentID, From: domain.FieldName},
+		},
+	}).Return(cat, nil)
+
+	h := handler.NewCategoryHandler(svc)
+
+	body := bytes.NewBufferString(`[
+		{"op":"test","path":"/name","value":"Mobiles"},
+		{"op":"replace","path":"/name","value":"Phones"},
+		{"op":"copy","from":"/name","path":"/parent_id"}
+	]`)
+	r := httptest.NewRequest(http.MethodPatch, "/categories/abc-123", body)
+	r.SetPathValue("id", "abc-123")
+	r.Header.Set("Content-Type", "application/json-patch+json")
+	w := httptest.NewRecorder()
+
+	h.Patch(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestHandlerPatch_JSONPatchWithoutValue_ReturnsBadRequest(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	h := handler.NewCategoryHandler(svc)
+
+	body := bytes.NewBufferString(`[{"op":"replace","path":"/name"},{"op":"remove","path":"name"}]`)
+	r := httptest.NewRequest(http.MethodPatch, "/categories/abc-123", body)
+	r.SetPathValue("id", "abc-123")
+	r.Header.Set("Content-Type", "application/json-patch+json")
+	w := httptest.NewRecorder()
+
+	h.Patch(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	errs := assertErrors(t, decodeBody(t, w))
+	assert.Len(t, errs, 2)
+	svc.AssertNotCalled(t, "Patch")
+}
+
+func TestHandlerPatch_MergePatchWithNonString_ReturnsBadRequest(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodPatch, "/categories/abc-123", bytes.NewBufferString(`{"name":42}`))
+	r.SetPathValue("id", "abc-123")
+	r.Header.Set("Content-Type", "application/merge-patch+json")
+	w := httptest.NewRecorder()
+
+	h.Patch(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	errs := assertErrors(t, decodeBody(t, w))
+	assert.Contains(t, errs, "name must be a string or null")
+	svc.AssertNotCalled(t, "Patch")
+}
+
+func TestHandlerPatch_UnsupportedContentType_Returns415(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodPatch, "/categories/abc-123", bytes.NewBufferString(`{"name":"Phones"}`))
+	r.SetPathValue("id", "abc-123")
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	h.Patch(w, r)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Contains(t, w.Header().Get("Accept-Patch"), "application/merge-patch+json")
+	svc.AssertNotCalled(t, "Patch")
+}
+
+func TestHandlerPatch_FailedTest_ReturnsConflict(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	svc.On("Patch", mock.Anything, "abc-123", mock.Anything).Return(nil, domain.ErrPatchTestFailed)
+
+	h := handler.NewCategoryHandler(svc)
+
+	body := bytes.NewBufferString(`[{"op":"test","path":"/name","value":"Mobiles"}]`)
+	r := httptest.NewRequest(http.MethodPatch, "/categories/abc-123", body)
+	r.SetPathValue("id", "abc-123")
+	r.Header.Set("Content-Type", "application/json-patch+json; charset=utf-8")
+	w := httptest.NewRecorder()
+
+	h.Patch(w, r)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
 // ─── Delete ───────────────────────────────────────────────────────────────────
 
 func TestHandlerDelete_Success(t *testing.T) {
 	svc := new(mocks.MockCategoryService)
 
-	svc.On("Delete", mock.Anything, "abc-123").Return(nil)
+	svc.On("Delete", mock.Anything, "abc-123", domain.DeleteCategoryParams{}).Return(nil)
 
 	h := handler.NewCategoryHandler(svc)
 
@@ -215,7 +459,7 @@ func TestHandlerDelete_Success(t *testing.T) {
 func TestHandlerDelete_NotFound_Returns404(t *testing.T) {
 	svc := new(mocks.MockCategoryService)
 
-	svc.On("Delete", mock.Anything, "not-exist").Return(domain.ErrNotFound)
+	svc.On("Delete", mock.Anything, "not-exist", domain.DeleteCategoryParams{}).Return(domain.ErrNotFound)
 
 	h := handler.NewCategoryHandler(svc)
 
@@ -229,3 +473,155 @@ func TestHandlerDelete_NotFound_Returns404(t *testing.T) {
 	resp := decodeBody(t, w)
 	assertErrors(t, resp)
 }
+
+func TestHandlerDelete_HasChildren_ReturnsConflict(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+
+	svc.On("Delete", mock.Anything, "abc-123", domain.DeleteCategoryParams{}).Return(domain.ErrHasChildren)
+
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodDelete, "/categories/abc-123", nil)
+	r.SetPathValue("id", "abc-123")
+	w := httptest.NewRecorder()
+
+	h.Delete(w, r)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	resp := decodeBody(t, w)
+	assertErrors(t, resp)
+}
+
+func TestHandlerDelete_Purge_PassesPurge(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+
+	svc.On("Delete", mock.Anything, "abc-123", domain.DeleteCategoryParams{Purge: true}).Return(nil)
+
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodDelete, "/categories/abc-123?purge=true", nil)
+	r.SetPathValue("id", "abc-123")
+	w := httptest.NewRecorder()
+
+	h.Delete(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	resp := decodeBody(t, w)
+	assert.Equal(t, "category purged", resp["message"])
+}
+
+func TestHandlerDelete_StaleVersion_ReturnsPreconditionFailed(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+
+	svc.On("Delete", mock.Anything, "abc-123", domain.DeleteCategoryParams{Version: 2}).Return(domain.ErrPreconditionFailed)
+
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodDelete, "/categories/abc-123", nil)
+	r.SetPathValue("id", "abc-123")
+	r.Header.Set("If-Match", `"2"`)
+	w := httptest.NewRecorder()
+
+	h.Delete(w, r)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	resp := decodeBody(t, w)
+	assertErrors(t, resp)
+}
+
+// ─── Restore ──────────────────────────────────────────────────────────────────
+
+func TestHandlerRestore_Success(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	cat := &domain.Category{ID: "abc-123", Name: "Electronics", Version: 3, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	svc.On("Restore", mock.Anything, "abc-123").Return(cat, nil)
+
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodPost, "/categories/abc-123/restore", nil)
+	r.SetPathValue("id", "abc-123")
+	w := httptest.NewRecorder()
+
+	h.Restore(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	resp := decodeBody(t, w)
+	assert.Equal(t, "category restored", resp["message"])
+}
+
+func TestHandlerRestore_NotDeleted_ReturnsConflict(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+
+	svc.On("Restore", mock.Anything, "abc-123").Return(nil, domain.ErrNotDeleted)
+
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodPost, "/categories/abc-123/restore", nil)
+	r.SetPathValue("id", "abc-123")
+	w := httptest.NewRecorder()
+
+	h.Restore(w, r)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	resp := decodeBody(t, w)
+	assertErrors(t, resp)
+}
+
+// ─── Move ─────────────────────────────────────────────────────────────────────
+
+func TestHandlerMove_Success(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	parentID := "parent-1"
+	cat := &domain.Category{ID: "abc-123", Name: "Phones", ParentID: &parentID, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	svc.On("Move", mock.Anything, "abc-123", &parentID).Return(cat, nil)
+
+	h := handler.NewCategoryHandler(svc)
+
+	body := bytes.NewBufferString(`{"parent_id":"parent-1"}`)
+	r := httptest.NewRequest(http.MethodPost, "/categories/abc-123/move", body)
+	r.SetPathValue("id", "abc-123")
+	w := httptest.NewRecorder()
+
+	h.Move(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	resp := decodeBody(t, w)
+	assert.Equal(t, "category moved", resp["message"])
+	svc.AssertExpectations(t)
+}
+
+func TestHandlerMove_MaxDepthExceeded_ReturnsUnprocessableEntity(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+
+	svc.On("Move", mock.Anything, "abc-123", mock.Anything).Return(nil, domain.ErrMaxDepthExceeded)
+
+	h := handler.NewCategoryHandler(svc)
+
+	body := bytes.NewBufferString(`{"parent_id":"parent-1"}`)
+	r := httptest.NewRequest(http.MethodPost, "/categories/abc-123/move", body)
+	r.SetPathValue("id", "abc-123")
+	w := httptest.NewRecorder()
+
+	h.Move(w, r)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	resp := decodeBody(t, w)
+	assertErrors(t, resp)
+}
+
+func TestHandlerMove_InvalidBody_ReturnsBadRequest(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodPost, "/categories/abc-123/move", bytes.NewBufferString(`invalid-json`))
+	r.SetPathValue("id", "abc-123")
+	w := httptest.NewRecorder()
+
+	h.Move(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNotCalled(t, "Move")
+}
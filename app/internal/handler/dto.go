@@ -1,38 +1,161 @@
 package handler
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/alfattd/category-service/internal/domain"
 )
 
 type createCategoryRequest struct {
-	Name string `json:"name"`
+	Name     string  `json:"name"`
+	Slug     string  `json:"slug"`
+	ParentID *string `json:"parent_id"`
 }
 
 type updateCategoryRequest struct {
-	Name string `json:"name"`
+	Name     string  `json:"name"`
+	Slug     string  `json:"slug"`
+	ParentID *string `json:"parent_id"`
+}
+
+// jsonPatchOperation is one operation of an RFC 6902 JSON Patch. Value stays raw so that an
+// explicit null can be told apart from a missing value.
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+type batchRequest struct {
+	Atomic     bool                    `json:"atomic"`
+	Operations []batchOperationRequest `json:"operations"`
+}
+
+type batchOperationRequest struct {
+	Op       string  `json:"op"`
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Slug     string  `json:"slug"`
+	ParentID *string `json:"parent_id"`
+	Version  int64   `json:"version"`
+}
+
+type importRowRequest struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Slug     string  `json:"slug"`
+	ParentID *string `json:"parent_id"`
+}
+
+type moveCategoryRequest struct {
+	ParentID *string `json:"parent_id"`
+}
+
+type issueAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
 }
 
 type categoryResponse struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Slug      string  `json:"slug"`
+	ParentID  *string `json:"parent_id"`
+	Version   int64   `json:"version"`
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
+	DeletedAt *string `json:"deleted_at,omitempty"`
+}
+
+// slugLookupResponse tells the client to redirect when the slug it asked for is a former one.
+type slugLookupResponse struct {
+	categoryResponse
+	Redirect bool `json:"redirect"`
+}
+
+type categoryNodeResponse struct {
+	categoryResponse
+	Children []categoryNodeResponse `json:"children"`
 }
 
 type paginationMeta struct {
-	Page       int  `json:"page"`
-	Limit      int  `json:"limit"`
-	Total      int  `json:"total"`
-	TotalPages int  `json:"total_pages"`
-	HasNext    bool `json:"has_next"`
-	HasPrev    bool `json:"has_prev"`
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	Total      *int   `json:"total,omitempty"`
+	TotalPages *int   `json:"total_pages,omitempty"`
+	HasNext    bool   `json:"has_next"`
+	HasPrev    bool   `json:"has_prev"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type paginatedResponse struct {
-	Data []categoryResponse `json:"data"`
-	Meta paginationMeta     `json:"meta"`
+	Data any            `json:"data"`
+	Meta paginationMeta `json:"meta"`
+}
+
+type revisionResponse struct {
+	ID        string            `json:"id"`
+	Action    string            `json:"action"`
+	Before    *categoryResponse `json:"before"`
+	After     *categoryResponse `json:"after"`
+	RequestID string            `json:"request_id,omitempty"`
+	Actor     string            `json:"actor,omitempty"`
+	CreatedAt string            `json:"created_at"`
+}
+
+type apiKeyResponse struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  *string  `json:"expires_at"`
+	CreatedAt  string   `json:"created_at"`
+	LastUsedAt *string  `json:"last_used_at"`
+	RevokedAt  *string  `json:"revoked_at"`
+}
+
+type issuedAPIKeyResponse struct {
+	apiKeyResponse
+	Secret string `json:"secret"`
+}
+
+type batchResponse struct {
+	Atomic    bool                `json:"atomic"`
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+	Results   []batchItemResponse `json:"results"`
+}
+
+type batchItemResponse struct {
+	Index  int               `json:"index"`
+	Op     string            `json:"op"`
+	ID     string            `json:"id,omitempty"`
+	Status int               `json:"status"`
+	Data   *categoryResponse `json:"data,omitempty"`
+	Errors []string          `json:"errors,omitempty"`
+}
+
+type importResponse struct {
+	Mode      string                 `json:"mode"`
+	DryRun    bool                   `json:"dry_run"`
+	Created   int                    `json:"created"`
+	Updated   int                    `json:"updated"`
+	Deleted   int                    `json:"deleted"`
+	Unchanged int                    `json:"unchanged"`
+	Changes   []importChangeResponse `json:"changes"`
+}
+
+type importChangeResponse struct {
+	Row           int      `json:"row,omitempty"`
+	Action        string   `json:"action"`
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Slug          string   `json:"slug"`
+	ParentID      *string  `json:"parent_id"`
+	ChangedFields []string `json:"changed_fields,omitempty"`
 }
 
 type apiResponse struct {
@@ -48,7 +171,134 @@ func toCategoryResponse(c *domain.Category) categoryResponse {
 	return categoryResponse{
 		ID:        c.ID,
 		Name:      c.Name,
+		Slug:      c.Slug,
+		ParentID:  c.ParentID,
+		Version:   c.Version,
 		CreatedAt: c.CreatedAt.Format(time.RFC3339),
 		UpdatedAt: c.UpdatedAt.Format(time.RFC3339),
+		DeletedAt: formatTime(c.DeletedAt),
+	}
+}
+
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
+
+func toCategoryResponses(categories []*domain.Category) []categoryResponse {
+	data := make([]categoryResponse, 0, len(categories))
+	for _, c := range categories {
+		data = append(data, toCategoryResponse(c))
+	}
+	return data
+}
+
+func toCategoryNodeResponses(nodes []*domain.CategoryNode) []categoryNodeResponse {
+	data := make([]categoryNodeResponse, 0, len(nodes))
+	for _, n := range nodes {
+		data = append(data, categoryNodeResponse{
+			categoryResponse: toCategoryResponse(n.Category),
+			Children:         toCategoryNodeResponses(n.Children),
+		})
+	}
+	return data
+}
+
+func toRevisionResponses(revisions []*domain.CategoryRevision) []revisionResponse {
+	data := make([]revisionResponse, 0, len(revisions))
+	for _, rev := range revisions {
+		data = append(data, revisionResponse{
+			ID:        rev.ID,
+			Action:    rev.Action,
+			Before:    toOptionalCategoryResponse(rev.Before),
+			After:     toOptionalCategoryResponse(rev.After),
+			RequestID: rev.RequestID,
+			Actor:     rev.Actor,
+			CreatedAt: rev.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return data
+}
+
+func toOptionalCategoryResponse(c *domain.Category) *categoryResponse {
+	if c == nil {
+		return nil
+	}
+	res := toCategoryResponse(c)
+	return &res
+}
+
+func toAPIKeyResponse(k *domain.APIKey) apiKeyResponse {
+	return apiKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes,
+		ExpiresAt:  formatTime(k.ExpiresAt),
+		CreatedAt:  k.CreatedAt.Format(time.RFC3339),
+		LastUsedAt: formatTime(k.LastUsedAt),
+		RevokedAt:  formatTime(k.RevokedAt),
+	}
+}
+
+func toAPIKeyResponses(keys []*domain.APIKey) []apiKeyResponse {
+	data := make([]apiKeyResponse, 0, len(keys))
+	for _, k := range keys {
+		data = append(data, toAPIKeyResponse(k))
+	}
+	return data
+}
+
+func toPaginationMeta[T any](p domain.PaginationParams, result *domain.PaginatedResult[T]) paginationMeta {
+	if p.Cursor != nil {
+		meta := paginationMeta{
+			Limit:      result.Limit,
+			HasNext:    result.NextCursor != "",
+			HasPrev:    *p.Cursor != "",
+			NextCursor: result.NextCursor,
+		}
+		if p.WithTotal {
+			meta.Total = &result.Total
+			meta.TotalPages = &result.TotalPages
+		}
+		return meta
+	}
+
+	return paginationMeta{
+		Page:       result.Page,
+		Limit:      result.Limit,
+		Total:      &result.Total,
+		TotalPages: &result.TotalPages,
+		HasNext:    result.Page < result.TotalPages,
+		HasPrev:    result.Page > 1,
+		NextCursor: result.NextCursor,
+	}
+}
+
+func toImportResponse(res *domain.ImportResult, mode string, dryRun bool) importResponse {
+	changes := make([]importChangeResponse, len(res.Changes))
+	for i, c := range res.Changes {
+		changes[i] = importChangeResponse{
+			Row:           c.Row,
+			Action:        c.Action,
+			ID:            c.Category.ID,
+			Name:          c.Category.Name,
+			Slug:          c.Category.Slug,
+			ParentID:      c.Category.ParentID,
+			ChangedFields: c.ChangedFields,
+		}
+	}
+
+	return importResponse{
+		Mode:      mode,
+		DryRun:    dryRun,
+		Created:   res.Created,
+		Updated:   res.Updated,
+		Deleted:   res.Deleted,
+		Unchanged: res.Unchanged,
+		Changes:   changes,
 	}
 }
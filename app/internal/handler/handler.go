@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/alfattd/category-service/internal/domain"
 	"github.com/alfattd/category-service/internal/validator"
@@ -24,18 +26,52 @@ func writeJSON(w http.ResponseWriter, status int, body any) {
 }
 
 func writeError(w http.ResponseWriter, err error) {
+	status, messages := errorStatus(err)
+	writeJSON(w, status, apiErrorResponse{Errors: messages})
+}
+
+// errorStatus maps err to the status code and messages it is answered with.
+func errorStatus(err error) (int, []string) {
 	var valErrs *validator.ErrorsValidator
 	if errors.As(err, &valErrs) {
-		writeJSON(w, http.StatusBadRequest, apiErrorResponse{Errors: valErrs.Messages})
-		return
+		return http.StatusBadRequest, valErrs.Messages
 	}
 
 	switch {
 	case errors.Is(err, domain.ErrNotFound):
-		writeJSON(w, http.StatusNotFound, apiErrorResponse{Errors: []string{err.Error()}})
-	case errors.Is(err, domain.ErrDuplicate):
-		writeJSON(w, http.StatusConflict, apiErrorResponse{Errors: []string{err.Error()}})
+		return http.StatusNotFound, []string{err.Error()}
+	case errors.Is(err, domain.ErrDuplicate), errors.Is(err, domain.ErrHasChildren), errors.Is(err, domain.ErrNotDeleted),
+		errors.Is(err, domain.ErrPatchTestFailed):
+		return http.StatusConflict, []string{err.Error()}
+	case errors.Is(err, domain.ErrInvalidParent), errors.Is(err, domain.ErrMaxDepthExceeded):
+		return http.StatusUnprocessableEntity, []string{err.Error()}
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed, []string{err.Error()}
+	case errors.Is(err, domain.ErrBatchAborted):
+		return http.StatusFailedDependency, []string{err.Error()}
+	case errors.Is(err, domain.ErrTxUnsupported):
+		return http.StatusNotImplemented, []string{err.Error()}
 	default:
-		writeJSON(w, http.StatusInternalServerError, apiErrorResponse{Errors: []string{"internal server error"}})
+		return http.StatusInternalServerError, []string{"internal server error"}
+	}
+}
+
+func setETag(w http.ResponseWriter, c *domain.Category) {
+	w.Header().Set("ETag", `"`+strconv.FormatInt(c.Version, 10)+`"`)
+}
+
+// ifMatchVersion reads the category version from If-Match. A missing header or "*" yields zero,
+// which skips the version check; anything that is not one of our ETags can never match.
+func ifMatchVersion(r *http.Request) (int64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
 	}
+
+	version, err := strconv.ParseInt(strings.Trim(header, `"`), 10, 64)
+	if err != nil || version < 1 || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		return 0, domain.ErrPreconditionFailed
+	}
+
+	return version, nil
 }
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/validator"
+)
+
+const (
+	mediaTypeMergePatch = "application/merge-patch+json"
+	mediaTypeJSONPatch  = "application/json-patch+json"
+)
+
+// decodeMergePatch turns an RFC 7396 merge patch into operations: null removes a field,
+// anything else replaces it.
+func decodeMergePatch(body io.Reader) ([]domain.PatchOp, *validator.ErrorsValidator) {
+	var doc map[string]json.RawMessage
+	if err := json.NewDecoder(body).Decode(&doc); err != nil || doc == nil {
+		return nil, invalidBody()
+	}
+
+	errs := &validator.ErrorsValidator{}
+	ops := make([]domain.PatchOp, 0, len(doc))
+
+	for _, field := range slices.Sorted(maps.Keys(doc)) {
+		value, err := decodePatchValue(doc[field])
+		if err != nil {
+			errs.Add(fmt.Sprintf("%s %s", field, err))
+			continue
+		}
+
+		op := domain.PatchOp{Op: domain.PatchReplace, Path: field, Value: value}
+		if value == nil {
+			op.Op = domain.PatchRemove
+		}
+		ops = append(ops, op)
+	}
+
+	if errs.HasErrors() {
+		return nil, errs
+	}
+
+	return ops, nil
+}
+
+// decodeJSONPatch turns an RFC 6902 JSON patch into operations, resolving the JSON Pointers
+// to field names. Whether the fields can be patched is up to the service.
+func decodeJSONPatch(body io.Reader) ([]domain.PatchOp, *validator.ErrorsValidator) {
+	var req []jsonPatchOperation
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		return nil, invalidBody()
+	}
+
+	errs := &validator.ErrorsValidator{}
+	ops := make([]domain.PatchOp, 0, len(req))
+
+	for i, r := range req {
+		op := domain.PatchOp{Op: r.Op}
+
+		var ok bool
+		if op.Path, ok = pointerField(r.Path); !ok {
+			errs.Add(fmt.Sprintf("operation %d: path must be a JSON Pointer", i))
+			continue
+		}
+
+		switch r.Op {
+		case domain.PatchMove, domain.PatchCopy:
+			if op.From, ok = pointerField(r.From); !ok {
+				errs.Add(fmt.Sprintf("operation %d: from must be a JSON Pointer", i))
+				continue
+			}
+		case domain.PatchAdd, domain.PatchReplace, domain.PatchTest:
+			if r.Value == nil {
+				errs.Add(fmt.Sprintf("operation %d: value is required", i))
+				continue
+			}
+
+			value, err := decodePatchValue(r.Value)
+			if err != nil {
+				errs.Add(fmt.Sprintf("operation %d: value %s", i, err))
+				continue
+			}
+			op.Value = value
+		}
+
+		ops = append(ops, op)
+	}
+
+	if errs.HasErrors() {
+		return nil, errs
+	}
+
+	return ops, nil
+}
+
+// pointerField resolves a JSON Pointer such as "/parent_id" to a top-level field name.
+// Pointers into nested values keep their slashes and are rejected as unknown fields.
+func pointerField(pointer string) (string, bool) {
+	field, ok := strings.CutPrefix(pointer, "/")
+	if !ok {
+		return "", false
+	}
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(field), true
+}
+
+func invalidBody() *validator.ErrorsValidator {
+	return &validator.ErrorsValidator{Messages: []string{"invalid request body"}}
+}
+
+// decodePatchValue accepts the values category fields can take: a string, or null as nil.
+func decodePatchValue(raw json.RawMessage) (*string, error) {
+	if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return nil, nil
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("must be a string or null")
+	}
+
+	return &value, nil
+}
@@ -3,8 +3,10 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/validator"
 )
 
 func (h *CategoryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
@@ -16,15 +18,37 @@ func (h *CategoryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, category)
 	writeJSON(w, http.StatusOK, apiResponse{
 		Data: toCategoryResponse(category),
 	})
 }
 
+// GetBySlug also answers for slugs the category had before, flagging that the client should
+// move on to the current one.
+func (h *CategoryHandler) GetBySlug(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+
+	category, err := h.service.GetBySlug(r.Context(), slug)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, category)
+	writeJSON(w, http.StatusOK, apiResponse{
+		Data: slugLookupResponse{
+			categoryResponse: toCategoryResponse(category),
+			Redirect:         category.Slug != slug,
+		},
+	})
+}
+
 func (h *CategoryHandler) List(w http.ResponseWriter, r *http.Request) {
-	p := domain.PaginationParams{
-		Page:  parseIntQuery(r, "page", 1),
-		Limit: parseIntQuery(r, "limit", 10),
+	p, err := parseListParams(r)
+	if err != nil {
+		writeError(w, err)
+		return
 	}
 
 	result, err := h.service.List(r.Context(), p)
@@ -33,26 +57,121 @@ func (h *CategoryHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data := make([]categoryResponse, 0, len(result.Data))
-	for _, c := range result.Data {
-		data = append(data, toCategoryResponse(c))
+	writeJSON(w, http.StatusOK, apiResponse{
+		Data: paginatedResponse{
+			Data: toCategoryResponses(result.Data),
+			Meta: toPaginationMeta(p, result),
+		},
+	})
+}
+
+func (h *CategoryHandler) Children(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	children, err := h.service.Children(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, apiResponse{
+		Data: toCategoryResponses(children),
+	})
+}
+
+func (h *CategoryHandler) Ancestors(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	ancestors, err := h.service.Ancestors(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, apiResponse{
+		Data: toCategoryResponses(ancestors),
+	})
+}
+
+func (h *CategoryHandler) History(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	p := domain.PaginationParams{
+		Page:  parseIntQuery(r, "page", 1),
+		Limit: parseIntQuery(r, "limit", 10),
+	}
+
+	result, err := h.service.History(r.Context(), id, p)
+	if err != nil {
+		writeError(w, err)
+		return
 	}
 
 	writeJSON(w, http.StatusOK, apiResponse{
 		Data: paginatedResponse{
-			Data: data,
-			Meta: paginationMeta{
-				Page:       result.Page,
-				Limit:      result.Limit,
-				Total:      result.Total,
-				TotalPages: result.TotalPages,
-				HasNext:    result.Page < result.TotalPages,
-				HasPrev:    result.Page > 1,
-			},
+			Data: toRevisionResponses(result.Data),
+			Meta: toPaginationMeta(p, result),
 		},
 	})
 }
 
+func (h *CategoryHandler) Tree(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.service.Tree(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, apiResponse{
+		Data: toCategoryNodeResponses(tree),
+	})
+}
+
+func parseListParams(r *http.Request) (domain.PaginationParams, error) {
+	query := r.URL.Query()
+
+	p := domain.PaginationParams{
+		Page:  parseIntQuery(r, "page", 1),
+		Limit: parseIntQuery(r, "limit", 10),
+		Sort:  domain.CategorySort(query.Get("sort")),
+		Filter: domain.CategoryFilter{
+			Query:          query.Get("q"),
+			IncludeDeleted: query.Get("include_deleted") == "true",
+		},
+	}
+
+	// Any cursor parameter, even an empty one, selects keyset pagination.
+	if query.Has("cursor") {
+		cursor := query.Get("cursor")
+		p.Cursor = &cursor
+		p.WithTotal = query.Get("total") == "true"
+	}
+
+	errs := &validator.ErrorsValidator{}
+	p.Filter.CreatedAfter = parseTimeQuery(r, "created_after", errs)
+	p.Filter.UpdatedAfter = parseTimeQuery(r, "updated_after", errs)
+	if errs.HasErrors() {
+		return p, errs
+	}
+
+	return p, nil
+}
+
+func parseTimeQuery(r *http.Request, key string, errs *validator.ErrorsValidator) time.Time {
+	raw := r.URL.Query().Get(key)
+	if raw == "" {
+		return time.Time{}
+	}
+
+	val, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		errs.Add(key + " must be an RFC 3339 timestamp")
+		return time.Time{}
+	}
+
+	return val.UTC()
+}
+
 func parseIntQuery(r *http.Request, key string, fallback int) int {
 	raw := r.URL.Query().Get(key)
 	if raw == "" {
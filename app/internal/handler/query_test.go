@@ -19,7 +19,7 @@ import (
 
 func TestHandlerGetByID_Success(t *testing.T) {
 	svc := new(mocks.MockCategoryService)
-	cat := &domain.Category{ID: "abc-123", Name: "Electronics", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	cat := &domain.Category{ID: "abc-123", Name: "Electronics", Version: 2, CreatedAt: time.Now(), UpdatedAt: time.Now()}
 
 	svc.On("GetByID", mock.Anything, "abc-123").Return(cat, nil)
 
@@ -33,6 +33,7 @@ func TestHandlerGetByID_Success(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
 
 	var resp map[string]any
 	json.NewDecoder(w.Body).Decode(&resp)
@@ -68,6 +69,59 @@ func TestHandlerGetByID_NotFound_Returns404(t *testing.T) {
 	svc.AssertExpectations(t)
 }
 
+func serveBySlug(svc *mocks.MockCategoryService, slug string) (*httptest.ResponseRecorder, map[string]any) {
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodGet, "/categories/by-slug/"+slug, nil)
+	r.SetPathValue("slug", slug)
+	w := httptest.NewRecorder()
+
+	h.GetBySlug(w, r)
+
+	var resp map[string]any
+	json.NewDecoder(w.Body).Decode(&resp)
+	data, _ := resp["data"].(map[string]any)
+	return w, data
+}
+
+func TestHandlerGetBySlug_CurrentSlug_DoesNotRedirect(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	cat := &domain.Category{ID: "abc-123", Name: "Home Garden", Slug: "home-garden", Version: 3, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	svc.On("GetBySlug", mock.Anything, "home-garden").Return(cat, nil)
+
+	w, data := serveBySlug(svc, "home-garden")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	assert.Equal(t, "abc-123", data["id"])
+	assert.Equal(t, "home-garden", data["slug"])
+	assert.Equal(t, false, data["redirect"])
+}
+
+func TestHandlerGetBySlug_FormerSlug_Redirects(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	cat := &domain.Category{ID: "abc-123", Name: "Outdoor", Slug: "outdoor", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	svc.On("GetBySlug", mock.Anything, "home-garden").Return(cat, nil)
+
+	w, data := serveBySlug(svc, "home-garden")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "outdoor", data["slug"])
+	assert.Equal(t, true, data["redirect"])
+}
+
+func TestHandlerGetBySlug_NotFound_Returns404(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+
+	svc.On("GetBySlug", mock.Anything, "missing").Return(nil, domain.ErrNotFound)
+
+	w, _ := serveBySlug(svc, "missing")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
 func TestHandlerGetByID_InternalError_Returns500(t *testing.T) {
 	svc := new(mocks.MockCategoryService)
 
@@ -256,6 +310,170 @@ func TestHandlerList_DefaultParams_WhenQueryInvalid(t *testing.T) {
 	svc.AssertExpectations(t)
 }
 
+func TestHandlerList_Cursor_ReturnsNextCursorWithoutTotal(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	cursor := "abc"
+	p := domain.PaginationParams{Page: 1, Limit: 2, Cursor: &cursor}
+	result := &domain.PaginatedResult[*domain.Category]{
+		Data: []*domain.Category{
+			{ID: "1", Name: "Electronics", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+			{ID: "2", Name: "Books", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		},
+		Limit: 2, NextCursor: "def",
+	}
+
+	svc.On("List", mock.Anything, p).Return(result, nil)
+
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodGet, "/categories?cursor=abc&limit=2", nil)
+	w := httptest.NewRecorder()
+
+	h.List(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp map[string]any
+	json.NewDecoder(w.Body).Decode(&resp)
+
+	meta := resp["data"].(map[string]any)["meta"].(map[string]any)
+
+	assert.Equal(t, "def", meta["next_cursor"])
+	assert.Equal(t, true, meta["has_next"])
+	assert.Equal(t, true, meta["has_prev"])
+	assert.NotContains(t, meta, "total")
+	assert.NotContains(t, meta, "page")
+
+	svc.AssertExpectations(t)
+}
+
+func TestHandlerList_Cursor_WithTotal(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	cursor := ""
+	p := domain.PaginationParams{Page: 1, Limit: 10, Cursor: &cursor, WithTotal: true}
+	result := &domain.PaginatedResult[*domain.Category]{
+		Data:  []*domain.Category{},
+		Limit: 10, Total: 0, TotalPages: 1,
+	}
+
+	svc.On("List", mock.Anything, p).Return(result, nil)
+
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodGet, "/categories?cursor=&total=true", nil)
+	w := httptest.NewRecorder()
+
+	h.List(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp map[string]any
+	json.NewDecoder(w.Body).Decode(&resp)
+
+	meta := resp["data"].(map[string]any)["meta"].(map[string]any)
+
+	assert.Equal(t, float64(0), meta["total"])
+	assert.Equal(t, false, meta["has_next"])
+	assert.Equal(t, false, meta["has_prev"])
+
+	svc.AssertExpectations(t)
+}
+
+func TestHandlerList_SortAndFilters_PassedToService(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	createdAfter := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := domain.PaginationParams{
+		Page: 1, Limit: 10, Sort: domain.SortNameDesc,
+		Filter: domain.CategoryFilter{Query: "phone", CreatedAfter: createdAfter},
+	}
+	result := &domain.PaginatedResult[*domain.Category]{
+		Data: []*domain.Category{}, Page: 1, Limit: 10, TotalPages: 1,
+	}
+
+	svc.On("List", mock.Anything, p).Return(result, nil)
+
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodGet, "/categories?sort=-name&q=phone&created_after=2026-01-01T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+
+	h.List(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestHandlerList_TimeFilterWithOffset_PassedAsUTC(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	p := domain.PaginationParams{
+		Page: 1, Limit: 10,
+		Filter: domain.CategoryFilter{
+			CreatedAfter: time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC),
+			UpdatedAfter: time.Date(2023, 12, 31, 17, 0, 0, 0, time.UTC),
+		},
+	}
+	result := &domain.PaginatedResult[*domain.Category]{
+		Data: []*domain.Category{}, Page: 1, Limit: 10, TotalPages: 1,
+	}
+
+	svc.On("List", mock.Anything, p).Return(result, nil)
+
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodGet, "/categories?created_after=2024-01-01T10:00:00%2B07:00&updated_after=2024-01-01T00:00:00%2B07:00", nil)
+	w := httptest.NewRecorder()
+
+	h.List(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestHandlerList_IncludeDeleted_PassedToService(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	deletedAt := time.Now()
+	p := domain.PaginationParams{Page: 1, Limit: 10, Filter: domain.CategoryFilter{IncludeDeleted: true}}
+	result := &domain.PaginatedResult[*domain.Category]{
+		Data: []*domain.Category{
+			{ID: "1", Name: "Electronics", CreatedAt: time.Now(), UpdatedAt: time.Now(), DeletedAt: &deletedAt},
+		},
+		Page: 1, Limit: 10, Total: 1, TotalPages: 1,
+	}
+
+	svc.On("List", mock.Anything, p).Return(result, nil)
+
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodGet, "/categories?include_deleted=true", nil)
+	w := httptest.NewRecorder()
+
+	h.List(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp map[string]any
+	json.NewDecoder(w.Body).Decode(&resp)
+
+	items := resp["data"].(map[string]any)["data"].([]any)
+	require.Len(t, items, 1)
+	assert.Contains(t, items[0], "deleted_at")
+
+	svc.AssertExpectations(t)
+}
+
+func TestHandlerList_InvalidTimeFilter_ReturnsBadRequest(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodGet, "/categories?updated_after=yesterday", nil)
+	w := httptest.NewRecorder()
+
+	h.List(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.AssertNotCalled(t, "List")
+}
+
 func TestHandlerList_InternalError_Returns500(t *testing.T) {
 	svc := new(mocks.MockCategoryService)
 	p := domain.PaginationParams{Page: 1, Limit: 10}
@@ -272,3 +490,186 @@ func TestHandlerList_InternalError_Returns500(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	svc.AssertExpectations(t)
 }
+
+// ─── Hierarchy ────────────────────────────────────────────────────────────────
+
+func TestHandlerChildren_Success(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	parentID := "abc-123"
+	children := []*domain.Category{
+		{ID: "child-1", Name: "Phones", ParentID: &parentID, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	}
+
+	svc.On("Children", mock.Anything, parentID).Return(children, nil)
+
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodGet, "/categories/abc-123/children", nil)
+	r.SetPathValue("id", parentID)
+	w := httptest.NewRecorder()
+
+	h.Children(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp map[string]any
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	data := resp["data"].([]any)
+	require.Len(t, data, 1)
+	assert.Equal(t, parentID, data[0].(map[string]any)["parent_id"])
+
+	svc.AssertExpectations(t)
+}
+
+func TestHandlerChildren_NotFound_Returns404(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+
+	svc.On("Children", mock.Anything, "not-exist").Return(nil, domain.ErrNotFound)
+
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodGet, "/categories/not-exist/children", nil)
+	r.SetPathValue("id", "not-exist")
+	w := httptest.NewRecorder()
+
+	h.Children(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	svc.AssertExpectations(t)
+}
+
+func TestHandlerAncestors_Success(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	ancestors := []*domain.Category{
+		{ID: "root", Name: "Electronics", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	}
+
+	svc.On("Ancestors", mock.Anything, "abc-123").Return(ancestors, nil)
+
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodGet, "/categories/abc-123/ancestors", nil)
+	r.SetPathValue("id", "abc-123")
+	w := httptest.NewRecorder()
+
+	h.Ancestors(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp map[string]any
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	data := resp["data"].([]any)
+	require.Len(t, data, 1)
+	assert.Nil(t, data[0].(map[string]any)["parent_id"])
+
+	svc.AssertExpectations(t)
+}
+
+func TestHandlerHistory_Success(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	before := &domain.Category{ID: "abc-123", Name: "Old", Version: 1, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	after := &domain.Category{ID: "abc-123", Name: "New", Version: 2, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	p := domain.PaginationParams{Page: 2, Limit: 1}
+
+	svc.On("History", mock.Anything, "abc-123", p).Return(&domain.PaginatedResult[*domain.CategoryRevision]{
+		Data: []*domain.CategoryRevision{{
+			ID:         "rev-1",
+			CategoryID: "abc-123",
+			Action:     domain.RevisionUpdated,
+			Before:     before,
+			After:      after,
+			RequestID:  "req-1",
+			Actor:      "alice",
+			CreatedAt:  time.Now(),
+		}},
+		Page:       2,
+		Limit:      1,
+		Total:      2,
+		TotalPages: 2,
+	}, nil)
+
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodGet, "/categories/abc-123/history?page=2&limit=1", nil)
+	r.SetPathValue("id", "abc-123")
+	w := httptest.NewRecorder()
+
+	h.History(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp map[string]any
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	body := resp["data"].(map[string]any)
+	data := body["data"].([]any)
+	require.Len(t, data, 1)
+
+	rev := data[0].(map[string]any)
+	assert.Equal(t, "updated", rev["action"])
+	assert.Equal(t, "Old", rev["before"].(map[string]any)["name"])
+	assert.Equal(t, "New", rev["after"].(map[string]any)["name"])
+	assert.Equal(t, "req-1", rev["request_id"])
+	assert.Equal(t, "alice", rev["actor"])
+
+	meta := body["meta"].(map[string]any)
+	assert.Equal(t, true, meta["has_prev"])
+	assert.Equal(t, false, meta["has_next"])
+
+	svc.AssertExpectations(t)
+}
+
+func TestHandlerHistory_NotFound_Returns404(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+
+	svc.On("History", mock.Anything, "not-exist", mock.Anything).Return(nil, domain.ErrNotFound)
+
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodGet, "/categories/not-exist/history", nil)
+	r.SetPathValue("id", "not-exist")
+	w := httptest.NewRecorder()
+
+	h.History(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandlerTree_Success(t *testing.T) {
+	svc := new(mocks.MockCategoryService)
+	rootID := "root"
+	tree := []*domain.CategoryNode{
+		{
+			Category: &domain.Category{ID: rootID, Name: "Electronics", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+			Children: []*domain.CategoryNode{
+				{
+					Category: &domain.Category{ID: "child", Name: "Phones", ParentID: &rootID, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+					Children: []*domain.CategoryNode{},
+				},
+			},
+		},
+	}
+
+	svc.On("Tree", mock.Anything).Return(tree, nil)
+
+	h := handler.NewCategoryHandler(svc)
+
+	r := httptest.NewRequest(http.MethodGet, "/categories/tree", nil)
+	w := httptest.NewRecorder()
+
+	h.Tree(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp map[string]any
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	roots := resp["data"].([]any)
+	require.Len(t, roots, 1)
+	root := roots[0].(map[string]any)
+	assert.Equal(t, rootID, root["id"])
+
+	children := root["children"].([]any)
+	require.Len(t, children, 1)
+	assert.Equal(t, "child", children[0].(map[string]any)["id"])
+
+	svc.AssertExpectations(t)
+}
@@ -0,0 +1,67 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) Add(ctx context.Context, events ...domain.CategoryEvent) error {
+	args := m.Called(ctx, events)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxMessage, error) {
+	args := m.Called(ctx, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOutboxRepository) MarkSent(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) MarkFailed(ctx context.Context, id string, cause error, retryAt time.Time) error {
+	args := m.Called(ctx, id, cause, retryAt)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) Release(ctx context.Context, ids ...string) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) DeleteSent(ctx context.Context, before time.Time, limit int) (int, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Int(0), args.Error(1)
+}
+
+// MockTransactor runs the callback directly unless the expectation returns an error.
+type MockTransactor struct {
+	mock.Mock
+}
+
+func (m *MockTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	args := m.Called(ctx)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(ctx)
+}
+
+func (m *MockTransactor) WithinSnapshot(ctx context.Context, fn func(ctx context.Context) error) error {
+	args := m.Called(ctx)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(ctx)
+}
@@ -16,12 +16,22 @@ func (m *MockCategoryEventPublisher) PublishCategoryCreated(ctx context.Context,
 	return args.Error(0)
 }
 
-func (m *MockCategoryEventPublisher) PublishCategoryUpdated(ctx context.Context, c *domain.Category) error {
+func (m *MockCategoryEventPublisher) PublishCategoryUpdated(ctx context.Context, before, after *domain.Category, changedFields []string) error {
+	args := m.Called(ctx, before, after, changedFields)
+	return args.Error(0)
+}
+
+func (m *MockCategoryEventPublisher) PublishCategoryDeleted(ctx context.Context, c *domain.Category) error {
 	args := m.Called(ctx, c)
 	return args.Error(0)
 }
 
-func (m *MockCategoryEventPublisher) PublishCategoryDeleted(ctx context.Context, id string) error {
-	args := m.Called(ctx, id)
+func (m *MockCategoryEventPublisher) PublishCategoryMoved(ctx context.Context, c *domain.Category, oldParentID *string) error {
+	args := m.Called(ctx, c, oldParentID)
+	return args.Error(0)
+}
+
+func (m *MockCategoryEventPublisher) PublishCategoryRestored(ctx context.Context, c *domain.Category) error {
+	args := m.Called(ctx, c)
 	return args.Error(0)
 }
@@ -21,9 +21,28 @@ func (m *MockCategoryRepository) Update(ctx context.Context, c *domain.Category)
 	return args.Error(0)
 }
 
-func (m *MockCategoryRepository) Delete(ctx context.Context, id string) error {
+func (m *MockCategoryRepository) Delete(ctx context.Context, id string, version int64) (*domain.Category, error) {
+	args := m.Called(ctx, id, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) Purge(ctx context.Context, id string, version int64) (*domain.Category, error) {
+	args := m.Called(ctx, id, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) Restore(ctx context.Context, id string) (*domain.Category, error) {
 	args := m.Called(ctx, id)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Category), args.Error(1)
 }
 
 func (m *MockCategoryRepository) GetByID(ctx context.Context, id string) (*domain.Category, error) {
@@ -34,6 +53,27 @@ func (m *MockCategoryRepository) GetByID(ctx context.Context, id string) (*domai
 	return args.Get(0).(*domain.Category), args.Error(1)
 }
 
+func (m *MockCategoryRepository) GetBySlug(ctx context.Context, slug string) (*domain.Category, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) ListSlugs(ctx context.Context, base string) (map[string]string, error) {
+	args := m.Called(ctx, base)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
+func (m *MockCategoryRepository) AddFormerSlug(ctx context.Context, id, slug string) error {
+	args := m.Called(ctx, id, slug)
+	return args.Error(0)
+}
+
 func (m *MockCategoryRepository) List(ctx context.Context, p domain.PaginationParams) ([]*domain.Category, error) {
 	args := m.Called(ctx, p)
 	if args.Get(0) == nil {
@@ -42,7 +82,62 @@ func (m *MockCategoryRepository) List(ctx context.Context, p domain.PaginationPa
 	return args.Get(0).([]*domain.Category), args.Error(1)
 }
 
-func (m *MockCategoryRepository) Count(ctx context.Context) (int, error) {
+func (m *MockCategoryRepository) ListAfter(ctx context.Context, p domain.PaginationParams, after *domain.CategoryCursor) ([]*domain.Category, error) {
+	args := m.Called(ctx, p, after)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) Count(ctx context.Context, f domain.CategoryFilter) (int, error) {
+	args := m.Called(ctx, f)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockCategoryRepository) ListChildren(ctx context.Context, parentID string) ([]*domain.Category, error) {
+	args := m.Called(ctx, parentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) ListAncestors(ctx context.Context, id string) ([]*domain.Category, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) ListAll(ctx context.Context) ([]*domain.Category, error) {
 	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) SubtreeHeight(ctx context.Context, id string) (int, error) {
+	args := m.Called(ctx, id)
 	return args.Int(0), args.Error(1)
 }
+
+func (m *MockCategoryRepository) LockSlug(ctx context.Context, root string) error {
+	args := m.Called(ctx, root)
+	return args.Error(0)
+}
+
+func (m *MockCategoryRepository) LockHierarchy(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockCategoryRepository) Move(ctx context.Context, id string, parentID *string, maxDepth int) (*domain.CategoryMove, error) {
+	args := m.Called(ctx, id, parentID, maxDepth)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CategoryMove), args.Error(1)
+}
@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRevisionRepository struct {
+	mock.Mock
+}
+
+func (m *MockRevisionRepository) Add(ctx context.Context, r *domain.CategoryRevision) error {
+	args := m.Called(ctx, r)
+	return args.Error(0)
+}
+
+func (m *MockRevisionRepository) ListByCategory(ctx context.Context, categoryID string, p domain.PaginationParams) ([]*domain.CategoryRevision, error) {
+	args := m.Called(ctx, categoryID, p)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.CategoryRevision), args.Error(1)
+}
+
+func (m *MockRevisionRepository) CountByCategory(ctx context.Context, categoryID string) (int, error) {
+	args := m.Called(ctx, categoryID)
+	return args.Int(0), args.Error(1)
+}
@@ -11,27 +11,67 @@ type MockCategoryService struct {
 	mock.Mock
 }
 
-func (m *MockCategoryService) Create(ctx context.Context, name string) (*domain.Category, error) {
-	args := m.Called(ctx, name)
+func (m *MockCategoryService) Create(ctx context.Context, p domain.CreateCategoryParams) (*domain.Category, error) {
+	args := m.Called(ctx, p)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.Category), args.Error(1)
 }
 
-func (m *MockCategoryService) Update(ctx context.Context, id, name string) (*domain.Category, error) {
-	args := m.Called(ctx, id, name)
+func (m *MockCategoryService) Update(ctx context.Context, id string, p domain.UpdateCategoryParams) (*domain.Category, error) {
+	args := m.Called(ctx, id, p)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.Category), args.Error(1)
 }
 
-func (m *MockCategoryService) Delete(ctx context.Context, id string) error {
-	args := m.Called(ctx, id)
+func (m *MockCategoryService) Patch(ctx context.Context, id string, p domain.PatchCategoryParams) (*domain.Category, error) {
+	args := m.Called(ctx, id, p)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Category), args.Error(1)
+}
+
+func (m *MockCategoryService) Batch(ctx context.Context, p domain.BatchParams) ([]domain.BatchResult, error) {
+	args := m.Called(ctx, p)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.BatchResult), args.Error(1)
+}
+
+func (m *MockCategoryService) Import(ctx context.Context, p domain.ImportParams) (*domain.ImportResult, error) {
+	args := m.Called(ctx, p)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ImportResult), args.Error(1)
+}
+
+func (m *MockCategoryService) Delete(ctx context.Context, id string, p domain.DeleteCategoryParams) error {
+	args := m.Called(ctx, id, p)
 	return args.Error(0)
 }
 
+func (m *MockCategoryService) Restore(ctx context.Context, id string) (*domain.Category, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Category), args.Error(1)
+}
+
+func (m *MockCategoryService) Move(ctx context.Context, id string, parentID *string) (*domain.Category, error) {
+	args := m.Called(ctx, id, parentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Category), args.Error(1)
+}
+
 func (m *MockCategoryService) GetByID(ctx context.Context, id string) (*domain.Category, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -40,6 +80,14 @@ func (m *MockCategoryService) GetByID(ctx context.Context, id string) (*domain.C
 	return args.Get(0).(*domain.Category), args.Error(1)
 }
 
+func (m *MockCategoryService) GetBySlug(ctx context.Context, slug string) (*domain.Category, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Category), args.Error(1)
+}
+
 func (m *MockCategoryService) List(ctx context.Context, p domain.PaginationParams) (*domain.PaginatedResult[*domain.Category], error) {
 	args := m.Called(ctx, p)
 	if args.Get(0) == nil {
@@ -47,3 +95,48 @@ func (m *MockCategoryService) List(ctx context.Context, p domain.PaginationParam
 	}
 	return args.Get(0).(*domain.PaginatedResult[*domain.Category]), args.Error(1)
 }
+
+func (m *MockCategoryService) Children(ctx context.Context, id string) ([]*domain.Category, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Category), args.Error(1)
+}
+
+func (m *MockCategoryService) Ancestors(ctx context.Context, id string) ([]*domain.Category, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Category), args.Error(1)
+}
+
+func (m *MockCategoryService) Tree(ctx context.Context) ([]*domain.CategoryNode, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.CategoryNode), args.Error(1)
+}
+
+func (m *MockCategoryService) History(ctx context.Context, id string, p domain.PaginationParams) (*domain.PaginatedResult[*domain.CategoryRevision], error) {
+	args := m.Called(ctx, id, p)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PaginatedResult[*domain.CategoryRevision]), args.Error(1)
+}
+
+// Export hands every category the expectation returns to fn.
+func (m *MockCategoryService) Export(ctx context.Context, fn func(*domain.Category) error) error {
+	args := m.Called(ctx)
+	if categories, ok := args.Get(0).([]*domain.Category); ok {
+		for _, c := range categories {
+			if err := fn(c); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
@@ -0,0 +1,200 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/pkg/eventmeta"
+	"github.com/alfattd/category-service/internal/pkg/requestid"
+	"github.com/alfattd/category-service/internal/pkg/tracing"
+)
+
+const (
+	defaultInterval  = time.Second
+	defaultBatchSize = 100
+	// defaultLease has to cover publishing a whole batch; a relay that takes longer may see
+	// another one publish the rest of its batch again.
+	defaultLease     = 5 * time.Minute
+	cleanupInterval  = time.Hour
+	cleanupBatchSize = 1000
+	recordTimeout    = 5 * time.Second
+	baseRetryDelay   = time.Second
+	maxRetryDelay    = 5 * time.Minute
+)
+
+// Relay drains the outbox to the event publisher. Messages that fail are
+// retried with exponential backoff until the broker accepts them. Sent
+// messages are deleted once they are older than retention.
+type Relay struct {
+	repo        domain.OutboxRepository
+	publisher   domain.CategoryEventPublisher
+	log         *slog.Logger
+	interval    time.Duration
+	batchSize   int
+	lease       time.Duration
+	retention   time.Duration
+	lastCleanup time.Time
+}
+
+func NewRelay(
+	repo domain.OutboxRepository,
+	publisher domain.CategoryEventPublisher,
+	retention time.Duration,
+	log *slog.Logger,
+) *Relay {
+	return &Relay{
+		repo:      repo,
+		publisher: publisher,
+		log:       log,
+		interval:  defaultInterval,
+		batchSize: defaultBatchSize,
+		lease:     defaultLease,
+		retention: retention,
+	}
+}
+
+// Run polls the outbox until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Drain(ctx); err != nil && ctx.Err() == nil {
+			r.log.Error("failed to relay outbox", "error", err)
+		}
+
+		if time.Since(r.lastCleanup) >= cleanupInterval {
+			r.lastCleanup = time.Now()
+			if err := r.Cleanup(ctx); err != nil && ctx.Err() == nil {
+				r.log.Error("failed to clean up outbox", "error", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Drain relays pending messages batch by batch until the outbox has nothing due
+// or a message could not be published.
+func (r *Relay) Drain(ctx context.Context) error {
+	for ctx.Err() == nil {
+		more, err := r.relayBatch(ctx)
+		if err != nil || !more {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// Cleanup deletes the messages that were sent longer than retention ago.
+func (r *Relay) Cleanup(ctx context.Context) error {
+	before := time.Now().Add(-r.retention)
+
+	for ctx.Err() == nil {
+		n, err := r.repo.DeleteSent(ctx, before, cleanupBatchSize)
+		if err != nil {
+			return err
+		}
+		if n < cleanupBatchSize {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// relayBatch claims a batch and publishes it in order, outside any transaction. It stops at the
+// first message that does not go out and hands the rest back, so an outage costs one failed
+// publish per poll and a category's later events do not overtake one waiting for its retry.
+// It reports whether there may be more to relay.
+func (r *Relay) relayBatch(ctx context.Context) (bool, error) {
+	messages, err := r.repo.Claim(ctx, r.batchSize, r.lease)
+	if err != nil {
+		return false, err
+	}
+
+	for i, m := range messages {
+		if ctx.Err() != nil {
+			return false, errors.Join(ctx.Err(), r.release(ctx, messages[i:]))
+		}
+
+		sent, err := r.relay(ctx, m)
+		if err != nil || !sent {
+			return false, errors.Join(err, r.release(ctx, messages[i+1:]), ctx.Err())
+		}
+	}
+
+	return len(messages) == r.batchSize, nil
+}
+
+// relay publishes m and records the outcome, which it reports. The outcome is recorded even
+// once ctx is cancelled, so shutting down does not send a published message a second time.
+func (r *Relay) relay(ctx context.Context, m *domain.OutboxMessage) (bool, error) {
+	// The publish span continues the trace of the request that wrote the message.
+	pubCtx := tracing.WithTraceParent(requestid.WithContext(ctx, m.RequestID), m.TraceParent)
+
+	// Messages written before events had their own identity are known by their outbox row.
+	if m.Event.ID == "" {
+		m.Event.ID, m.Event.OccurredAt = m.ID, m.CreatedAt
+	}
+
+	pubCtx = eventmeta.WithContext(pubCtx, eventmeta.Meta{ID: m.Event.ID, Time: m.Event.OccurredAt})
+
+	pubErr := m.Event.Publish(pubCtx, r.publisher)
+
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+
+	if pubErr == nil {
+		return true, r.repo.MarkSent(recordCtx, m.ID)
+	}
+
+	// A publish cut short by shutdown is not the broker's fault; the message goes out on the next start.
+	if ctx.Err() != nil {
+		return false, r.repo.Release(recordCtx, m.ID)
+	}
+
+	retryAt := time.Now().Add(retryDelay(m.Attempts))
+
+	r.log.Error("failed to relay outbox message",
+		"error", pubErr,
+		"outbox_id", m.ID,
+		"type", m.Event.Type,
+		"attempts", m.Attempts+1,
+		"retry_at", retryAt,
+		"request_id", m.RequestID,
+	)
+
+	return false, r.repo.MarkFailed(recordCtx, m.ID, pubErr, retryAt)
+}
+
+func (r *Relay) release(ctx context.Context, messages []*domain.OutboxMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(messages))
+	for i, m := range messages {
+		ids[i] = m.ID
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+
+	return r.repo.Release(ctx, ids...)
+}
+
+func retryDelay(attempts int) time.Duration {
+	delay := time.Duration(math.Pow(2, float64(attempts))) * baseRetryDelay
+	if delay <= 0 || delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,244 @@
+package outbox_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/mocks"
+	"github.com/alfattd/category-service/internal/outbox"
+	"github.com/alfattd/category-service/internal/pkg/eventmeta"
+	"github.com/alfattd/category-service/internal/pkg/requestid"
+	"github.com/alfattd/category-service/internal/pkg/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+const testRetention = 24 * time.Hour
+
+func created(id string, cat *domain.Category) *domain.OutboxMessage {
+	return &domain.OutboxMessage{ID: id, Event: domain.CategoryEvent{Type: domain.EventCategoryCreated, Category: cat}}
+}
+
+// notCancelled matches the context outcomes are recorded with, which has to outlive a shutdown.
+func notCancelled() any {
+	return mock.MatchedBy(func(ctx context.Context) bool { return ctx.Err() == nil })
+}
+
+func TestRelayDrain_PublishesAndMarksSent(t *testing.T) {
+	cat := &domain.Category{ID: "abc-123", Name: "Electronics"}
+	messages := []*domain.OutboxMessage{
+		{ID: "msg-1", Event: domain.CategoryEvent{Type: domain.EventCategoryCreated, Category: cat}, RequestID: "req-1"},
+		{ID: "msg-2", Event: domain.CategoryEvent{Type: domain.EventCategoryDeleted, Category: cat}},
+	}
+
+	repo := new(mocks.MockOutboxRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Claim", mock.Anything, 100, 5*time.Minute).Return(messages, nil).Once()
+	pub.On("PublishCategoryCreated", mock.MatchedBy(func(ctx context.Context) bool {
+		return requestid.FromContext(ctx) == "req-1"
+	}), cat).Return(nil)
+	pub.On("PublishCategoryDeleted", mock.Anything, cat).Return(nil)
+	repo.On("MarkSent", mock.Anything, "msg-1").Return(nil)
+	repo.On("MarkSent", mock.Anything, "msg-2").Return(nil)
+
+	relay := outbox.NewRelay(repo, pub, testRetention, testLogger)
+	err := relay.Drain(context.Background())
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+	pub.AssertExpectations(t)
+}
+
+func TestRelayDrain_ContinuesTraceOfWritingRequest(t *testing.T) {
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	cat := &domain.Category{ID: "abc-123", Name: "Electronics"}
+	messages := []*domain.OutboxMessage{
+		{ID: "msg-1", Event: domain.CategoryEvent{Type: domain.EventCategoryCreated, Category: cat}, TraceParent: traceParent},
+	}
+
+	repo := new(mocks.MockOutboxRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Claim", mock.Anything, mock.Anything, mock.Anything).Return(messages, nil)
+	pub.On("PublishCategoryCreated", mock.MatchedBy(func(ctx context.Context) bool {
+		return tracing.TraceParent(ctx) == traceParent
+	}), cat).Return(nil)
+	repo.On("MarkSent", mock.Anything, "msg-1").Return(nil)
+
+	relay := outbox.NewRelay(repo, pub, testRetention, testLogger)
+	err := relay.Drain(context.Background())
+
+	require.NoError(t, err)
+	pub.AssertExpectations(t)
+}
+
+func TestRelayDrain_KeepsEventIdentity(t *testing.T) {
+	occurredAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	written := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	cat := &domain.Category{ID: "abc-123", Name: "Electronics"}
+	messages := []*domain.OutboxMessage{
+		{ID: "msg-1", Event: domain.CategoryEvent{ID: "event-1", OccurredAt: occurredAt, Type: domain.EventCategoryCreated, Category: cat}},
+		{ID: "msg-2", Event: domain.CategoryEvent{Type: domain.EventCategoryDeleted, Category: cat}, CreatedAt: written},
+	}
+
+	repo := new(mocks.MockOutboxRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Claim", mock.Anything, mock.Anything, mock.Anything).Return(messages, nil)
+	pub.On("PublishCategoryCreated", mock.MatchedBy(func(ctx context.Context) bool {
+		m, ok := eventmeta.FromContext(ctx)
+		return ok && m.ID == "event-1" && m.Time.Equal(occurredAt)
+	}), cat).Return(nil)
+	// Messages from before events had an identity of their own take the one of their outbox row.
+	pub.On("PublishCategoryDeleted", mock.MatchedBy(func(ctx context.Context) bool {
+		m, ok := eventmeta.FromContext(ctx)
+		return ok && m.ID == "msg-2" && m.Time.Equal(written)
+	}), cat).Return(nil)
+	repo.On("MarkSent", mock.Anything, mock.Anything).Return(nil)
+
+	relay := outbox.NewRelay(repo, pub, testRetention, testLogger)
+	err := relay.Drain(context.Background())
+
+	require.NoError(t, err)
+	pub.AssertExpectations(t)
+}
+
+func TestRelayDrain_PublishError_StopsAndReleasesTheRest(t *testing.T) {
+	first := &domain.Category{ID: "abc-123", Name: "Electronics"}
+	failing := &domain.Category{ID: "def-456", Name: "Books"}
+	later := &domain.Category{ID: "ghi-789", Name: "Toys"}
+
+	messages := []*domain.OutboxMessage{created("msg-1", first), created("msg-2", failing), created("msg-3", later), created("msg-4", later)}
+	messages[1].Attempts = 2
+
+	repo := new(mocks.MockOutboxRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Claim", mock.Anything, mock.Anything, mock.Anything).Return(messages, nil).Once()
+	pub.On("PublishCategoryCreated", mock.Anything, first).Return(nil)
+	pub.On("PublishCategoryCreated", mock.Anything, failing).Return(assert.AnError)
+	repo.On("MarkSent", mock.Anything, "msg-1").Return(nil)
+	repo.On("MarkFailed", notCancelled(), "msg-2", assert.AnError, mock.MatchedBy(func(retryAt time.Time) bool {
+		return retryAt.After(time.Now().Add(3 * time.Second))
+	})).Return(nil)
+	repo.On("Release", notCancelled(), []string{"msg-3", "msg-4"}).Return(nil)
+
+	relay := outbox.NewRelay(repo, pub, testRetention, testLogger)
+	err := relay.Drain(context.Background())
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+	pub.AssertNotCalled(t, "PublishCategoryCreated", mock.Anything, later)
+}
+
+func TestRelayDrain_ClaimError_ReturnsError(t *testing.T) {
+	repo := new(mocks.MockOutboxRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Claim", mock.Anything, mock.Anything, mock.Anything).Return(nil, assert.AnError)
+
+	relay := outbox.NewRelay(repo, pub, testRetention, testLogger)
+	err := relay.Drain(context.Background())
+
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestRelayDrain_Shutdown_RecordsWhatWasPublished(t *testing.T) {
+	cat := &domain.Category{ID: "abc-123", Name: "Electronics"}
+	messages := []*domain.OutboxMessage{created("msg-1", cat), created("msg-2", cat), created("msg-3", cat)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := new(mocks.MockOutboxRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Claim", mock.Anything, mock.Anything, mock.Anything).Return(messages, nil).Once()
+	// The broker confirms msg-1 just as the service is told to stop.
+	pub.On("PublishCategoryCreated", mock.Anything, cat).Run(func(mock.Arguments) { cancel() }).Return(nil).Once()
+	repo.On("MarkSent", notCancelled(), "msg-1").Return(nil)
+	repo.On("Release", notCancelled(), []string{"msg-2", "msg-3"}).Return(nil)
+
+	relay := outbox.NewRelay(repo, pub, testRetention, testLogger)
+	err := relay.Drain(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	repo.AssertExpectations(t)
+	pub.AssertNumberOfCalls(t, "PublishCategoryCreated", 1)
+}
+
+func TestRelayDrain_Shutdown_ReleasesInterruptedPublish(t *testing.T) {
+	cat := &domain.Category{ID: "abc-123", Name: "Electronics"}
+	messages := []*domain.OutboxMessage{created("msg-1", cat), created("msg-2", cat)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := new(mocks.MockOutboxRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Claim", mock.Anything, mock.Anything, mock.Anything).Return(messages, nil).Once()
+	pub.On("PublishCategoryCreated", mock.Anything, cat).Run(func(mock.Arguments) { cancel() }).Return(context.Canceled).Once()
+	repo.On("Release", notCancelled(), []string{"msg-1"}).Return(nil)
+	repo.On("Release", notCancelled(), []string{"msg-2"}).Return(nil)
+
+	relay := outbox.NewRelay(repo, pub, testRetention, testLogger)
+	err := relay.Drain(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "MarkFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRelayCleanup_DeletesSentMessagesPastRetention(t *testing.T) {
+	repo := new(mocks.MockOutboxRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	pastRetention := mock.MatchedBy(func(before time.Time) bool {
+		cutoff := time.Now().Add(-testRetention)
+		return !before.After(cutoff) && before.After(cutoff.Add(-time.Minute))
+	})
+	repo.On("DeleteSent", mock.Anything, pastRetention, 1000).Return(1000, nil).Once()
+	repo.On("DeleteSent", mock.Anything, pastRetention, 1000).Return(3, nil).Once()
+
+	relay := outbox.NewRelay(repo, pub, testRetention, testLogger)
+	err := relay.Cleanup(context.Background())
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestRelayRun_StopsWhenContextCancelled(t *testing.T) {
+	repo := new(mocks.MockOutboxRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Claim", mock.Anything, mock.Anything, mock.Anything).Return([]*domain.OutboxMessage{}, nil)
+	repo.On("DeleteSent", mock.Anything, mock.Anything, mock.Anything).Return(0, nil)
+
+	relay := outbox.NewRelay(repo, pub, testRetention, testLogger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("relay did not stop after context cancellation")
+	}
+}
@@ -0,0 +1,62 @@
+package actor
+
+import (
+	"context"
+	"slices"
+)
+
+type contextKey string
+
+const (
+	ActorKey      contextKey = "actor"
+	RolesKey      contextKey = "roles"
+	CredentialKey contextKey = "credential"
+)
+
+// Credentials an actor can be authenticated by. Their ids come from different places, so the
+// same id under two of them names two callers.
+const (
+	CredentialJWT    = "jwt"
+	CredentialAPIKey = "key"
+)
+
+// FromContext returns who is making the request, or an empty string for anonymous requests.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ActorKey).(string)
+	return id
+}
+
+func WithContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ActorKey, id)
+}
+
+// CredentialFromContext returns what the caller was authenticated by, or an empty string for
+// anonymous requests.
+func CredentialFromContext(ctx context.Context) string {
+	kind, _ := ctx.Value(CredentialKey).(string)
+	return kind
+}
+
+func WithCredential(ctx context.Context, kind string) context.Context {
+	return context.WithValue(ctx, CredentialKey, kind)
+}
+
+func RolesFromContext(ctx context.Context) []string {
+	roles, _ := ctx.Value(RolesKey).([]string)
+	return roles
+}
+
+func WithRoles(ctx context.Context, roles []string) context.Context {
+	return context.WithValue(ctx, RolesKey, roles)
+}
+
+// HasAnyRole reports whether the caller holds at least one of roles.
+func HasAnyRole(ctx context.Context, roles ...string) bool {
+	held := RolesFromContext(ctx)
+	for _, r := range roles {
+		if slices.Contains(held, r) {
+			return true
+		}
+	}
+	return false
+}
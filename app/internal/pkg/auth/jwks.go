@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// keySet holds the RSA signing keys of a JWKS, by key ID.
+type keySet struct {
+	keys map[string]*rsa.PublicKey
+}
+
+func loadKeySet(path string) (*keySet, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS file: %w", err)
+	}
+
+	set := &keySet{keys: make(map[string]*rsa.PublicKey)}
+
+	for _, k := range doc.Keys {
+		// Keys for other algorithms or for encryption are skipped, not rejected.
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") || (k.Alg != "" && k.Alg != "RS256") {
+			continue
+		}
+
+		key, err := k.rsaPublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWKS key %q: %w", k.Kid, err)
+		}
+		set.keys[k.Kid] = key
+	}
+
+	if len(set.keys) == 0 {
+		return nil, fmt.Errorf("JWKS file %s has no RS256 signing keys", path)
+	}
+
+	return set, nil
+}
+
+// lookup finds the key a token names in its kid header. Without a kid only a single-key set is unambiguous.
+func (s *keySet) lookup(kid string) (*rsa.PublicKey, error) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, nil
+		}
+	}
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	return key, nil
+}
+
+func (k jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("modulus: %w", err)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("exponent: %w", err)
+	}
+
+	exp := new(big.Int).SetBytes(e)
+	if !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("exponent is out of range")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+}
@@ -0,0 +1,264 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alfattd/category-service/internal/pkg/auth"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// claims builds a valid token body; tests change what they are about.
+func claims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "user-1",
+		"roles": []string{"category:write"},
+		"iss":   "https://issuer.test",
+		"aud":   "category-service",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func signHS256(t *testing.T, key []byte, c jwt.MapClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString(key)
+	require.NoError(t, err)
+	return token
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, c jwt.MapClaims) string {
+	t.Helper()
+
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+	if kid != "" {
+		tok.Header["kid"] = kid
+	}
+	token, err := tok.SignedString(key)
+	require.NoError(t, err)
+	return token
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+// writeJWKS stores the public halves of keys, by key ID, as a JWKS file.
+func writeJWKS(t *testing.T, keys map[string]*rsa.PrivateKey) string {
+	t.Helper()
+
+	var doc struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	for kid, key := range keys {
+		doc.Keys = append(doc.Keys, map[string]string{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+
+	raw, err := json.Marshal(doc)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, raw, 0o600))
+	return path
+}
+
+func newVerifier(t *testing.T, cfg auth.Config) *auth.Verifier {
+	t.Helper()
+
+	if cfg.Issuer == "" {
+		cfg.Issuer = "https://issuer.test"
+	}
+	if cfg.Audience == "" {
+		cfg.Audience = "category-service"
+	}
+
+	v, err := auth.NewVerifier(cfg)
+	require.NoError(t, err)
+	return v
+}
+
+// ─── HS256 ────────────────────────────────────────────────────────────────────
+
+func TestVerify_HS256_ReturnsSubjectAndRoles(t *testing.T) {
+	v := newVerifier(t, auth.Config{HMACSecret: testSecret})
+
+	got, err := v.Verify(signHS256(t, []byte(testSecret), claims()))
+
+	require.NoError(t, err)
+	assert.Equal(t, &auth.Claims{Subject: "user-1", Roles: []string{"category:write"}}, got)
+}
+
+func TestVerify_HS256_WrongSecret_Rejected(t *testing.T) {
+	v := newVerifier(t, auth.Config{HMACSecret: testSecret})
+
+	_, err := v.Verify(signHS256(t, []byte("another-secret-of-thirty-two-byte"), claims()))
+
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+func TestVerify_MissingSubject_Rejected(t *testing.T) {
+	v := newVerifier(t, auth.Config{HMACSecret: testSecret})
+	c := claims()
+	delete(c, "sub")
+
+	_, err := v.Verify(signHS256(t, []byte(testSecret), c))
+
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+// ─── RS256 ────────────────────────────────────────────────────────────────────
+
+func TestVerify_RS256_PicksKeyByKid(t *testing.T) {
+	first, second := newRSAKey(t), newRSAKey(t)
+	v := newVerifier(t, auth.Config{JWKSFile: writeJWKS(t, map[string]*rsa.PrivateKey{"k1": first, "k2": second})})
+
+	got, err := v.Verify(signRS256(t, second, "k2", claims()))
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", got.Subject)
+
+	_, err = v.Verify(signRS256(t, second, "k1", claims()))
+	assert.ErrorIs(t, err, auth.ErrInvalidToken, "signed by k2 but naming k1")
+
+	_, err = v.Verify(signRS256(t, second, "k3", claims()))
+	assert.ErrorIs(t, err, auth.ErrInvalidToken, "unknown kid")
+}
+
+func TestVerify_RS256_WithoutKid(t *testing.T) {
+	only := newRSAKey(t)
+	v := newVerifier(t, auth.Config{JWKSFile: writeJWKS(t, map[string]*rsa.PrivateKey{"k1": only})})
+
+	_, err := v.Verify(signRS256(t, only, "", claims()))
+	assert.NoError(t, err, "a single key is unambiguous")
+
+	first, second := newRSAKey(t), newRSAKey(t)
+	v = newVerifier(t, auth.Config{JWKSFile: writeJWKS(t, map[string]*rsa.PrivateKey{"k1": first, "k2": second})})
+
+	_, err = v.Verify(signRS256(t, first, "", claims()))
+	assert.ErrorIs(t, err, auth.ErrInvalidToken, "two keys need a kid")
+}
+
+// ─── Registered claims ────────────────────────────────────────────────────────
+
+func TestVerify_RegisteredClaims_Rejected(t *testing.T) {
+	cases := []struct {
+		name   string
+		mutate func(jwt.MapClaims)
+	}{
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }},
+		{"without exp", func(c jwt.MapClaims) { delete(c, "exp") }},
+		{"wrong issuer", func(c jwt.MapClaims) { c["iss"] = "https://someone-else.test" }},
+		{"without issuer", func(c jwt.MapClaims) { delete(c, "iss") }},
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "another-service" }},
+		{"without audience", func(c jwt.MapClaims) { delete(c, "aud") }},
+	}
+
+	v := newVerifier(t, auth.Config{HMACSecret: testSecret})
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := claims()
+			tc.mutate(c)
+
+			_, err := v.Verify(signHS256(t, []byte(testSecret), c))
+
+			assert.ErrorIs(t, err, auth.ErrInvalidToken)
+		})
+	}
+}
+
+// ─── Algorithm confusion ──────────────────────────────────────────────────────
+
+func TestVerify_AlgNone_Rejected(t *testing.T) {
+	v := newVerifier(t, auth.Config{HMACSecret: testSecret})
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	_, err = v.Verify(token)
+
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+func TestVerify_HS256SignedWithRSAPublicKey_Rejected(t *testing.T) {
+	key := newRSAKey(t)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	// The public key is, well, public, so anyone can produce these.
+	forgeries := map[string][]byte{
+		"PEM":     pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+		"DER":     der,
+		"modulus": key.N.Bytes(),
+	}
+
+	jwks := writeJWKS(t, map[string]*rsa.PrivateKey{"k1": key})
+	verifiers := map[string]*auth.Verifier{
+		"JWKS only":     newVerifier(t, auth.Config{JWKSFile: jwks}),
+		"JWKS and HMAC": newVerifier(t, auth.Config{JWKSFile: jwks, HMACSecret: testSecret}),
+	}
+
+	for vName, v := range verifiers {
+		for fName, secret := range forgeries {
+			t.Run(vName+"/"+fName, func(t *testing.T) {
+				c := claims()
+				tok := jwt.NewWithClaims(jwt.SigningMethodHS256, c)
+				tok.Header["kid"] = "k1"
+				token, err := tok.SignedString(secret)
+				require.NoError(t, err)
+
+				_, err = v.Verify(token)
+
+				assert.ErrorIs(t, err, auth.ErrInvalidToken)
+			})
+		}
+	}
+}
+
+func TestVerify_RS256WithoutJWKS_Rejected(t *testing.T) {
+	v := newVerifier(t, auth.Config{HMACSecret: testSecret})
+
+	_, err := v.Verify(signRS256(t, newRSAKey(t), "k1", claims()))
+
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+// ─── NewVerifier ──────────────────────────────────────────────────────────────
+
+func TestNewVerifier_RequiresAKey(t *testing.T) {
+	_, err := auth.NewVerifier(auth.Config{})
+
+	assert.Error(t, err)
+}
+
+func TestNewVerifier_JWKSWithoutSigningKeys_Fails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys":[{"kty":"RSA","kid":"enc","use":"enc","n":"AQAB","e":"AQAB"}]}`), 0o600))
+
+	_, err := auth.NewVerifier(auth.Config{JWKSFile: path})
+
+	assert.Error(t, err)
+}
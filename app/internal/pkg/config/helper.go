@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 )
 
 func Env(key, fallback string) string {
@@ -12,6 +14,39 @@ func Env(key, fallback string) string {
 	return fallback
 }
 
+// EnvOptional is Env for settings that may be turned off with an empty value: the fallback only
+// applies while key is not set at all.
+func EnvOptional(key, fallback string) string {
+	if val, ok := os.LookupEnv(key); ok {
+		return val
+	}
+	return fallback
+}
+
+func EnvInt(key string, fallback int) int {
+	val, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return val
+}
+
+func EnvBool(key string, fallback bool) bool {
+	val, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return val
+}
+
+func EnvDuration(key string, fallback time.Duration) time.Duration {
+	val, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return val
+}
+
 func Required(value, name string) error {
 	if value == "" {
 		return fmt.Errorf("%s is required", name)
@@ -0,0 +1,13 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// RegisterMetrics exposes the connection pool statistics of db, as reported by sql.DB.Stats, on every scrape.
+func RegisterMetrics(reg prometheus.Registerer, db *sql.DB, name string) error {
+	return reg.Register(collectors.NewDBStatsCollector(db, name))
+}
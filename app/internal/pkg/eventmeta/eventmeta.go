@@ -0,0 +1,25 @@
+// Package eventmeta carries the identity of an event through the context to the publisher,
+// the way requestid carries the request it was caused by.
+package eventmeta
+
+import (
+	"context"
+	"time"
+)
+
+type contextKey struct{}
+
+// Meta identifies one occurrence of an event. A retried or relayed event keeps it.
+type Meta struct {
+	ID   string
+	Time time.Time
+}
+
+func FromContext(ctx context.Context) (Meta, bool) {
+	m, ok := ctx.Value(contextKey{}).(Meta)
+	return m, ok
+}
+
+func WithContext(ctx context.Context, m Meta) context.Context {
+	return context.WithValue(ctx, contextKey{}, m)
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/pkg/actor"
+	"github.com/alfattd/category-service/internal/pkg/auth"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// Authenticate verifies the bearer token, if any, and stores its subject and roles in the
+// request context. Requests without a token pass through anonymously; RequireRole decides
+// whether that is acceptable. A token that is present but invalid is always rejected.
+func Authenticate(v *auth.Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scheme, token, ok := strings.Cut(header, " ")
+			if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+				unauthorized(w, "authorization header must be a bearer token")
+				return
+			}
+
+			claims, err := v.Verify(strings.TrimSpace(token))
+			if err != nil {
+				unauthorized(w, "invalid or expired token")
+				return
+			}
+
+			ctx := actor.WithContext(r.Context(), claims.Subject)
+			ctx = actor.WithCredential(ctx, actor.CredentialJWT)
+			ctx = actor.WithRoles(ctx, claims.Roles)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// APIKeyAuthenticator resolves the secret from X-API-Key to the key it belongs to.
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, secret string) (*domain.APIKey, error)
+}
+
+// APIKey authenticates requests carrying X-API-Key. The caller becomes "api-key:<id>"
+// and the key's scopes become its roles. Requests without the header pass through.
+func APIKey(keys APIKeyAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			secret := r.Header.Get(APIKeyHeader)
+			if secret == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, err := keys.Authenticate(r.Context(), secret)
+			if err != nil {
+				if errors.Is(err, domain.ErrInvalidAPIKey) {
+					unauthorized(w, err.Error())
+					return
+				}
+				writeJSONError(w, http.StatusInternalServerError, "internal server error")
+				return
+			}
+
+			ctx := actor.WithContext(r.Context(), "api-key:"+key.ID)
+			ctx = actor.WithCredential(ctx, actor.CredentialAPIKey)
+			ctx = actor.WithRoles(ctx, key.Scopes)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireRole lets a request through only if it is authenticated and holds one of roles.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if actor.FromContext(r.Context()) == "" {
+				unauthorized(w, "authentication required")
+				return
+			}
+
+			if !actor.HasAnyRole(r.Context(), roles...) {
+				writeJSONError(w, http.StatusForbidden, "requires one of the roles: "+strings.Join(roles, ", "))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRoleIf applies RequireRole(roles...) to the requests cond holds for and lets the others through.
+func RequireRoleIf(cond func(*http.Request) bool, roles ...string) func(http.Handler) http.Handler {
+	require := RequireRole(roles...)
+
+	return func(next http.Handler) http.Handler {
+		guarded := require(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cond(r) {
+				guarded.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// QueryFlag reports whether the query parameter name is "true", the way the handlers read flags.
+func QueryFlag(name string) func(*http.Request) bool {
+	return func(r *http.Request) bool { return r.URL.Query().Get(name) == "true" }
+}
+
+func unauthorized(w http.ResponseWriter, msg string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="category-service"`)
+	writeJSONError(w, http.StatusUnauthorized, msg)
+}
+
+// writeJSONError answers in the same {"errors": [...]} shape as the handlers.
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string][]string{"errors": {msg}})
+}
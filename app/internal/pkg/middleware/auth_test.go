@@ -0,0 +1,230 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/mocks"
+	"github.com/alfattd/category-service/internal/pkg/actor"
+	"github.com/alfattd/category-service/internal/pkg/auth"
+	"github.com/alfattd/category-service/internal/pkg/middleware"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func newVerifier(t *testing.T) *auth.Verifier {
+	t.Helper()
+
+	v, err := auth.NewVerifier(auth.Config{HMACSecret: testSecret})
+	require.NoError(t, err)
+	return v
+}
+
+func token(t *testing.T, sub string, roles ...string) string {
+	t.Helper()
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   sub,
+		"roles": roles,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+	return signed
+}
+
+// seen records who the wrapped handler was called for.
+type seen struct {
+	called bool
+	actor  string
+	roles  []string
+}
+
+func (s *seen) handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.called = true
+		s.actor = actor.FromContext(r.Context())
+		s.roles = actor.RolesFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func withBearer(r *http.Request, token string) *http.Request {
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+// ─── Authenticate ─────────────────────────────────────────────────────────────
+
+func TestAuthenticate_NoToken_PassesThroughAnonymously(t *testing.T) {
+	var s seen
+	h := middleware.Authenticate(newVerifier(t))(s.handler())
+
+	w := serve(h, httptest.NewRequest(http.MethodGet, "/categories", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, s.called)
+	assert.Empty(t, s.actor)
+}
+
+func TestAuthenticate_ValidToken_SetsActorAndRoles(t *testing.T) {
+	var s seen
+	h := middleware.Authenticate(newVerifier(t))(s.handler())
+
+	r := withBearer(httptest.NewRequest(http.MethodGet, "/categories", nil), token(t, "user-1", domain.RoleCategoryWrite))
+	w := serve(h, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "user-1", s.actor)
+	assert.Equal(t, []string{domain.RoleCategoryWrite}, s.roles)
+}
+
+func TestAuthenticate_BadCredentials_Unauthorized(t *testing.T) {
+	cases := map[string]string{
+		"invalid token":     "Bearer not-a-jwt",
+		"wrong scheme":      "Basic dXNlcjpwYXNz",
+		"empty bearer":      "Bearer ",
+		"no scheme":         "token",
+		"forged (alg none)": "Bearer eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJzdWIiOiJ1c2VyLTEiLCJleHAiOjQxMDI0NDQ4MDB9.",
+	}
+
+	for name, header := range cases {
+		t.Run(name, func(t *testing.T) {
+			var s seen
+			h := middleware.Authenticate(newVerifier(t))(s.handler())
+
+			r := httptest.NewRequest(http.MethodGet, "/categories", nil)
+			r.Header.Set("Authorization", header)
+			w := serve(h, r)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			assert.False(t, s.called)
+		})
+	}
+}
+
+// ─── APIKey ───────────────────────────────────────────────────────────────────
+
+func TestAPIKey_ValidKey_SetsActorAndScopes(t *testing.T) {
+	keys := new(mocks.MockAPIKeyService)
+	keys.On("Authenticate", mock.Anything, "csk_secret").Return(&domain.APIKey{ID: "key-1", Scopes: []string{domain.RoleCategoryAdmin}}, nil)
+
+	var s seen
+	r := httptest.NewRequest(http.MethodPost, "/categories", nil)
+	r.Header.Set(middleware.APIKeyHeader, "csk_secret")
+	w := serve(middleware.APIKey(keys)(s.handler()), r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "api-key:key-1", s.actor)
+	assert.Equal(t, []string{domain.RoleCategoryAdmin}, s.roles)
+}
+
+func TestAPIKey_InvalidKey_Unauthorized(t *testing.T) {
+	keys := new(mocks.MockAPIKeyService)
+	keys.On("Authenticate", mock.Anything, "csk_wrong").Return(nil, domain.ErrInvalidAPIKey)
+
+	var s seen
+	r := httptest.NewRequest(http.MethodPost, "/categories", nil)
+	r.Header.Set(middleware.APIKeyHeader, "csk_wrong")
+	w := serve(middleware.APIKey(keys)(s.handler()), r)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.False(t, s.called)
+}
+
+func TestAPIKey_LookupFails_InternalError(t *testing.T) {
+	keys := new(mocks.MockAPIKeyService)
+	keys.On("Authenticate", mock.Anything, "csk_secret").Return(nil, errors.New("connection refused"))
+
+	var s seen
+	r := httptest.NewRequest(http.MethodPost, "/categories", nil)
+	r.Header.Set(middleware.APIKeyHeader, "csk_secret")
+	w := serve(middleware.APIKey(keys)(s.handler()), r)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.False(t, s.called)
+}
+
+// ─── RequireRole ──────────────────────────────────────────────────────────────
+
+func TestRequireRole(t *testing.T) {
+	cases := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"anonymous", "", http.StatusUnauthorized},
+		{"without the role", token(t, "user-1", "category:read"), http.StatusForbidden},
+		{"with one of the roles", token(t, "user-1", domain.RoleCategoryAdmin), http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var s seen
+			h := middleware.Chain(
+				middleware.Authenticate(newVerifier(t)),
+				middleware.RequireRole(domain.RoleCategoryWrite, domain.RoleCategoryAdmin),
+			)(s.handler())
+
+			r := httptest.NewRequest(http.MethodDelete, "/categories/1", nil)
+			if tc.token != "" {
+				withBearer(r, tc.token)
+			}
+			w := serve(h, r)
+
+			assert.Equal(t, tc.status, w.Code)
+			assert.Equal(t, tc.status == http.StatusOK, s.called)
+			if tc.status == http.StatusUnauthorized {
+				assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestRequireRoleIf_OnlyGuardsFlaggedRequests(t *testing.T) {
+	cases := []struct {
+		name   string
+		target string
+		token  string
+		status int
+	}{
+		{"anonymous without the flag", "/categories", "", http.StatusOK},
+		{"flag set to false", "/categories?include_deleted=false", "", http.StatusOK},
+		{"anonymous with the flag", "/categories?include_deleted=true", "", http.StatusUnauthorized},
+		{"writer with the flag", "/categories?include_deleted=true", token(t, "user-1", domain.RoleCategoryWrite), http.StatusForbidden},
+		{"admin with the flag", "/categories?include_deleted=true", token(t, "user-1", domain.RoleCategoryAdmin), http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var s seen
+			h := middleware.Chain(
+				middleware.Authenticate(newVerifier(t)),
+				middleware.RequireRoleIf(middleware.QueryFlag("include_deleted"), domain.RoleCategoryAdmin),
+			)(s.handler())
+
+			r := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			if tc.token != "" {
+				withBearer(r, tc.token)
+			}
+			w := serve(h, r)
+
+			assert.Equal(t, tc.status, w.Code)
+			assert.Equal(t, tc.status == http.StatusOK, s.called)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics records request counts and latencies labelled by the matched route pattern rather than
+// the raw path, so IDs do not blow up the label cardinality; requests no route matches share the
+// "unmatched" route. It must sit between the ServeMux and any
+// middleware that replaces the request, because the mux stores the pattern on the request it receives.
+func Metrics(reg prometheus.Registerer) func(http.Handler) http.Handler {
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Number of HTTP requests handled, by route and status.",
+	}, []string{"method", "route", "status"})
+
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time spent handling HTTP requests, by route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	reg.MustRegister(requests, duration)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			wrapped := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(wrapped, r)
+
+			// Patterns such as "GET /categories/{id}" carry the method, which has its own label.
+			route := r.Pattern
+			if _, path, ok := strings.Cut(route, " "); ok {
+				route = path
+			}
+			if route == "" {
+				route = "unmatched"
+			}
+
+			labels := prometheus.Labels{
+				"method": methodLabel(r.Method),
+				"route":  route,
+				"status": strconv.Itoa(wrapped.status),
+			}
+			requests.With(labels).Inc()
+			duration.With(labels).Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// methodLabel keeps the method label to the standard methods, as clients can send any token.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,109 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alfattd/category-service/internal/pkg/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMetricsServer wires the middleware around nested muxes the way server.New does.
+func newMetricsServer(reg prometheus.Registerer) http.Handler {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	missing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) })
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /categories", ok)
+	mux.Handle("GET /categories/{id}", ok)
+	mux.Handle("DELETE /categories/{id}", missing)
+
+	root := http.NewServeMux()
+	root.Handle("GET /categories/by-slug/{slug}", ok)
+	root.Handle("/", mux)
+
+	return middleware.Metrics(reg)(root)
+}
+
+// requestCounts returns http_requests_total by "method route status".
+func requestCounts(t *testing.T, reg *prometheus.Registry) map[string]float64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	counts := make(map[string]float64)
+	for _, f := range families {
+		if f.GetName() != "http_requests_total" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := make(map[string]string)
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			counts[labels["method"]+" "+labels["route"]+" "+labels["status"]] = m.GetCounter().GetValue()
+		}
+	}
+	return counts
+}
+
+func TestMetrics_LabelsByRoutePattern(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	h := newMetricsServer(reg)
+
+	for _, target := range []string{"/categories/1", "/categories/2", "/categories/3?page=2"} {
+		serve(h, httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	serve(h, httptest.NewRequest(http.MethodGet, "/categories", nil))
+	serve(h, httptest.NewRequest(http.MethodGet, "/categories/by-slug/phones", nil))
+	serve(h, httptest.NewRequest(http.MethodDelete, "/categories/1", nil))
+
+	assert.Equal(t, map[string]float64{
+		"GET /categories/{id} 200":           3,
+		"GET /categories 200":                1,
+		"GET /categories/by-slug/{slug} 200": 1,
+		"DELETE /categories/{id} 404":        1,
+	}, requestCounts(t, reg))
+}
+
+func TestMetrics_UnmatchedRequestsShareOneSeries(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	h := newMetricsServer(reg)
+
+	for _, target := range []string{"/", "/wp-login.php", "/categories/1/unknown", "/a/b/c/d"} {
+		serve(h, httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	// Made-up methods neither match a route nor get a label of their own.
+	for _, method := range []string{"PROPFIND", "X-SCAN-1", "X-SCAN-2"} {
+		serve(h, httptest.NewRequest(method, "/categories", nil))
+	}
+
+	assert.Equal(t, map[string]float64{
+		"GET unmatched 404":   4,
+		"other unmatched 405": 3,
+	}, requestCounts(t, reg))
+}
+
+func TestMetrics_RecordsDuration(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	h := newMetricsServer(reg)
+
+	serve(h, httptest.NewRequest(http.MethodGet, "/categories/1", nil))
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	var found bool
+	for _, f := range families {
+		if f.GetName() == "http_request_duration_seconds" {
+			found = true
+			require.Len(t, f.GetMetric(), 1)
+			assert.Equal(t, uint64(1), f.GetMetric()[0].GetHistogram().GetSampleCount())
+		}
+	}
+	assert.True(t, found)
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alfattd/category-service/internal/pkg/actor"
+	"github.com/alfattd/category-service/internal/pkg/ratelimit"
+)
+
+// RateLimit answers with 429 once the caller has used up its bucket in l. Callers are told apart
+// by the actor that authentication put in the context, so it has to run after APIKey and
+// Authenticate; anonymous requests share a bucket per client IP.
+func RateLimit(l *ratelimit.Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d := l.Allow(rateLimitKey(r))
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(d.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+			w.Header().Set("RateLimit-Reset", seconds(d.Reset))
+
+			if !d.Allowed {
+				w.Header().Set("Retry-After", seconds(d.RetryAfter))
+				writeJSONError(w, http.StatusTooManyRequests, "rate limit exceeded, retry later")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AuthFailureLimit keeps a client from guessing credentials. Every request that carries a token or
+// API key and is still answered with 401 takes a token from the client IP's bucket in l; once the
+// bucket is empty, requests with credentials from that IP get 429 before they are checked. It runs
+// in front of APIKey and Authenticate, which RateLimit cannot, as it needs the actor they find.
+func AuthFailureLimit(l *ratelimit.Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" && r.Header.Get(APIKeyHeader) == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := clientIPKey(r)
+
+			if d := l.Peek(key); !d.Allowed {
+				w.Header().Set("Retry-After", seconds(d.RetryAfter))
+				writeJSONError(w, http.StatusTooManyRequests, "too many failed authentication attempts, retry later")
+				return
+			}
+
+			wrapped := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(wrapped, r)
+
+			if wrapped.status == http.StatusUnauthorized {
+				l.Allow(key)
+			}
+		})
+	}
+}
+
+// rateLimitKey prefixes the actor with its credential, so that a token whose subject reads like an
+// API key actor, or like a client IP key, cannot drain that caller's bucket.
+func rateLimitKey(r *http.Request) string {
+	if id := actor.FromContext(r.Context()); id != "" {
+		return actor.CredentialFromContext(r.Context()) + ":" + id
+	}
+	return clientIPKey(r)
+}
+
+func clientIPKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// seconds rounds up, so a client waiting that long is guaranteed a token.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,143 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/mocks"
+	"github.com/alfattd/category-service/internal/pkg/actor"
+	"github.com/alfattd/category-service/internal/pkg/middleware"
+	"github.com/alfattd/category-service/internal/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func request(remoteAddr, actorID string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/categories", nil)
+	r.RemoteAddr = remoteAddr
+	if actorID != "" {
+		r = r.WithContext(actor.WithContext(r.Context(), actorID))
+	}
+	return r
+}
+
+// ─── RateLimit ────────────────────────────────────────────────────────────────
+
+func TestRateLimit_HeadersAndRetryAfter(t *testing.T) {
+	var s seen
+	h := middleware.RateLimit(ratelimit.New(1, 2))(s.handler())
+
+	w := serve(h, request("10.0.0.1:1234", ""))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Reset"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+
+	w = serve(h, request("10.0.0.1:1234", ""))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Reset"))
+
+	s.called = false
+	w = serve(h, request("10.0.0.1:1234", ""))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.JSONEq(t, `{"errors":["rate limit exceeded, retry later"]}`, w.Body.String())
+	assert.False(t, s.called)
+}
+
+func TestRateLimit_KeysByActorBeforeIP(t *testing.T) {
+	var s seen
+	h := middleware.RateLimit(ratelimit.New(0.001, 1))(s.handler())
+
+	// Callers behind the same address each get their own bucket...
+	assert.Equal(t, http.StatusOK, serve(h, request("10.0.0.1:1", "user-1")).Code)
+	assert.Equal(t, http.StatusOK, serve(h, request("10.0.0.1:2", "user-2")).Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(h, request("10.0.0.9:3", "user-1")).Code, "an actor keeps its bucket from any address")
+
+	// ...while anonymous callers share the bucket of their IP, whatever the port.
+	assert.Equal(t, http.StatusOK, serve(h, request("10.0.0.1:4", "")).Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(h, request("10.0.0.1:5", "")).Code)
+	assert.Equal(t, http.StatusOK, serve(h, request("10.0.0.2:6", "")).Code)
+}
+
+func TestRateLimit_KeysByCredentialAndActor(t *testing.T) {
+	keys := new(mocks.MockAPIKeyService)
+	keys.On("Authenticate", mock.Anything, "csk_secret").Return(&domain.APIKey{ID: "key-1"}, nil)
+
+	h := middleware.Chain(
+		middleware.APIKey(keys),
+		middleware.Authenticate(newVerifier(t)),
+		middleware.RateLimit(ratelimit.New(0.001, 1)),
+	)(new(seen).handler())
+
+	withKey := func() *http.Request {
+		r := request("10.0.0.1:1", "")
+		r.Header.Set(middleware.APIKeyHeader, "csk_secret")
+		return r
+	}
+
+	// A token whose subject is the API key's actor, or a client IP key, has buckets of its own.
+	assert.Equal(t, http.StatusOK, serve(h, withBearer(request("10.0.0.1:1", ""), token(t, "api-key:key-1"))).Code)
+	assert.Equal(t, http.StatusOK, serve(h, withBearer(request("10.0.0.1:1", ""), token(t, "ip:10.0.0.1"))).Code)
+	assert.Equal(t, http.StatusOK, serve(h, withKey()).Code)
+	assert.Equal(t, http.StatusOK, serve(h, request("10.0.0.1:1", "")).Code)
+
+	assert.Equal(t, http.StatusTooManyRequests, serve(h, withKey()).Code)
+}
+
+// ─── AuthFailureLimit ─────────────────────────────────────────────────────────
+
+func TestAuthFailureLimit_LocksOutAnIPAfterFailedAttempts(t *testing.T) {
+	var s seen
+	h := middleware.Chain(
+		middleware.AuthFailureLimit(ratelimit.New(0.001, 2)),
+		middleware.Authenticate(newVerifier(t)),
+	)(s.handler())
+
+	bad := func(addr string) *http.Request { return withBearer(request(addr, ""), "not-a-jwt") }
+	good := func(addr string) *http.Request { return withBearer(request(addr, ""), token(t, "user-1")) }
+
+	// Successful attempts are free.
+	for range 5 {
+		assert.Equal(t, http.StatusOK, serve(h, good("10.0.0.1:1")).Code)
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve(h, bad("10.0.0.1:1")).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(h, bad("10.0.0.1:2")).Code)
+
+	w := serve(h, bad("10.0.0.1:3"))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	s.called = false
+	assert.Equal(t, http.StatusTooManyRequests, serve(h, good("10.0.0.1:4")).Code, "credentials are no longer checked")
+	assert.False(t, s.called)
+
+	assert.Equal(t, http.StatusOK, serve(h, request("10.0.0.1:5", "")).Code, "anonymous reads still pass")
+	assert.Equal(t, http.StatusUnauthorized, serve(h, bad("10.0.0.2:1")).Code, "other addresses are not affected")
+}
+
+func TestAuthFailureLimit_CountsRejectedAPIKeys(t *testing.T) {
+	keys := new(mocks.MockAPIKeyService)
+	keys.On("Authenticate", mock.Anything, "csk_guess").Return(nil, domain.ErrInvalidAPIKey)
+
+	h := middleware.Chain(
+		middleware.AuthFailureLimit(ratelimit.New(0.001, 1)),
+		middleware.APIKey(keys),
+	)(new(seen).handler())
+
+	withKey := func() *http.Request {
+		r := request("10.0.0.1:1", "")
+		r.Header.Set(middleware.APIKeyHeader, "csk_guess")
+		return r
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve(h, withKey()).Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(h, withKey()).Code)
+	keys.AssertNumberOfCalls(t, "Authenticate", 1)
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/alfattd/category-service/internal/pkg/requestid"
+	"github.com/alfattd/category-service/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/alfattd/category-service/internal/pkg/middleware"
+
+// Tracing starts a server span for every request, continuing a trace passed in the traceparent header.
+// It has to run after RequestID so the request ID can be recorded on the span.
+func Tracing(next http.Handler) http.Handler {
+	tracer := otel.Tracer(tracerName)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		ctx, span := tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+				attribute.String(tracing.RequestIDKey, requestid.FromContext(ctx)),
+			),
+		)
+		defer span.End()
+
+		r = r.WithContext(ctx)
+		wrapped := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(wrapped, r)
+
+		// The mux fills in the pattern only while routing, so the span is renamed afterwards.
+		route := r.Pattern
+		if _, path, ok := strings.Cut(route, " "); ok {
+			route = path
+		}
+		if route != "" {
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(wrapped.status))
+		if wrapped.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(wrapped.status))
+		}
+	})
+}
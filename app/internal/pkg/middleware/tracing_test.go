@@ -0,0 +1,139 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alfattd/category-service/internal/pkg/middleware"
+	"github.com/alfattd/category-service/internal/pkg/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans installs a tracer provider that keeps ended spans in memory for the duration of the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	return rec
+}
+
+// newTracingServer wires the middleware the way server.New does.
+func newTracingServer() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /categories/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("POST /categories", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	return middleware.Chain(middleware.RequestID, middleware.Tracing)(mux)
+}
+
+func serveTraced(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestTracing_NamesSpanAfterRoute(t *testing.T) {
+	rec := recordSpans(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/categories/42", nil)
+	r.Header.Set("X-Request-ID", "req-1")
+	w := serveTraced(newTracingServer(), r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	spans := rec.Ended()
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+	assert.Equal(t, "GET /categories/{id}", span.Name())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	assert.Equal(t, codes.Unset, span.Status().Code)
+
+	attrs := attributes(span)
+	assert.Equal(t, "GET", attrs["http.request.method"].AsString())
+	assert.Equal(t, "/categories/42", attrs["url.path"].AsString())
+	assert.Equal(t, "/categories/{id}", attrs["http.route"].AsString())
+	assert.Equal(t, int64(http.StatusOK), attrs["http.response.status_code"].AsInt64())
+	assert.Equal(t, "req-1", attrs[tracing.RequestIDKey].AsString())
+}
+
+func TestTracing_ServerErrorMarksSpan(t *testing.T) {
+	rec := recordSpans(t)
+
+	serveTraced(newTracingServer(), httptest.NewRequest(http.MethodPost, "/categories", nil))
+
+	spans := rec.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "POST /categories", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, int64(http.StatusInternalServerError), attributes(spans[0])["http.response.status_code"].AsInt64())
+}
+
+func TestTracing_UnmatchedRouteKeepsMethodName(t *testing.T) {
+	rec := recordSpans(t)
+
+	serveTraced(newTracingServer(), httptest.NewRequest(http.MethodGet, "/unknown/1", nil))
+
+	spans := rec.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET", spans[0].Name())
+	assert.NotContains(t, attributes(spans[0]), attribute.Key("http.route"))
+	assert.Equal(t, int64(http.StatusNotFound), attributes(spans[0])["http.response.status_code"].AsInt64())
+}
+
+func TestTracing_ContinuesIncomingTraceparent(t *testing.T) {
+	rec := recordSpans(t)
+
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	var inner trace.SpanContext
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /categories/{id}", func(w http.ResponseWriter, r *http.Request) {
+		inner = trace.SpanContextFromContext(r.Context())
+	})
+	h := middleware.Tracing(mux)
+
+	r := httptest.NewRequest(http.MethodGet, "/categories/42", nil)
+	r.Header.Set("traceparent", traceParent)
+	serveTraced(h, r)
+
+	spans := rec.Ended()
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	assert.True(t, span.Parent().IsRemote())
+
+	// Handlers see the server span, so what they publish carries it on.
+	assert.Equal(t, span.SpanContext().SpanID(), inner.SpanID())
+}
@@ -0,0 +1,134 @@
+// Command gensql writes migration 000011, whose SQL functions repeat names.Normalize and names.Key,
+// with the character tables of the unicode package the toolchain was built with. The migration is
+// applied once, so the command is kept for its test: it fails when names.Key or the toolchain no
+// longer produce the tables the migration was written with.
+package main
+
+import (
+	_ "embed"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/alfattd/category-service/internal/pkg/names"
+)
+
+//go:embed migration.sql.tmpl
+var migrationTemplate string
+
+var migration = template.Must(template.New("migration").Parse(migrationTemplate))
+
+// mappingsPerLine keeps the translate() tables of the migration readable.
+const mappingsPerLine = 12
+
+func main() {
+	out := flag.String("out", "", "path of the migration to write")
+	flag.Parse()
+
+	if *out == "" {
+		log.Fatal("gensql: -out is required")
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := render(f); err != nil {
+		f.Close()
+		log.Fatal(err)
+	}
+
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// render writes the migration generated from the unicode tables of this toolchain to w.
+func render(w io.Writer) error {
+	var upper, lower []rune
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		if l := unicode.ToLower(r); l != r {
+			upper = append(upper, r)
+			lower = append(lower, l)
+		}
+	}
+
+	return migration.Execute(w, struct {
+		UnicodeVersion string
+		Whitespace     string
+		Diacritics     string
+		Upper          string
+		Lower          string
+	}{
+		UnicodeVersion: unicode.Version,
+		Whitespace:     regexpClass(unicode.IsSpace),
+		Diacritics:     regexpClass(func(r rune) bool { return unicode.Is(names.Diacritics, r) }),
+		Upper:          unicodeLiteral(upper),
+		Lower:          unicodeLiteral(lower),
+	})
+}
+
+// regexpClass returns a bracket expression matching the runes in, merging runs into ranges.
+func regexpClass(in func(rune) bool) string {
+	var b strings.Builder
+	b.WriteByte('[')
+
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		if !in(r) {
+			continue
+		}
+
+		last := r
+		for last < unicode.MaxRune && in(last+1) {
+			last++
+		}
+
+		b.WriteString(regexpEscape(r))
+		if last > r {
+			b.WriteByte('-')
+			b.WriteString(regexpEscape(last))
+		}
+		r = last
+	}
+
+	b.WriteByte(']')
+	return b.String()
+}
+
+func regexpEscape(r rune) string {
+	if r > 0xFFFF {
+		return fmt.Sprintf(`\U%08X`, r)
+	}
+	return fmt.Sprintf(`\u%04X`, r)
+}
+
+// unicodeLiteral returns runes as concatenated U& string constants, mappingsPerLine to a line.
+func unicodeLiteral(runes []rune) string {
+	var lines []string
+
+	for len(runes) > 0 {
+		n := min(mappingsPerLine, len(runes))
+
+		var b strings.Builder
+		b.WriteString("U&'")
+		for _, r := range runes[:n] {
+			if r > 0xFFFF {
+				fmt.Fprintf(&b, `\+%06X`, r)
+			} else {
+				fmt.Fprintf(&b, `\%04X`, r)
+			}
+		}
+		b.WriteByte('\'')
+
+		lines = append(lines, b.String())
+		runes = runes[n:]
+	}
+
+	return "        " + strings.Join(lines, "\n        || ")
+}
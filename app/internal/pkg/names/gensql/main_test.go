@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/require"
+)
+
+const migrationPath = "../../../../../postgres/migrations/000011_add_name_key_to_categories.up.sql"
+
+func TestMigrationIsUpToDate(t *testing.T) {
+	var want bytes.Buffer
+	require.NoError(t, render(&want))
+
+	got, err := os.ReadFile(migrationPath)
+	require.NoError(t, err)
+
+	if !bytes.Equal(want.Bytes(), got) {
+		t.Fatal("names.Key no longer folds names like migration 000011: keep its folding, or recompute name_key in a new migration")
+	}
+}
+
+// A toolchain with a newer Unicode version makes names.Key disagree with the tables the migration
+// was generated with, which TestMigrationIsUpToDate also catches; this names the reason.
+func TestUnicodeVersion(t *testing.T) {
+	got, err := os.ReadFile(migrationPath)
+	require.NoError(t, err)
+
+	m := regexp.MustCompile(`\(Unicode ([0-9.]+)\)`).FindSubmatch(got)
+	require.NotNil(t, m, "migration 000011 does not name its Unicode version")
+
+	if version := string(m[1]); version != unicode.Version {
+		t.Fatalf("migration 000011 was generated from Unicode %s, this toolchain has %s: build with the toolchain go.mod pins", version, unicode.Version)
+	}
+}
@@ -0,0 +1,34 @@
+// Package names normalizes category names and derives the key they have to be unique by.
+package names
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize composes name to Unicode NFC, trims it and collapses runs of whitespace into one space.
+func Normalize(name string) string {
+	return strings.Join(strings.Fields(norm.NFC.String(name)), " ")
+}
+
+// Diacritics are the combining marks Key drops: the accents of Latin, Greek and Cyrillic letters.
+// Marks of other scripts, such as kana voicing marks or Hebrew points, tell words apart and stay.
+var Diacritics = &unicode.RangeTable{
+	R16: []unicode.Range16{{Lo: 0x0300, Hi: 0x036F, Stride: 1}},
+}
+
+// Key folds case and accents, and compatibility forms such as ligatures, so that "Books",
+// "books " and "Bóoks" all share a key while "Books" and "Book" do not.
+func Key(name string) string {
+	var b strings.Builder
+
+	for _, r := range norm.NFKD.String(Normalize(name)) {
+		if !unicode.Is(Diacritics, r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+
+	return norm.NFC.String(b.String())
+}
@@ -0,0 +1,58 @@
+package names_test
+
+import (
+	"testing"
+
+	"github.com/alfattd/category-service/internal/pkg/names"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"trims", "  Books ", "Books"},
+		{"collapses whitespace", "Books\t and   More", "Books and More"},
+		{"composes", "Bo\u0301oks", "B\u00f3oks"},
+		{"keeps case and accents", "B\u00f3oks", "B\u00f3oks"},
+		{"only whitespace", " \u3000\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, names.Normalize(tt.in))
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"folds case", "BOOKS", "books"},
+		{"drops accents", "B\u00f3oks", "books"},
+		{"drops decomposed accents", "Bo\u0301oks", "books"},
+		{"normalizes spacing", "  Books \t and  More ", "books and more"},
+		{"folds compatibility forms", "ﬁle", "file"},
+		{"keeps letters that are not accented", "Straße", "straße"},
+		{"keeps kana voicing marks", "\u304c", "\u304c"},
+		{"keeps Hebrew points", "\u05e9\u05c1", "\u05e9\u05c1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, names.Key(tt.in))
+		})
+	}
+}
+
+func TestKey_DistinctNamesKeepDistinctKeys(t *testing.T) {
+	assert.NotEqual(t, names.Key("Books"), names.Key("Book"))
+	assert.NotEqual(t, names.Key("Ωmega"), names.Key("Omega"))
+	assert.NotEqual(t, names.Key("\u304c\u3063\u3053\u3046"), names.Key("\u304b\u3063\u3053\u3046"))
+	assert.NotEqual(t, names.Key("\u30d0\u30b9"), names.Key("\u30cf\u30b9"))
+}
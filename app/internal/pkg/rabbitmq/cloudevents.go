@@ -0,0 +1,171 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/pkg/eventmeta"
+	"github.com/alfattd/category-service/internal/pkg/requestid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Event formats. FormatLegacy keeps the plain payload consumers had before CloudEvents;
+// the other two follow the CloudEvents 1.0 AMQP binding.
+const (
+	FormatLegacy     = "legacy"
+	FormatStructured = "structured"
+	FormatBinary     = "binary"
+)
+
+const (
+	specVersion = "1.0"
+
+	// DataSchema names the version of the data every CloudEvent carries. It changes
+	// whenever a field is removed or changes its meaning.
+	DataSchema = "urn:category-service:schema:category-event:v1"
+
+	headerPrefix = "cloudEvents:"
+)
+
+// errNoEventMeta is returned for an event published without the id and time eventmeta carries.
+// Making them up would give every retry of the event a new id, which consumers cannot deduplicate.
+var errNoEventMeta = errors.New("event has no id and time to publish with")
+
+// WithEventFormat selects how events are encoded. source becomes the CloudEvents source attribute.
+func WithEventFormat(format, source string) Option {
+	return func(p *Publisher) {
+		p.format = format
+		p.source = source
+	}
+}
+
+type cloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	DataSchema      string    `json:"dataschema"`
+	RequestID       string    `json:"requestid,omitempty"`
+	Data            eventData `json:"data"`
+}
+
+// eventData carries the category as the event left it and, for updates and deletions,
+// as it was before.
+type eventData struct {
+	ID            string            `json:"id"`
+	Category      *categorySnapshot `json:"category,omitempty"`
+	Previous      *categorySnapshot `json:"previous,omitempty"`
+	OldParentID   *string           `json:"old_parent_id,omitempty"`
+	ChangedFields []string          `json:"changed_fields,omitempty"`
+}
+
+type categorySnapshot struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Slug      string     `json:"slug"`
+	ParentID  *string    `json:"parent_id"`
+	Version   int64      `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+func toSnapshot(c *domain.Category) *categorySnapshot {
+	if c == nil {
+		return nil
+	}
+	return &categorySnapshot{
+		ID:        c.ID,
+		Name:      c.Name,
+		Slug:      c.Slug,
+		ParentID:  c.ParentID,
+		Version:   c.Version,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+		DeletedAt: c.DeletedAt,
+	}
+}
+
+// ceType turns "category_created" into "category.created".
+func ceType(eventType string) string {
+	return strings.Replace(eventType, "_", ".", 1)
+}
+
+// encode builds the message for event in the configured format, with after and before as in send.
+func (p *Publisher) encode(ctx context.Context, meta eventmeta.Meta, event categoryEvent, after, before *domain.Category) (amqp.Publishing, error) {
+	msg := amqp.Publishing{
+		Headers:      amqp.Table{},
+		MessageId:    meta.ID,
+		Timestamp:    meta.Time,
+		DeliveryMode: amqp.Persistent,
+	}
+
+	if p.format == FormatLegacy || p.format == "" {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return amqp.Publishing{}, err
+		}
+		msg.ContentType = "application/json"
+		msg.Body = body
+		return msg, nil
+	}
+
+	ce := cloudEvent{
+		SpecVersion:     specVersion,
+		ID:              meta.ID,
+		Source:          p.source,
+		Type:            ceType(event.Type),
+		Subject:         event.ID,
+		Time:            meta.Time.UTC(),
+		DataContentType: "application/json",
+		DataSchema:      DataSchema,
+		RequestID:       requestid.FromContext(ctx),
+		Data: eventData{
+			ID:            event.ID,
+			Category:      toSnapshot(after),
+			Previous:      toSnapshot(before),
+			OldParentID:   event.OldParentID,
+			ChangedFields: event.ChangedFields,
+		},
+	}
+
+	msg.Type = ce.Type
+	msg.AppId = ce.Source
+
+	if p.format == FormatStructured {
+		body, err := json.Marshal(ce)
+		if err != nil {
+			return amqp.Publishing{}, err
+		}
+		msg.ContentType = "application/cloudevents+json"
+		msg.Body = body
+		return msg, nil
+	}
+
+	body, err := json.Marshal(ce.Data)
+	if err != nil {
+		return amqp.Publishing{}, err
+	}
+
+	msg.Headers[headerPrefix+"specversion"] = ce.SpecVersion
+	msg.Headers[headerPrefix+"id"] = ce.ID
+	msg.Headers[headerPrefix+"source"] = ce.Source
+	msg.Headers[headerPrefix+"type"] = ce.Type
+	msg.Headers[headerPrefix+"subject"] = ce.Subject
+	msg.Headers[headerPrefix+"time"] = ce.Time.Format(time.RFC3339Nano)
+	msg.Headers[headerPrefix+"dataschema"] = ce.DataSchema
+	if ce.RequestID != "" {
+		msg.Headers[headerPrefix+"requestid"] = ce.RequestID
+	}
+	msg.ContentType = ce.DataContentType
+	msg.Body = body
+
+	return msg, nil
+}
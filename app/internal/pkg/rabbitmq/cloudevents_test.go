@@ -0,0 +1,177 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/pkg/eventmeta"
+	"github.com/alfattd/category-service/internal/pkg/requestid"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMeta = eventmeta.Meta{ID: "evt-1", Time: time.Date(2026, 1, 2, 10, 4, 5, 0, time.FixedZone("UTC+7", 7*60*60))}
+
+func testCategory(name, slug string) *domain.Category {
+	parentID := "p-1"
+	return &domain.Category{
+		ID:        "c-1",
+		Name:      name,
+		Slug:      slug,
+		ParentID:  &parentID,
+		Version:   2,
+		CreatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+// The legacy payloads are the ones consumers got before CloudEvents, byte for byte.
+func TestEncode_Legacy_KeepsPlainPayload(t *testing.T) {
+	oldParentID := "p-0"
+
+	tests := []struct {
+		name  string
+		event categoryEvent
+		want  string
+	}{
+		{
+			"created",
+			categoryEvent{ID: "c-1", Name: "Phones", Slug: "phones", ParentID: strPtr("p-1"), Type: "category_created"},
+			`{"id":"c-1","name":"Phones","slug":"phones","parent_id":"p-1","type":"category_created"}`,
+		},
+		{
+			"moved",
+			categoryEvent{ID: "c-1", Name: "Phones", Slug: "phones", ParentID: strPtr("p-1"), OldParentID: &oldParentID, Type: "category_moved"},
+			`{"id":"c-1","name":"Phones","slug":"phones","parent_id":"p-1","old_parent_id":"p-0","type":"category_moved"}`,
+		},
+		{
+			"restored at the root",
+			categoryEvent{ID: "c-1", Name: "Phones", Slug: "phones", Type: "category_restored"},
+			`{"id":"c-1","name":"Phones","slug":"phones","type":"category_restored"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Publisher{format: FormatLegacy, source: "category-service"}
+
+			msg, err := p.encode(requestid.WithContext(context.Background(), "req-1"), testMeta, tt.event, nil, nil)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(msg.Body))
+			assert.Equal(t, "application/json", msg.ContentType)
+			assert.Equal(t, amqp.Persistent, msg.DeliveryMode)
+			assert.Equal(t, testMeta.ID, msg.MessageId)
+			assert.Equal(t, testMeta.Time, msg.Timestamp)
+			assert.Empty(t, msg.Type)
+			assert.Empty(t, msg.Headers)
+		})
+	}
+}
+
+func TestEncode_CloudEvents_CarryAttributesAndData(t *testing.T) {
+	tests := []struct {
+		format      string
+		contentType string
+		// split returns the CloudEvents attributes of msg and its data.
+		split func(t *testing.T, msg amqp.Publishing) (map[string]any, []byte)
+	}{
+		{
+			format:      FormatStructured,
+			contentType: "application/cloudevents+json",
+			split: func(t *testing.T, msg amqp.Publishing) (map[string]any, []byte) {
+				var ce map[string]json.RawMessage
+				require.NoError(t, json.Unmarshal(msg.Body, &ce))
+
+				attrs := make(map[string]any)
+				for name, value := range ce {
+					if name == "data" {
+						continue
+					}
+					var s string
+					require.NoError(t, json.Unmarshal(value, &s), name)
+					attrs[name] = s
+				}
+				return attrs, ce["data"]
+			},
+		},
+		{
+			format:      FormatBinary,
+			contentType: "application/json",
+			split: func(t *testing.T, msg amqp.Publishing) (map[string]any, []byte) {
+				attrs := map[string]any{"datacontenttype": msg.ContentType}
+				for name, value := range msg.Headers {
+					if attr, ok := strings.CutPrefix(name, headerPrefix); ok {
+						attrs[attr] = value
+					}
+				}
+				return attrs, msg.Body
+			},
+		},
+	}
+
+	before, after := testCategory("Phones", "phones"), testCategory("Mobile Phones", "mobile-phones")
+	event := categoryEvent{ID: "c-1", Name: after.Name, Slug: after.Slug, ParentID: after.ParentID, ChangedFields: []string{"name", "slug"}, Type: "category_updated"}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			p := &Publisher{format: tt.format, source: "category-service"}
+
+			msg, err := p.encode(requestid.WithContext(context.Background(), "req-1"), testMeta, event, after, before)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.contentType, msg.ContentType)
+			assert.Equal(t, amqp.Persistent, msg.DeliveryMode)
+			assert.Equal(t, testMeta.ID, msg.MessageId)
+			assert.Equal(t, "category.updated", msg.Type)
+			assert.Equal(t, "category-service", msg.AppId)
+
+			attrs, data := tt.split(t, msg)
+			assert.Equal(t, map[string]any{
+				"specversion":     "1.0",
+				"id":              "evt-1",
+				"source":          "category-service",
+				"type":            "category.updated",
+				"subject":         "c-1",
+				"time":            "2026-01-02T03:04:05Z",
+				"datacontenttype": "application/json",
+				"dataschema":      DataSchema,
+				"requestid":       "req-1",
+			}, attrs)
+			assert.JSONEq(t, `{
+				"id": "c-1",
+				"category": {"id": "c-1", "name": "Mobile Phones", "slug": "mobile-phones", "parent_id": "p-1", "version": 2,
+					"created_at": "2026-01-01T00:00:00Z", "updated_at": "2026-01-02T03:04:05Z"},
+				"previous": {"id": "c-1", "name": "Phones", "slug": "phones", "parent_id": "p-1", "version": 2,
+					"created_at": "2026-01-01T00:00:00Z", "updated_at": "2026-01-02T03:04:05Z"},
+				"changed_fields": ["name", "slug"]
+			}`, string(data))
+		})
+	}
+}
+
+func TestEncode_Binary_WithoutRequestID_OmitsHeader(t *testing.T) {
+	p := &Publisher{format: FormatBinary, source: "category-service"}
+
+	msg, err := p.encode(context.Background(), testMeta, categoryEvent{ID: "c-1", Type: "category_created"}, testCategory("Phones", "phones"), nil)
+
+	require.NoError(t, err)
+	assert.NotContains(t, msg.Headers, headerPrefix+"requestid")
+}
+
+func TestSend_WithoutEventMeta_ReturnsErrorWithoutPublishing(t *testing.T) {
+	ch := newStandIn(randomLatency)
+	p := newStandInPublisher(ch)
+
+	err := p.PublishCategoryCreated(context.Background(), testCategory("Phones", "phones"))
+
+	assert.ErrorIs(t, err, errNoEventMeta)
+	assert.Equal(t, uint64(1), ch.GetNextPublishSeqNo(), "nothing should have been published")
+}
+
+func strPtr(s string) *string { return &s }
@@ -0,0 +1,184 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// confirmBuffer is how many confirms and returns may wait for the tracker before the
+// channel blocks; it bounds nothing else, any number of publishes can be in flight.
+const confirmBuffer = 256
+
+var (
+	// ErrUnroutable means the broker had no queue for a mandatory message. Retrying does not help
+	// until a consumer binds one.
+	ErrUnroutable = errors.New("message returned by broker: no queue is bound for its routing key")
+
+	errNacked        = errors.New("message not acknowledged by broker (nack received)")
+	errChannelClosed = errors.New("channel closed before the broker confirmed the message")
+)
+
+// channel is the part of *amqp.Channel the publisher uses once it is set up.
+type channel interface {
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	GetNextPublishSeqNo() uint64
+	IsClosed() bool
+	Close() error
+}
+
+// declarer is the part of *amqp.Channel declare uses.
+type declarer interface {
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error
+}
+
+// connection is the part of *amqp.Connection the publisher uses.
+type connection interface {
+	IsClosed() bool
+	Close() error
+}
+
+// confirmTracker hands each broker confirm to the publish with its delivery tag, so that any
+// number of publishes can wait at once. A channel numbers its publishes from 1, so each channel
+// gets a tracker of its own.
+type confirmTracker struct {
+	mu      sync.Mutex
+	pending map[uint64]*pendingConfirm
+	// byMessageID finds the publish a return is for, as returns carry no delivery tag.
+	byMessageID map[string]uint64
+	closed      bool
+}
+
+type pendingConfirm struct {
+	messageID string
+	returned  bool
+	done      chan error
+}
+
+// newConfirmTracker starts matching confirms and returns until the channel closes them.
+func newConfirmTracker(confirms <-chan amqp.Confirmation, returns <-chan amqp.Return) *confirmTracker {
+	t := &confirmTracker{
+		pending:     make(map[uint64]*pendingConfirm),
+		byMessageID: make(map[string]uint64),
+	}
+	go t.run(confirms, returns)
+	return t
+}
+
+// add registers the publish that is about to get tag. It has to be called before the message is
+// published, under the same lock, so that tags and publishes stay in step.
+func (t *confirmTracker) add(tag uint64, messageID string) <-chan error {
+	done := make(chan error, 1)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		done <- errChannelClosed
+		return done
+	}
+
+	t.pending[tag] = &pendingConfirm{messageID: messageID, done: done}
+	if messageID != "" {
+		t.byMessageID[messageID] = tag
+	}
+
+	return done
+}
+
+// forget drops a publish that failed or stopped waiting.
+func (t *confirmTracker) forget(tag uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if pc, ok := t.pending[tag]; ok {
+		delete(t.byMessageID, pc.messageID)
+		delete(t.pending, tag)
+	}
+}
+
+func (t *confirmTracker) run(confirms <-chan amqp.Confirmation, returns <-chan amqp.Return) {
+	for {
+		select {
+		case r, ok := <-returns:
+			if !ok {
+				returns = nil
+				continue
+			}
+			t.markReturned(r)
+		case c, ok := <-confirms:
+			if !ok {
+				t.close()
+				return
+			}
+			// The broker sends a return before the ack of the same message, and the channel
+			// hands them over in that order, so any return for c is already waiting.
+			t.drainReturns(returns)
+			t.resolve(c)
+		}
+	}
+}
+
+func (t *confirmTracker) drainReturns(returns <-chan amqp.Return) {
+	for {
+		select {
+		case r, ok := <-returns:
+			if !ok {
+				return
+			}
+			t.markReturned(r)
+		default:
+			return
+		}
+	}
+}
+
+func (t *confirmTracker) markReturned(r amqp.Return) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if tag, ok := t.byMessageID[r.MessageId]; ok {
+		t.pending[tag].returned = true
+	}
+}
+
+func (t *confirmTracker) resolve(c amqp.Confirmation) {
+	t.mu.Lock()
+	pc, ok := t.pending[c.DeliveryTag]
+	if ok {
+		delete(t.byMessageID, pc.messageID)
+		delete(t.pending, c.DeliveryTag)
+	}
+	t.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	switch {
+	case !c.Ack:
+		pc.done <- errNacked
+	case pc.returned:
+		pc.done <- fmt.Errorf("%w (%s)", ErrUnroutable, pc.messageID)
+	default:
+		pc.done <- nil
+	}
+}
+
+// close fails every publish still waiting; their channel is gone and will not confirm them.
+func (t *confirmTracker) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closed = true
+	for tag, pc := range t.pending {
+		pc.done <- errChannelClosed
+		delete(t.pending, tag)
+	}
+	clear(t.byMessageID)
+}
@@ -24,24 +24,49 @@ func (p *Publisher) connect() error {
 		return fmt.Errorf("failed to set confirm mode: %w", err)
 	}
 
-	_, err = ch.QueueDeclare(
-		p.queue,
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
-	if err != nil {
+	if err := p.declare(ch); err != nil {
 		ch.Close()
 		conn.Close()
-		return fmt.Errorf("failed to declare queue: %w", err)
+		return err
 	}
 
 	p.conn = conn
 	p.channel = ch
+	p.tracker = newConfirmTracker(
+		ch.NotifyPublish(make(chan amqp.Confirmation, confirmBuffer)),
+		ch.NotifyReturn(make(chan amqp.Return, confirmBuffer)),
+	)
+
+	return nil
+}
+
+// declare sets up the exchange and the queue the publisher was configured with. The queue is
+// bound to the exchange for every event, which works for any exchange type.
+func (p *Publisher) declare(ch declarer) error {
+	if p.exchange != "" {
+		if err := ch.ExchangeDeclare(p.exchange, p.exchangeKind, true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare exchange: %w", err)
+		}
+	}
 
-	p.confirmCh = ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+	if p.queue == "" {
+		return nil
+	}
+
+	if _, err := ch.QueueDeclare(p.queue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	if p.exchange == "" {
+		return nil
+	}
+
+	for _, eventType := range eventTypes {
+		_, key := p.route(eventType)
+		if err := ch.QueueBind(p.queue, key, p.exchange, false, nil); err != nil {
+			return fmt.Errorf("failed to bind queue: %w", err)
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,98 @@
+package rabbitmq
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// declarations records what declare asks the broker for, one line per call.
+type declarations struct {
+	calls       []string
+	exchangeErr error
+}
+
+func (d *declarations) ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, _ amqp.Table) error {
+	d.calls = append(d.calls, fmt.Sprintf("exchange %s %s durable=%t autoDelete=%t internal=%t", name, kind, durable, autoDelete, internal))
+	return d.exchangeErr
+}
+
+func (d *declarations) QueueDeclare(name string, durable, autoDelete, exclusive, _ bool, _ amqp.Table) (amqp.Queue, error) {
+	d.calls = append(d.calls, fmt.Sprintf("queue %s durable=%t autoDelete=%t exclusive=%t", name, durable, autoDelete, exclusive))
+	return amqp.Queue{Name: name}, nil
+}
+
+func (d *declarations) QueueBind(name, key, exchange string, _ bool, _ amqp.Table) error {
+	d.calls = append(d.calls, fmt.Sprintf("bind %s %s %s", name, exchange, key))
+	return nil
+}
+
+func TestDeclare(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []Option
+		queue string
+		want  []string
+	}{
+		{
+			name:  "queue only",
+			queue: "category_events",
+			want:  []string{"queue category_events durable=true autoDelete=false exclusive=false"},
+		},
+		{
+			name:  "topic exchange binds the queue for every event",
+			opts:  []Option{WithExchange("catalog", ExchangeTopic, DefaultRoutingKey)},
+			queue: "category_events",
+			want: []string{
+				"exchange catalog topic durable=true autoDelete=false internal=false",
+				"queue category_events durable=true autoDelete=false exclusive=false",
+				"bind category_events catalog category.created",
+				"bind category_events catalog category.updated",
+				"bind category_events catalog category.deleted",
+				"bind category_events catalog category.moved",
+				"bind category_events catalog category.restored",
+			},
+		},
+		{
+			name:  "direct exchange with a custom pattern",
+			opts:  []Option{WithExchange("catalog", ExchangeDirect, "shop.{event}")},
+			queue: "category_events",
+			want: []string{
+				"exchange catalog direct durable=true autoDelete=false internal=false",
+				"queue category_events durable=true autoDelete=false exclusive=false",
+				"bind category_events catalog shop.created",
+				"bind category_events catalog shop.updated",
+				"bind category_events catalog shop.deleted",
+				"bind category_events catalog shop.moved",
+				"bind category_events catalog shop.restored",
+			},
+		},
+		{
+			name: "exchange without a queue",
+			opts: []Option{WithExchange("catalog", ExchangeFanout, DefaultRoutingKey)},
+			want: []string{"exchange catalog fanout durable=true autoDelete=false internal=false"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := new(declarations)
+
+			require.NoError(t, newPublisher("amqp://localhost", tt.queue, tt.opts...).declare(d))
+			assert.Equal(t, tt.want, d.calls)
+		})
+	}
+}
+
+func TestDeclare_ExchangeFails_DeclaresNoQueue(t *testing.T) {
+	d := &declarations{exchangeErr: errors.New("access refused")}
+
+	err := newPublisher("amqp://localhost", "category_events", WithExchange("catalog", ExchangeTopic, DefaultRoutingKey)).declare(d)
+
+	assert.ErrorContains(t, err, "failed to declare exchange")
+	assert.Len(t, d.calls, 1)
+}
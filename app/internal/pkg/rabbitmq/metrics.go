@@ -0,0 +1,40 @@
+package rabbitmq
+
+import "github.com/prometheus/client_golang/prometheus"
+
+type publisherMetrics struct {
+	published *prometheus.CounterVec
+	retries   *prometheus.CounterVec
+	confirm   prometheus.Histogram
+}
+
+func newPublisherMetrics() *publisherMetrics {
+	return &publisherMetrics{
+		published: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "category_events_published_total",
+			Help: "Number of category events handed to the broker, by type and result after all retries.",
+		}, []string{"type", "result"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "category_event_publish_retries_total",
+			Help: "Number of publish attempts repeated after a failure, by event type.",
+		}, []string{"type"}),
+		confirm: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "category_event_confirm_duration_seconds",
+			Help:    "Time from publishing an event until the broker confirmed it.",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}),
+	}
+}
+
+func (m *publisherMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.published, m.retries, m.confirm}
+}
+
+type Option func(*Publisher)
+
+// WithMetrics registers the publish counters and confirm latency histogram with reg.
+func WithMetrics(reg prometheus.Registerer) Option {
+	return func(p *Publisher) {
+		reg.MustRegister(p.metrics.collectors()...)
+	}
+}
@@ -2,13 +2,21 @@ package rabbitmq
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"time"
 
 	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/pkg/eventmeta"
+	"github.com/alfattd/category-service/internal/pkg/requestid"
+	"github.com/alfattd/category-service/internal/pkg/tracing"
 	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -21,67 +29,158 @@ func (p *Publisher) PublishCategoryCreated(
 	ctx context.Context,
 	c *domain.Category,
 ) error {
-	return p.publishWithRetry(ctx, categoryEvent{
-		ID:   c.ID,
-		Name: c.Name,
-		Type: "category_created",
+	return p.send(ctx, c, nil, categoryEvent{
+		ID:       c.ID,
+		Name:     c.Name,
+		Slug:     c.Slug,
+		ParentID: c.ParentID,
+		Type:     "category_created",
 	})
 }
 
 func (p *Publisher) PublishCategoryUpdated(
+	ctx context.Context,
+	before, after *domain.Category,
+	changedFields []string,
+) error {
+	event := categoryEvent{
+		ID:            after.ID,
+		Name:          after.Name,
+		Slug:          after.Slug,
+		ParentID:      after.ParentID,
+		ChangedFields: changedFields,
+		Type:          "category_updated",
+	}
+	if before != nil {
+		event.Previous = &categoryValues{Name: before.Name, Slug: before.Slug, ParentID: before.ParentID}
+	}
+
+	return p.send(ctx, after, before, event)
+}
+
+func (p *Publisher) PublishCategoryDeleted(
 	ctx context.Context,
 	c *domain.Category,
 ) error {
-	return p.publishWithRetry(ctx, categoryEvent{
-		ID:   c.ID,
-		Name: c.Name,
-		Type: "category_updated",
+	return p.send(ctx, nil, c, categoryEvent{
+		ID:       c.ID,
+		Name:     c.Name,
+		Slug:     c.Slug,
+		ParentID: c.ParentID,
+		Type:     "category_deleted",
 	})
 }
 
-func (p *Publisher) PublishCategoryDeleted(
+func (p *Publisher) PublishCategoryMoved(
+	ctx context.Context,
+	c *domain.Category,
+	oldParentID *string,
+) error {
+	return p.send(ctx, c, nil, categoryEvent{
+		ID:          c.ID,
+		Name:        c.Name,
+		Slug:        c.Slug,
+		ParentID:    c.ParentID,
+		OldParentID: oldParentID,
+		Type:        "category_moved",
+	})
+}
+
+func (p *Publisher) PublishCategoryRestored(
 	ctx context.Context,
-	id string,
+	c *domain.Category,
 ) error {
-	return p.publishWithRetry(ctx, categoryEvent{
-		ID:   id,
-		Type: "category_deleted",
+	return p.send(ctx, c, nil, categoryEvent{
+		ID:       c.ID,
+		Name:     c.Name,
+		Slug:     c.Slug,
+		ParentID: c.ParentID,
+		Type:     "category_restored",
 	})
 }
 
-func (p *Publisher) publishWithRetry(ctx context.Context, event categoryEvent) error {
+// send encodes event in the configured format and publishes it. after is the category the event
+// leaves behind and before the one it started from; either is nil when there is none.
+func (p *Publisher) send(ctx context.Context, after, before *domain.Category, event categoryEvent) error {
+	meta, ok := eventmeta.FromContext(ctx)
+	if !ok || meta.ID == "" {
+		return fmt.Errorf("failed to publish %s: %w", event.Type, errNoEventMeta)
+	}
+
+	msg, err := p.encode(ctx, meta, event, after, before)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	return p.publishWithRetry(ctx, event.Type, msg)
+}
+
+func (p *Publisher) publishWithRetry(ctx context.Context, eventType string, msg amqp.Publishing) error {
 	var lastErr error
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		if attempt > 0 {
+			p.metrics.retries.WithLabelValues(eventType).Inc()
+
 			delay := time.Duration(math.Pow(2, float64(attempt-1))) * baseDelay
 			select {
 			case <-time.After(delay):
 			case <-ctx.Done():
+				p.metrics.published.WithLabelValues(eventType, "failure").Inc()
 				return fmt.Errorf("context cancelled during retry backoff: %w", ctx.Err())
 			}
 		}
 
-		if err := p.publish(ctx, event); err != nil {
+		start := time.Now()
+		if err := p.publish(ctx, eventType, msg); err != nil {
 			lastErr = err
+			if errors.Is(err, ErrUnroutable) {
+				break
+			}
 			continue
 		}
 
+		p.metrics.confirm.Observe(time.Since(start).Seconds())
+		p.metrics.published.WithLabelValues(eventType, "success").Inc()
 		return nil
 	}
 
+	p.metrics.published.WithLabelValues(eventType, "failure").Inc()
+	if errors.Is(lastErr, ErrUnroutable) {
+		return lastErr
+	}
 	return fmt.Errorf("failed to publish after %d attempts: %w", maxRetries+1, lastErr)
 }
 
-func (p *Publisher) publish(ctx context.Context, event categoryEvent) error {
+func (p *Publisher) publish(ctx context.Context, eventType string, msg amqp.Publishing) (err error) {
 	if err := ctx.Err(); err != nil {
 		return err
 	}
 
-	body, err := json.Marshal(event)
-	if err != nil {
-		return fmt.Errorf("failed to marshal event: %w", err)
-	}
+	exchange, key := p.route(eventType)
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, p.destination()+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemRabbitMQ,
+			semconv.MessagingOperationTypeSend,
+			semconv.MessagingOperationName("publish"),
+			semconv.MessagingDestinationName(p.destination()),
+			semconv.MessagingRabbitMQDestinationRoutingKey(key),
+			attribute.String("category.event.type", eventType),
+			semconv.MessagingMessageID(msg.MessageId),
+			attribute.String(tracing.RequestIDKey, requestid.FromContext(ctx)),
+		),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(msg.Headers))
 
 	// Use a dedicated timeout for publish, independent of the HTTP request context.
 	// This prevents a client disconnect from aborting an in-flight publish.
@@ -97,20 +196,16 @@ func (p *Publisher) publish(ctx context.Context, event categoryEvent) error {
 		}
 	}
 
-	err = p.channel.PublishWithContext(
-		publishCtx,
-		"",
-		p.queue,
-		false,
-		false,
-		amqp.Publishing{
-			ContentType:  "application/json",
-			Body:         body,
-			DeliveryMode: amqp.Persistent,
-		},
-	)
+	// The tag is taken and the message published under the lock, so that no other publish
+	// can take the tag in between. Waiting for the confirm happens outside it.
+	ch, tracker := p.channel, p.tracker
+	tag := ch.GetNextPublishSeqNo()
+	confirmed := tracker.add(tag, msg.MessageId)
 
-	confirmCh := p.confirmCh
+	err = ch.PublishWithContext(publishCtx, exchange, key, p.mandatory, false, msg)
+	if err != nil {
+		tracker.forget(tag)
+	}
 
 	p.mu.Unlock()
 
@@ -119,15 +214,10 @@ func (p *Publisher) publish(ctx context.Context, event categoryEvent) error {
 	}
 
 	select {
-	case confirm, ok := <-confirmCh:
-		if !ok {
-			return fmt.Errorf("confirm channel closed unexpectedly")
-		}
-		if !confirm.Ack {
-			return fmt.Errorf("message not acknowledged by broker (nack received)")
-		}
-		return nil
+	case err := <-confirmed:
+		return err
 	case <-publishCtx.Done():
+		tracker.forget(tag)
 		return fmt.Errorf("timed out waiting for broker confirmation: %w", publishCtx.Err())
 	}
 }
@@ -0,0 +1,272 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alfattd/category-service/internal/pkg/eventmeta"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// standIn plays the broker side of a confirm-mode channel: every publish is confirmed after
+// latency, in whatever order the timers fire. Messages whose id starts with "nack-" are nacked and
+// those routed with "unroutable" are returned before their ack, the way RabbitMQ does it.
+type standIn struct {
+	latency func() time.Duration
+
+	mu        sync.Mutex
+	published uint64
+	messages  []amqp.Publishing
+	closed    bool
+	confirms  chan amqp.Confirmation
+	returns   chan amqp.Return
+}
+
+func newStandIn(latency func() time.Duration) *standIn {
+	return &standIn{
+		latency:  latency,
+		confirms: make(chan amqp.Confirmation, confirmBuffer),
+		returns:  make(chan amqp.Return, confirmBuffer),
+	}
+}
+
+func (s *standIn) PublishWithContext(_ context.Context, _, key string, mandatory, _ bool, msg amqp.Publishing) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return amqp.ErrClosed
+	}
+
+	s.published++
+	s.messages = append(s.messages, msg)
+	tag := s.published
+
+	time.AfterFunc(s.latency(), func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.closed {
+			return
+		}
+		if mandatory && key == "unroutable" {
+			s.returns <- amqp.Return{MessageId: msg.MessageId}
+		}
+		s.confirms <- amqp.Confirmation{DeliveryTag: tag, Ack: !strings.HasPrefix(msg.MessageId, "nack-")}
+	})
+
+	return nil
+}
+
+func (s *standIn) GetNextPublishSeqNo() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.published + 1
+}
+
+func (s *standIn) IsClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func (s *standIn) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.confirms)
+		close(s.returns)
+	}
+	return nil
+}
+
+type openConn struct{}
+
+func (openConn) IsClosed() bool { return false }
+func (openConn) Close() error   { return nil }
+
+func newStandInPublisher(ch *standIn) *Publisher {
+	return &Publisher{
+		conn:      openConn{},
+		channel:   ch,
+		tracker:   newConfirmTracker(ch.confirms, ch.returns),
+		queue:     "category_events",
+		format:    FormatLegacy,
+		mandatory: true,
+		metrics:   newPublisherMetrics(),
+	}
+}
+
+func message(id string) amqp.Publishing {
+	return amqp.Publishing{Headers: amqp.Table{}, MessageId: id}
+}
+
+func randomLatency() time.Duration {
+	return time.Duration(rand.IntN(2000)) * time.Microsecond
+}
+
+func TestPublish_ConcurrentPublishesGetTheirOwnConfirms(t *testing.T) {
+	p := newStandInPublisher(newStandIn(randomLatency))
+
+	const n = 200
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			id := fmt.Sprintf("msg-%d", i)
+			if i%3 == 0 {
+				id = "nack-" + id
+			}
+			errs[i] = p.publish(context.Background(), "category_created", message(id))
+		})
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if i%3 == 0 {
+			assert.ErrorIs(t, err, errNacked, "message %d", i)
+		} else {
+			assert.NoError(t, err, "message %d", i)
+		}
+	}
+}
+
+func TestPublish_Unroutable_ReturnsErrUnroutableWithoutRetrying(t *testing.T) {
+	p := newStandInPublisher(newStandIn(randomLatency))
+	p.exchange, p.routingKey = "category_events", "unroutable"
+
+	start := time.Now()
+	err := p.publishWithRetry(context.Background(), "category_created", message("msg-1"))
+
+	assert.ErrorIs(t, err, ErrUnroutable)
+	assert.Less(t, time.Since(start), baseDelay, "an unroutable event should not be retried")
+
+	p.mandatory = false
+	assert.NoError(t, p.publish(context.Background(), "category_created", message("msg-2")))
+}
+
+func TestPublish_ChannelClosed_FailsWaitingPublishes(t *testing.T) {
+	ch := newStandIn(func() time.Duration { return time.Hour })
+	p := newStandInPublisher(ch)
+
+	done := make(chan error, 1)
+	go func() { done <- p.publish(context.Background(), "category_created", message("msg-1")) }()
+
+	require.Eventually(t, func() bool { return ch.GetNextPublishSeqNo() == 2 }, time.Second, time.Millisecond)
+	require.NoError(t, ch.Close())
+
+	select {
+	case err := <-done:
+		assert.True(t, errors.Is(err, errChannelClosed), "got %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("publish kept waiting after the channel closed")
+	}
+}
+
+func TestPublish_PreviousAndChangedFieldsByEvent(t *testing.T) {
+	before, after := testCategory("Phones", "phones"), testCategory("Mobile Phones", "mobile-phones")
+
+	publish := map[string]func(p *Publisher, ctx context.Context) error{
+		"created": func(p *Publisher, ctx context.Context) error { return p.PublishCategoryCreated(ctx, after) },
+		"updated": func(p *Publisher, ctx context.Context) error {
+			return p.PublishCategoryUpdated(ctx, before, after, []string{"name", "slug"})
+		},
+		"deleted": func(p *Publisher, ctx context.Context) error { return p.PublishCategoryDeleted(ctx, before) },
+	}
+
+	tests := []struct {
+		format string
+		event  string
+		// want holds the previous and changed_fields the payload carries; missing keys are omitted.
+		want map[string]string
+	}{
+		{FormatLegacy, "created", map[string]string{}},
+		{FormatLegacy, "updated", map[string]string{
+			"previous":       `{"name":"Phones","slug":"phones","parent_id":"p-1"}`,
+			"changed_fields": `["name","slug"]`,
+		}},
+		{FormatLegacy, "deleted", map[string]string{}},
+		{FormatStructured, "created", map[string]string{}},
+		{FormatStructured, "updated", map[string]string{
+			"previous": `{"id":"c-1","name":"Phones","slug":"phones","parent_id":"p-1","version":2,` +
+				`"created_at":"2026-01-01T00:00:00Z","updated_at":"2026-01-02T03:04:05Z"}`,
+			"changed_fields": `["name","slug"]`,
+		}},
+		// A deletion carries the last state the category had as its previous one.
+		{FormatStructured, "deleted", map[string]string{
+			"previous": `{"id":"c-1","name":"Phones","slug":"phones","parent_id":"p-1","version":2,` +
+				`"created_at":"2026-01-01T00:00:00Z","updated_at":"2026-01-02T03:04:05Z"}`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format+" "+tt.event, func(t *testing.T) {
+			ch := newStandIn(randomLatency)
+			p := newStandInPublisher(ch)
+			p.format = tt.format
+
+			require.NoError(t, publish[tt.event](p, eventmeta.WithContext(context.Background(), testMeta)))
+			require.Len(t, ch.messages, 1)
+
+			var payload map[string]json.RawMessage
+			require.NoError(t, json.Unmarshal(ch.messages[0].Body, &payload))
+			if tt.format == FormatStructured {
+				require.NoError(t, json.Unmarshal(payload["data"], &payload))
+			}
+
+			for _, field := range []string{"previous", "changed_fields"} {
+				if want, ok := tt.want[field]; ok {
+					assert.JSONEq(t, want, string(payload[field]), field)
+				} else {
+					assert.NotContains(t, payload, field)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkPublish measures confirmed publishes per second against a stand-in that confirms each
+// message after 500µs. With one publish in flight, as before tags were tracked, throughput is
+// bound by that latency; concurrent publishes now share it.
+func BenchmarkPublish(b *testing.B) {
+	for _, inFlight := range []int{1, 8, 64} {
+		b.Run(fmt.Sprintf("in-flight=%d", inFlight), func(b *testing.B) {
+			p := newStandInPublisher(newStandIn(func() time.Duration { return 500 * time.Microsecond }))
+
+			var (
+				wg   sync.WaitGroup
+				next = make(chan int)
+			)
+			for range inFlight {
+				wg.Go(func() {
+					for i := range next {
+						if err := p.publish(context.Background(), "category_created", message(fmt.Sprint(i))); err != nil {
+							b.Error(err)
+						}
+					}
+				})
+			}
+
+			b.ResetTimer()
+			for i := range b.N {
+				next <- i
+			}
+			close(next)
+			wg.Wait()
+
+			b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "msgs/s")
+		})
+	}
+}
@@ -1,40 +1,74 @@
 package rabbitmq
 
 import (
+	"context"
+	"errors"
 	"sync"
 
 	"github.com/alfattd/category-service/internal/domain"
-	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 type Publisher struct {
-	amqpURL   string
-	conn      *amqp.Connection
-	channel   *amqp.Channel
-	confirmCh chan amqp.Confirmation
-	queue     string
-	mu        sync.Mutex
+	amqpURL string
+	conn    connection
+	channel channel
+	tracker *confirmTracker
+	// queue is declared by the publisher unless it is empty; consumers then bind their own.
+	queue        string
+	exchange     string
+	exchangeKind string
+	routingKey   string
+	mandatory    bool
+	format       string
+	source       string
+	mu           sync.Mutex
+	metrics      *publisherMetrics
 }
 
 type categoryEvent struct {
-	ID   string `json:"id"`
-	Name string `json:"name,omitempty"`
-	Type string `json:"type"`
+	ID            string   `json:"id"`
+	Name          string   `json:"name,omitempty"`
+	Slug          string   `json:"slug,omitempty"`
+	ParentID      *string  `json:"parent_id,omitempty"`
+	OldParentID   *string  `json:"old_parent_id,omitempty"`
+	ChangedFields []string `json:"changed_fields,omitempty"`
+	// Previous holds the values an update started from.
+	Previous *categoryValues `json:"previous,omitempty"`
+	Type     string          `json:"type"`
+}
+
+type categoryValues struct {
+	Name     string  `json:"name"`
+	Slug     string  `json:"slug"`
+	ParentID *string `json:"parent_id"`
 }
 
 var _ domain.CategoryEventPublisher = (*Publisher)(nil)
 
-func NewPublisher(amqpURL, queueName string) (*Publisher, error) {
+func NewPublisher(amqpURL, queueName string, opts ...Option) (*Publisher, error) {
+	p := newPublisher(amqpURL, queueName, opts...)
+
+	if err := p.connect(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// newPublisher configures a publisher without connecting it.
+func newPublisher(amqpURL, queueName string, opts ...Option) *Publisher {
 	p := &Publisher{
 		amqpURL: amqpURL,
 		queue:   queueName,
+		format:  FormatLegacy,
+		metrics: newPublisherMetrics(),
 	}
 
-	if err := p.connect(); err != nil {
-		return nil, err
+	for _, opt := range opts {
+		opt(p)
 	}
 
-	return p, nil
+	return p
 }
 
 func (p *Publisher) Close() {
@@ -48,3 +82,15 @@ func (p *Publisher) Close() {
 		_ = p.conn.Close()
 	}
 }
+
+// Ping reports whether the broker connection and channel are open. It does not try to reconnect.
+func (p *Publisher) Ping(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.isConnected() {
+		return errors.New("rabbitmq connection is closed")
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package rabbitmq
+
+import (
+	"strings"
+
+	"github.com/alfattd/category-service/internal/domain"
+)
+
+// Exchange types a publisher can declare.
+const (
+	ExchangeTopic  = "topic"
+	ExchangeDirect = "direct"
+	ExchangeFanout = "fanout"
+)
+
+// DefaultRoutingKey routes category_created as category.created, and so on.
+const DefaultRoutingKey = "category.{event}"
+
+var eventTypes = []string{
+	domain.EventCategoryCreated,
+	domain.EventCategoryUpdated,
+	domain.EventCategoryDeleted,
+	domain.EventCategoryMoved,
+	domain.EventCategoryRestored,
+}
+
+// WithExchange publishes to the named exchange, declared durable with kind, instead of straight to
+// the queue. routingKey is a pattern in which {event} stands for the event without its category_
+// prefix, so "category.{event}" routes category_created as category.created.
+func WithExchange(name, kind, routingKey string) Option {
+	return func(p *Publisher) {
+		p.exchange = name
+		p.exchangeKind = kind
+		p.routingKey = routingKey
+	}
+}
+
+// WithMandatory decides whether an event no queue is bound for fails with ErrUnroutable or, which is
+// the default, is dropped by the broker.
+func WithMandatory(mandatory bool) Option {
+	return func(p *Publisher) {
+		p.mandatory = mandatory
+	}
+}
+
+// route returns the exchange and routing key an event of eventType is published with.
+// Without an exchange events go through the default one, which routes by queue name.
+func (p *Publisher) route(eventType string) (exchange, key string) {
+	if p.exchange == "" {
+		return "", p.queue
+	}
+	return p.exchange, strings.ReplaceAll(p.routingKey, "{event}", strings.TrimPrefix(eventType, "category_"))
+}
+
+// destination names where messages go, for spans.
+func (p *Publisher) destination() string {
+	if p.exchange == "" {
+		return p.queue
+	}
+	return p.exchange
+}
@@ -0,0 +1,44 @@
+package rabbitmq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoute(t *testing.T) {
+	tests := []struct {
+		name         string
+		exchange     string
+		routingKey   string
+		eventType    string
+		wantExchange string
+		wantKey      string
+	}{
+		{"no exchange routes by queue name", "", DefaultRoutingKey, "category_created", "", "category_events"},
+		{"default key created", "category_events", DefaultRoutingKey, "category_created", "category_events", "category.created"},
+		{"default key updated", "category_events", DefaultRoutingKey, "category_updated", "category_events", "category.updated"},
+		{"default key deleted", "category_events", DefaultRoutingKey, "category_deleted", "category_events", "category.deleted"},
+		{"default key moved", "category_events", DefaultRoutingKey, "category_moved", "category_events", "category.moved"},
+		{"default key restored", "category_events", DefaultRoutingKey, "category_restored", "category_events", "category.restored"},
+		{"custom pattern", "catalog", "shop.{event}.v1", "category_moved", "catalog", "shop.moved.v1"},
+		{"pattern repeating the event", "catalog", "{event}.{event}", "category_deleted", "catalog", "deleted.deleted"},
+		{"fixed key", "catalog", "categories", "category_restored", "catalog", "categories"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Publisher{queue: "category_events", exchange: tt.exchange, routingKey: tt.routingKey}
+
+			exchange, key := p.route(tt.eventType)
+
+			assert.Equal(t, tt.wantExchange, exchange)
+			assert.Equal(t, tt.wantKey, key)
+		})
+	}
+}
+
+func TestNewPublisher_MandatoryOnlyWhenAsked(t *testing.T) {
+	assert.False(t, newPublisher("amqp://localhost", "category_events").mandatory)
+	assert.True(t, newPublisher("amqp://localhost", "category_events", WithMandatory(true)).mandatory)
+}
@@ -0,0 +1,121 @@
+// Package ratelimit implements per-key token buckets.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Decision is the outcome of taking a token, with what the RateLimit-* headers report.
+type Decision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until the next token, zero when Allowed.
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter hands out up to burst tokens per key, refilled at rate tokens per second.
+//
+// A bucket that has been idle long enough to refill completely is indistinguishable from a
+// new one, so such buckets are dropped on a periodic sweep. Memory stays bounded by the number
+// of keys seen within one refill period rather than by every key ever seen.
+type Limiter struct {
+	rate  float64
+	burst int
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func New(rate float64, burst int) *Limiter {
+	return &Limiter{
+		rate:    rate,
+		burst:   burst,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from the bucket of key if one is available.
+func (l *Limiter) Allow(key string) Decision {
+	return l.decide(key, true)
+}
+
+// Peek reports what Allow would decide for key without taking a token.
+func (l *Limiter) Peek(key string) Decision {
+	return l.decide(key, false)
+}
+
+func (l *Limiter) decide(key string, take bool) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.burst), last: now}
+	}
+
+	b.tokens = math.Min(float64(l.burst), b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	d := Decision{Limit: l.burst}
+	if b.tokens >= 1 {
+		d.Allowed = true
+		if take {
+			b.tokens--
+		}
+	} else {
+		d.RetryAfter = l.duration(1 - b.tokens)
+	}
+
+	// A full bucket is what an unknown key gets anyway, so peeking does not have to keep it.
+	if take && !ok {
+		l.buckets[key] = b
+	}
+
+	d.Remaining = int(b.tokens)
+	d.Reset = l.duration(float64(l.burst) - b.tokens)
+
+	return d
+}
+
+// Len returns the number of buckets currently held.
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// sweep drops full buckets, at most once per refill period so Allow stays cheap.
+func (l *Limiter) sweep(now time.Time) {
+	full := l.duration(float64(l.burst))
+	if now.Sub(l.lastSweep) < full {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= full {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// duration returns how long it takes to refill n tokens.
+func (l *Limiter) duration(n float64) time.Duration {
+	return time.Duration(n / l.rate * float64(time.Second))
+}
@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// clock is a time source the test moves by hand.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time          { return c.t }
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestLimiter(rate float64, burst int) (*Limiter, *clock) {
+	c := &clock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := New(rate, burst)
+	l.now = c.now
+	return l, c
+}
+
+func TestAllow_SpendsTheBurstThenRefuses(t *testing.T) {
+	l, _ := newTestLimiter(1, 3)
+
+	for want := 2; want >= 0; want-- {
+		d := l.Allow("a")
+		assert.True(t, d.Allowed)
+		assert.Equal(t, 3, d.Limit)
+		assert.Equal(t, want, d.Remaining)
+		assert.Zero(t, d.RetryAfter)
+	}
+
+	d := l.Allow("a")
+	assert.False(t, d.Allowed)
+	assert.Equal(t, 0, d.Remaining)
+	assert.Equal(t, time.Second, d.RetryAfter)
+	assert.Equal(t, 3*time.Second, d.Reset)
+}
+
+func TestAllow_RefillsAtRate(t *testing.T) {
+	l, c := newTestLimiter(2, 2)
+	l.Allow("a")
+	l.Allow("a")
+
+	c.advance(250 * time.Millisecond)
+	d := l.Allow("a")
+	assert.False(t, d.Allowed, "half a token is not enough")
+	assert.Equal(t, 250*time.Millisecond, d.RetryAfter)
+
+	c.advance(250 * time.Millisecond)
+	assert.True(t, l.Allow("a").Allowed)
+
+	// Refilling stops at the burst, however long the bucket was idle.
+	c.advance(time.Hour)
+	assert.Equal(t, 1, l.Allow("a").Remaining)
+}
+
+func TestAllow_KeysHaveTheirOwnBuckets(t *testing.T) {
+	l, _ := newTestLimiter(1, 1)
+
+	assert.True(t, l.Allow("a").Allowed)
+	assert.False(t, l.Allow("a").Allowed)
+	assert.True(t, l.Allow("b").Allowed)
+}
+
+func TestPeek_DoesNotTakeATokenOrKeepABucket(t *testing.T) {
+	l, _ := newTestLimiter(1, 1)
+
+	assert.True(t, l.Peek("a").Allowed)
+	assert.Zero(t, l.Len())
+
+	l.Allow("a")
+	d := l.Peek("a")
+	assert.False(t, d.Allowed)
+	assert.Equal(t, time.Second, d.RetryAfter)
+	assert.Equal(t, 1, l.Len())
+}
+
+func TestSweep_DropsBucketsOnceTheyAreFull(t *testing.T) {
+	l, c := newTestLimiter(1, 10)
+
+	l.Allow("idle")
+	c.advance(5 * time.Second)
+	l.Allow("busy")
+	assert.Equal(t, 2, l.Len())
+
+	// "idle" is full again after 10s, "busy" is not.
+	c.advance(6 * time.Second)
+	l.Allow("other")
+	assert.Equal(t, 2, l.Len())
+	l.mu.Lock()
+	_, kept := l.buckets["idle"]
+	l.mu.Unlock()
+	assert.False(t, kept)
+}
+
+func TestSweep_RunsAtMostOncePerRefillPeriod(t *testing.T) {
+	l, c := newTestLimiter(1, 10)
+
+	l.Allow("a") // sweeps, as nothing has been swept yet
+	c.advance(10 * time.Second)
+	l.Allow("b") // sweeps again and drops "a"
+	assert.Equal(t, 1, l.Len())
+
+	c.advance(9 * time.Second)
+	l.Allow("c")
+	assert.Equal(t, 2, l.Len(), "too soon for another sweep")
+
+	c.advance(10 * time.Second)
+	l.Allow("d")
+	assert.Equal(t, 1, l.Len())
+}
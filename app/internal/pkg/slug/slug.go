@@ -0,0 +1,98 @@
+// Package slug turns names into URL path segments.
+package slug
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength leaves room for a collision suffix within the length the validator allows.
+const MaxLength = 50
+
+// Fallback is used for names that have nothing left after transliteration, such as names
+// written only in scripts without a table below.
+const Fallback = "category"
+
+// letters covers the Latin letters that do not decompose into a base letter and a mark,
+// and the Cyrillic and Greek alphabets.
+var letters = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'þ': "th", 'ł': "l", 'ı': "i", 'ħ': "h",
+
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'ґ': "g", 'д': "d", 'е': "e", 'є': "ye", 'ж': "zh", 'з': "z",
+	'и': "i", 'і': "i", 'ї': "yi", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p",
+	'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh",
+	'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i", 'κ': "k",
+	'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t",
+	'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// Make lowercases and transliterates name to ASCII, joining the words with hyphens.
+// "Home + Garden" becomes "home-garden" and "Café Déjà Vu" becomes "cafe-deja-vu".
+func Make(name string) string {
+	var b strings.Builder
+	pendingHyphen := false
+
+	write := func(s string) {
+		for _, r := range s {
+			if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+				if pendingHyphen && b.Len() > 0 {
+					b.WriteByte('-')
+				}
+				pendingHyphen = false
+				b.WriteRune(r)
+			} else {
+				pendingHyphen = true
+			}
+		}
+	}
+
+	// Decomposing splits "é" into "e" and a combining accent, which is then dropped.
+	for _, r := range norm.NFKD.String(strings.ToLower(name)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case r < unicode.MaxASCII:
+			write(string(r))
+		default:
+			if s, ok := letters[r]; ok {
+				write(s)
+			} else {
+				pendingHyphen = true
+			}
+		}
+	}
+
+	s := b.String()
+	if len(s) > MaxLength {
+		s = strings.TrimRight(s[:MaxLength], "-")
+	}
+	if s == "" {
+		return Fallback
+	}
+
+	return s
+}
+
+// Root strips trailing numbers like the suffixes WithSuffix adds, so every candidate for a base
+// has the root of the base: "route-66-2" and "route-66" both have the root "route".
+func Root(s string) string {
+	for {
+		i := strings.LastIndexByte(s, '-')
+		if i <= 0 || i == len(s)-1 || strings.Trim(s[i+1:], "0123456789") != "" {
+			return s
+		}
+		s = s[:i]
+	}
+}
+
+// WithSuffix returns the n-th candidate for base: base itself for n = 1, then base-2, base-3...
+func WithSuffix(base string, n int) string {
+	if n <= 1 {
+		return base
+	}
+	return base + "-" + strconv.Itoa(n)
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 )
 
+// Health reports that the process is up. It is also served as /health/live.
 func Health(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
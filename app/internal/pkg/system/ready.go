@@ -0,0 +1,129 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultCheckTimeout = 2 * time.Second
+
+// Checker probes one dependency. A failing critical checker makes the service not ready;
+// other failures are only reported.
+type Checker struct {
+	Name     string
+	Critical bool
+	Check    func(ctx context.Context) error
+	// Details, when set, is reported with the result whatever its status.
+	Details func() any
+}
+
+type checkResult struct {
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+	Details   any     `json:"details,omitempty"`
+}
+
+type readyResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks"`
+}
+
+// Readiness answers /health/ready by running every checker concurrently, each with its own timeout.
+type Readiness struct {
+	checkers     []Checker
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewReadiness(timeout time.Duration, checkers ...Checker) *Readiness {
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+
+	return &Readiness{checkers: checkers, timeout: timeout}
+}
+
+// Shutdown makes every following readiness check fail, so traffic drains before the server stops.
+func (rd *Readiness) Shutdown() {
+	rd.shuttingDown.Store(true)
+}
+
+func (rd *Readiness) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	resp := readyResponse{Status: "ready", Checks: make(map[string]checkResult, len(rd.checkers))}
+
+	if rd.shuttingDown.Load() {
+		resp.Status = "shutting_down"
+	} else {
+		var (
+			mu sync.Mutex
+			wg sync.WaitGroup
+		)
+
+		for _, c := range rd.checkers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				result := rd.run(r.Context(), c)
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				resp.Checks[c.Name] = result
+				if result.Status != "ok" && c.Critical {
+					resp.Status = "not_ready"
+				}
+			}()
+		}
+
+		wg.Wait()
+	}
+
+	status := http.StatusOK
+	if resp.Status != "ready" {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (rd *Readiness) run(ctx context.Context, c Checker) checkResult {
+	ctx, cancel := context.WithTimeout(ctx, rd.timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	// A checker that ignores ctx still cannot hold the probe past the timeout.
+	done := make(chan error, 1)
+	go func() { done <- c.Check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := checkResult{
+		Status:    "ok",
+		Critical:  c.Critical,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = "down"
+		result.Error = err.Error()
+	}
+	if c.Details != nil {
+		result.Details = c.Details()
+	}
+
+	return result
+}
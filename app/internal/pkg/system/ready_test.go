@@ -0,0 +1,174 @@
+package system_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alfattd/category-service/internal/pkg/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type readyBody struct {
+	Status string `json:"status"`
+	Checks map[string]struct {
+		Status   string          `json:"status"`
+		Critical bool            `json:"critical"`
+		Error    string          `json:"error"`
+		Details  json.RawMessage `json:"details"`
+	} `json:"checks"`
+}
+
+func probe(t *testing.T, rd *system.Readiness) (int, readyBody) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	rd.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var body readyBody
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return w.Code, body
+}
+
+func healthy(name string, critical bool) system.Checker {
+	return system.Checker{Name: name, Critical: critical, Check: func(context.Context) error { return nil }}
+}
+
+func failing(name string, critical bool, err error) system.Checker {
+	return system.Checker{Name: name, Critical: critical, Check: func(context.Context) error { return err }}
+}
+
+// stuck never returns on its own, not even when ctx is done, until the test is over.
+func stuck(t *testing.T, name string) system.Checker {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	return system.Checker{Name: name, Critical: true, Check: func(context.Context) error {
+		<-release
+		return nil
+	}}
+}
+
+func TestReadiness_AllHealthy_Ready(t *testing.T) {
+	rd := system.NewReadiness(time.Second,
+		healthy("postgres", true),
+		system.Checker{
+			Name:    "event_queue",
+			Check:   func(context.Context) error { return nil },
+			Details: func() any { return map[string]int{"depth": 3} },
+		},
+	)
+
+	code, body := probe(t, rd)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", body.Status)
+	require.Len(t, body.Checks, 2)
+	assert.Equal(t, "ok", body.Checks["postgres"].Status)
+	assert.True(t, body.Checks["postgres"].Critical)
+	assert.JSONEq(t, `{"depth":3}`, string(body.Checks["event_queue"].Details))
+}
+
+func TestReadiness_CriticalFailure_ListsFailingChecks(t *testing.T) {
+	rd := system.NewReadiness(time.Second,
+		healthy("postgres", true),
+		failing("rabbitmq", true, errors.New("connection refused")),
+		failing("outbox", false, errors.New("backlog of 500")),
+	)
+
+	code, body := probe(t, rd)
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "not_ready", body.Status)
+	assert.Equal(t, "ok", body.Checks["postgres"].Status)
+	assert.Equal(t, "down", body.Checks["rabbitmq"].Status)
+	assert.Equal(t, "connection refused", body.Checks["rabbitmq"].Error)
+	assert.Equal(t, "down", body.Checks["outbox"].Status)
+	assert.Equal(t, "backlog of 500", body.Checks["outbox"].Error)
+}
+
+func TestReadiness_NonCriticalFailure_StillReady(t *testing.T) {
+	rd := system.NewReadiness(time.Second,
+		healthy("postgres", true),
+		failing("outbox", false, errors.New("backlog of 500")),
+	)
+
+	code, body := probe(t, rd)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", body.Status)
+	assert.Equal(t, "down", body.Checks["outbox"].Status)
+	assert.False(t, body.Checks["outbox"].Critical)
+}
+
+func TestReadiness_TimesOutEachCheckerOnItsOwn(t *testing.T) {
+	const timeout = 100 * time.Millisecond
+
+	rd := system.NewReadiness(timeout,
+		healthy("postgres", true),
+		stuck(t, "rabbitmq"),
+		stuck(t, "redis"),
+		system.Checker{Name: "outbox", Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+	)
+
+	start := time.Now()
+	code, body := probe(t, rd)
+	elapsed := time.Since(start)
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "ok", body.Checks["postgres"].Status)
+	for _, name := range []string{"rabbitmq", "redis", "outbox"} {
+		assert.Equal(t, "down", body.Checks[name].Status, name)
+		assert.Equal(t, context.DeadlineExceeded.Error(), body.Checks[name].Error, name)
+	}
+
+	// The checkers run side by side, and ones ignoring ctx are abandoned at the timeout; one
+	// after the other they would take three timeouts.
+	assert.GreaterOrEqual(t, elapsed, timeout)
+	assert.Less(t, elapsed, 2*timeout)
+}
+
+func TestReadiness_ShuttingDown_Unavailable(t *testing.T) {
+	var calls atomic.Int32
+	rd := system.NewReadiness(time.Second, system.Checker{Name: "postgres", Critical: true, Check: func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}})
+
+	code, _ := probe(t, rd)
+	require.Equal(t, http.StatusOK, code)
+
+	rd.Shutdown()
+	code, body := probe(t, rd)
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "shutting_down", body.Status)
+	assert.Empty(t, body.Checks)
+	assert.Equal(t, int32(1), calls.Load(), "checks are skipped while shutting down")
+}
+
+func TestNewReadiness_DefaultsTimeout(t *testing.T) {
+	var deadline time.Duration
+	rd := system.NewReadiness(0, system.Checker{Name: "postgres", Check: func(ctx context.Context) error {
+		if d, ok := ctx.Deadline(); ok {
+			deadline = time.Until(d)
+		}
+		return nil
+	}})
+
+	probe(t, rd)
+
+	assert.Greater(t, deadline, time.Second)
+	assert.LessOrEqual(t, deadline, 2*time.Second)
+}
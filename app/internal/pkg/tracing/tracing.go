@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// RequestIDKey is the span attribute holding the X-Request-ID of the request.
+const RequestIDKey = "request.id"
+
+var propagator = propagation.TraceContext{}
+
+// Setup installs the global tracer provider and W3C trace context propagator. With ExporterNone
+// spans are not recorded, but incoming trace context is still passed on to outgoing messages.
+// The OTLP exporter is configured through the standard OTEL_EXPORTER_OTLP_* variables.
+func Setup(ctx context.Context, exporter, serviceName, serviceVersion string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagator)
+
+	var (
+		exp sdktrace.SpanExporter
+		err error
+	)
+
+	switch exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exp, err = stdouttrace.New()
+	case ExporterOTLP:
+		exp, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", exporter, err)
+	}
+
+	res := resource.NewSchemaless(
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(serviceVersion),
+	)
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+// TraceParent returns the W3C traceparent of the span in ctx, or an empty string without one.
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
+
+// WithTraceParent makes the span described by traceParent the remote parent of spans started from the returned context.
+func WithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier{"traceparent": traceParent})
+}
@@ -0,0 +1,93 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alfattd/category-service/internal/pkg/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// restoreGlobals puts back the tracer provider and propagator that Setup replaces.
+func restoreGlobals(t *testing.T) {
+	t.Helper()
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+}
+
+func TestSetup_NoneRecordsNothingButPropagates(t *testing.T) {
+	for _, exporter := range []string{tracing.ExporterNone, ""} {
+		t.Run("exporter="+exporter, func(t *testing.T) {
+			restoreGlobals(t)
+
+			shutdown, err := tracing.Setup(context.Background(), exporter, "category-service", "test")
+			require.NoError(t, err)
+
+			_, span := otel.Tracer("test").Start(context.Background(), "op")
+			assert.False(t, span.IsRecording())
+			span.End()
+
+			// Incoming trace context still reaches outgoing headers.
+			ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier{"traceparent": traceParent})
+			carrier := propagation.MapCarrier{}
+			otel.GetTextMapPropagator().Inject(ctx, carrier)
+			assert.Equal(t, traceParent, carrier.Get("traceparent"))
+
+			assert.NoError(t, shutdown(context.Background()))
+		})
+	}
+}
+
+func TestSetup_StdoutInstallsSDKProvider(t *testing.T) {
+	restoreGlobals(t)
+
+	shutdown, err := tracing.Setup(context.Background(), tracing.ExporterStdout, "category-service", "test")
+	require.NoError(t, err)
+
+	assert.IsType(t, &sdktrace.TracerProvider{}, otel.GetTracerProvider())
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	restoreGlobals(t)
+
+	_, err := tracing.Setup(context.Background(), "jaeger", "category-service", "test")
+
+	assert.Error(t, err)
+}
+
+func TestTraceParent_RoundTrip(t *testing.T) {
+	ctx := tracing.WithTraceParent(context.Background(), traceParent)
+
+	assert.Equal(t, traceParent, tracing.TraceParent(ctx))
+	assert.Equal(t, context.Background(), tracing.WithTraceParent(context.Background(), ""))
+	assert.Empty(t, tracing.TraceParent(context.Background()))
+}
+
+func TestTraceParent_ParentsSpansStartedFromIt(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	ctx := tracing.WithTraceParent(context.Background(), traceParent)
+	ctx, span := tp.Tracer("test").Start(ctx, "publish")
+	span.End()
+
+	spans := rec.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+
+	// The traceparent passed on names the new span, not the incoming one.
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+spans[0].SpanContext().SpanID().String()+"-01", tracing.TraceParent(ctx))
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/lib/pq"
+)
+
+type postgresAPIKeyRepo struct {
+	db *sql.DB
+}
+
+func NewPostgresAPIKeyRepo(db *sql.DB) domain.APIKeyRepository {
+	return &postgresAPIKeyRepo{db: db}
+}
+
+const apiKeyColumns = `id, name, prefix, key_hash, scopes, expires_at, created_at, last_used_at, revoked_at`
+
+func (r *postgresAPIKeyRepo) Create(ctx context.Context, k *domain.APIKey) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO api_keys (id, name, prefix, key_hash, scopes, expires_at, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
+		k.ID, k.Name, k.Prefix, k.Hash, pq.Array(k.Scopes), k.ExpiresAt, k.CreatedAt)
+	if err != nil {
+		return mapPostgresError(err)
+	}
+
+	return nil
+}
+
+func (r *postgresAPIKeyRepo) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+
+	k, err := scanAPIKey(conn(ctx, r.db).QueryRowContext(ctx, query, hash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return k, nil
+}
+
+func (r *postgresAPIKeyRepo) List(ctx context.Context) ([]*domain.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at DESC, id DESC`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*domain.APIKey, 0)
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, k)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *postgresAPIKeyRepo) Revoke(ctx context.Context, id string, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $1) WHERE id = $2`
+
+	return r.exec(ctx, query, at, id)
+}
+
+func (r *postgresAPIKeyRepo) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	query := `UPDATE api_keys SET last_used_at = GREATEST(last_used_at, $1) WHERE id = $2`
+
+	return r.exec(ctx, query, at, id)
+}
+
+func (r *postgresAPIKeyRepo) exec(ctx context.Context, query string, args ...any) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return mapPostgresError(err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	var (
+		k                              domain.APIKey
+		expiresAt, lastUsed, revokedAt sql.NullTime
+	)
+
+	err := row.Scan(&k.ID, &k.Name, &k.Prefix, &k.Hash, pq.Array(&k.Scopes), &expiresAt, &k.CreatedAt, &lastUsed, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	k.ExpiresAt = nullTime(expiresAt)
+	k.LastUsedAt = nullTime(lastUsed)
+	k.RevokedAt = nullTime(revokedAt)
+
+	return &k, nil
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -2,8 +2,13 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/pkg/names"
 )
 
 func (r *postgresCategoryRepo) Create(ctx context.Context, c *domain.Category) error {
@@ -12,15 +17,17 @@ func (r *postgresCategoryRepo) Create(ctx context.Context, c *domain.Category) e
 	}
 
 	query := `
-	INSERT INTO categories (id, name, created_at, updated_at)
-	VALUES ($1, $2, $3, $4)
+	INSERT INTO categories (id, name, name_key, slug, parent_id, version, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, 1, $6, $7)
 	`
 
-	_, err := r.db.ExecContext(ctx, query, c.ID, c.Name, c.CreatedAt, c.UpdatedAt)
+	_, err := r.conn(ctx).ExecContext(ctx, query, c.ID, c.Name, names.Key(c.Name), c.Slug, c.ParentID, c.CreatedAt, c.UpdatedAt)
 	if err != nil {
-		return mapPostgresError(err)
+		return r.duplicateOf(ctx, c, err)
 	}
 
+	c.Version = 1
+
 	return nil
 }
 
@@ -32,13 +39,17 @@ func (r *postgresCategoryRepo) Update(ctx context.Context, c *domain.Category) e
 	query := `
 	UPDATE categories
 	SET name = $1,
-		updated_at = $2
-	WHERE id = $3
+		name_key = $2,
+		slug = $3,
+		parent_id = $4,
+		updated_at = $5,
+		version = version + 1
+	WHERE id = $6 AND version = $7 AND deleted_at IS NULL
 	`
 
-	res, err := r.db.ExecContext(ctx, query, c.Name, c.UpdatedAt, c.ID)
+	res, err := r.conn(ctx).ExecContext(ctx, query, c.Name, names.Key(c.Name), c.Slug, c.ParentID, c.UpdatedAt, c.ID, c.Version)
 	if err != nil {
-		return mapPostgresError(err)
+		return r.duplicateOf(ctx, c, err)
 	}
 
 	rows, err := res.RowsAffected()
@@ -47,32 +58,303 @@ func (r *postgresCategoryRepo) Update(ctx context.Context, c *domain.Category) e
 	}
 
 	if rows == 0 {
-		return domain.ErrNotFound
+		return r.staleOrMissing(ctx, c.ID)
 	}
 
+	c.Version++
+
 	return nil
 }
 
-func (r *postgresCategoryRepo) Delete(ctx context.Context, id string) error {
+func (r *postgresCategoryRepo) Delete(ctx context.Context, id string, version int64) (*domain.Category, error) {
 	if err := ctx.Err(); err != nil {
-		return err
+		return nil, err
 	}
 
-	query := `DELETE FROM categories WHERE id = $1`
+	var deleted *domain.Category
+
+	err := withinTx(ctx, r.db, func(ctx context.Context) error {
+		tx := r.conn(ctx)
+
+		// Children are created and moved under this lock, so none can appear after the check below.
+		if err := r.LockHierarchy(ctx); err != nil {
+			return err
+		}
+
+		c, err := lockCategory(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
 
-	res, err := r.db.ExecContext(ctx, query, id)
+		if version != 0 && c.Version != version {
+			return domain.ErrPreconditionFailed
+		}
+
+		var hasChildren bool
+		err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM categories WHERE parent_id = $1 AND deleted_at IS NULL)
+		`, id).Scan(&hasChildren)
+		if err != nil {
+			return err
+		}
+
+		if hasChildren {
+			return domain.ErrHasChildren
+		}
+
+		_, err = tx.ExecContext(ctx, `
+		UPDATE categories
+		SET deleted_at = $1,
+			version = version + 1
+		WHERE id = $2
+		`, time.Now(), id)
+		if err != nil {
+			return err
+		}
+
+		deleted = c
+		return nil
+	})
 	if err != nil {
-		return mapPostgresError(err)
+		return nil, err
 	}
 
-	rows, err := res.RowsAffected()
+	return deleted, nil
+}
+
+func (r *postgresCategoryRepo) Purge(ctx context.Context, id string, version int64) (*domain.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var purged *domain.Category
+
+	err := withinTx(ctx, r.db, func(ctx context.Context) error {
+		tx := r.conn(ctx)
+
+		c, err := lockCategory(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+
+		if version != 0 && c.Version != version {
+			return domain.ErrPreconditionFailed
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, id); err != nil {
+			if isForeignKeyViolation(err) {
+				return domain.ErrHasChildren
+			}
+			return mapPostgresError(err)
+		}
+
+		purged = c
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return purged, nil
+}
+
+func (r *postgresCategoryRepo) Restore(ctx context.Context, id string) (*domain.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var restored *domain.Category
+
+	err := withinTx(ctx, r.db, func(ctx context.Context) error {
+		tx := r.conn(ctx)
+
+		// Taken like Delete does, so the parent cannot be deleted between the check below and the commit.
+		if err := r.LockHierarchy(ctx); err != nil {
+			return err
+		}
+
+		c, err := lockCategory(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+
+		if c.DeletedAt == nil {
+			return domain.ErrNotDeleted
+		}
+
+		if c.ParentID != nil {
+			var parentAlive bool
+			err := tx.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM categories WHERE id = $1 AND deleted_at IS NULL)
+			`, *c.ParentID).Scan(&parentAlive)
+			if err != nil {
+				return err
+			}
+
+			if !parentAlive {
+				return fmt.Errorf("%w: parent %s is deleted", domain.ErrInvalidParent, *c.ParentID)
+			}
+		}
+
+		c.DeletedAt = nil
+		c.UpdatedAt = time.Now()
+		c.Version++
+
+		_, err = tx.ExecContext(ctx, `
+		UPDATE categories
+		SET deleted_at = NULL,
+			updated_at = $1,
+			version = version + 1
+		WHERE id = $2
+		`, c.UpdatedAt, id)
+		if err != nil {
+			return r.duplicateOf(ctx, c, err)
+		}
+
+		restored = c
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
+// lockCategory reads a category with a row lock, treating soft-deleted rows as missing unless includeDeleted is set.
+func lockCategory(ctx context.Context, q querier, id string, includeDeleted bool) (*domain.Category, error) {
+	c, err := scanCategory(q.QueryRowContext(ctx, `
+	SELECT id, name, slug, parent_id, version, created_at, updated_at, deleted_at
+	FROM categories
+	WHERE id = $1 AND ($2 OR deleted_at IS NULL)
+	FOR UPDATE
+	`, id, includeDeleted))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// staleOrMissing tells apart the two reasons a versioned write can match no rows.
+func (r *postgresCategoryRepo) staleOrMissing(ctx context.Context, id string) error {
+	var exists bool
+	err := r.conn(ctx).QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM categories WHERE id = $1 AND deleted_at IS NULL)`, id).Scan(&exists)
 	if err != nil {
 		return err
 	}
 
-	if rows == 0 {
-		return domain.ErrNotFound
+	if exists {
+		return domain.ErrPreconditionFailed
 	}
 
-	return nil
+	return domain.ErrNotFound
+}
+
+// Move re-parents a category, and with it the whole subtree below it, in a single transaction.
+// Hierarchy changes are serialized with an advisory lock so concurrent moves cannot form a cycle.
+func (r *postgresCategoryRepo) Move(ctx context.Context, id string, parentID *string, maxDepth int) (*domain.CategoryMove, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var move *domain.CategoryMove
+
+	err := withinTx(ctx, r.db, func(ctx context.Context) error {
+		tx := r.conn(ctx)
+
+		if err := r.LockHierarchy(ctx); err != nil {
+			return err
+		}
+
+		c, err := lockCategory(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+
+		parentDepth := 0
+		if parentID != nil {
+			if parentDepth, err = checkMoveTarget(ctx, tx, id, *parentID); err != nil {
+				return err
+			}
+		}
+
+		height, err := subtreeHeight(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if parentDepth+height > maxDepth {
+			return fmt.Errorf("%w: hierarchy is limited to %d levels", domain.ErrMaxDepthExceeded, maxDepth)
+		}
+
+		before := *c
+		move = &domain.CategoryMove{Category: c, Before: &before, OldParentID: c.ParentID}
+		c.ParentID = parentID
+		c.UpdatedAt = time.Now()
+		c.Version++
+
+		_, err = tx.ExecContext(ctx, `
+		UPDATE categories
+		SET parent_id = $1,
+			updated_at = $2,
+			version = version + 1
+		WHERE id = $3
+		`, c.ParentID, c.UpdatedAt, c.ID)
+		if err != nil {
+			return mapPostgresError(err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return move, nil
+}
+
+func (r *postgresCategoryRepo) LockHierarchy(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, err := r.conn(ctx).ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('categories_hierarchy'))`)
+	return err
+}
+
+// checkMoveTarget returns the depth of the new parent, rejecting parents that are id itself or one of its descendants.
+func checkMoveTarget(ctx context.Context, q querier, id, parentID string) (int, error) {
+	query := `
+	WITH RECURSIVE chain AS (
+		SELECT id, parent_id
+		FROM categories
+		WHERE id = $1 AND deleted_at IS NULL
+		UNION ALL
+		SELECT c.id, c.parent_id
+		FROM categories c
+		JOIN chain ON c.id = chain.parent_id
+	) CYCLE id SET is_cycle USING path
+	SELECT COUNT(*), COUNT(*) FILTER (WHERE id = $2)
+	FROM chain
+	WHERE NOT is_cycle
+	`
+
+	var depth, cycles int
+	if err := q.QueryRowContext(ctx, query, parentID, id).Scan(&depth, &cycles); err != nil {
+		return 0, err
+	}
+
+	if depth == 0 {
+		return 0, fmt.Errorf("%w: parent %s does not exist", domain.ErrInvalidParent, parentID)
+	}
+
+	if cycles > 0 {
+		return 0, fmt.Errorf("%w: category cannot be moved under itself or its descendant", domain.ErrInvalidParent)
+	}
+
+	return depth, nil
 }
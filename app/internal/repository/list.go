@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alfattd/category-service/internal/domain"
+)
+
+// sortColumns whitelists what a listing can be ordered by, so a sort never reaches SQL verbatim.
+// Names are compared byte by byte rather than by the database collation, which is the code point
+// order the memory repository sorts them in and does not change with where the database runs.
+var sortColumns = map[string]string{
+	"name":       `name COLLATE "C"`,
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+type listOrder struct {
+	field  string
+	column string
+	desc   bool
+}
+
+func orderBy(sort domain.CategorySort) (listOrder, error) {
+	if sort == "" {
+		sort = domain.DefaultCategorySort
+	}
+
+	column, ok := sortColumns[sort.Field()]
+	if !ok || !sort.Valid() {
+		return listOrder{}, fmt.Errorf("unsupported category sort %q", sort)
+	}
+
+	return listOrder{field: sort.Field(), column: column, desc: sort.Desc()}, nil
+}
+
+func (o listOrder) clause() string {
+	dir := "ASC"
+	if o.desc {
+		dir = "DESC"
+	}
+	return fmt.Sprintf("%s %s, id %s", o.column, dir, dir)
+}
+
+// listQuery collects WHERE conditions and their positional arguments.
+type listQuery struct {
+	conditions []string
+	args       []any
+}
+
+func filterCategories(f domain.CategoryFilter) *listQuery {
+	q := &listQuery{}
+
+	if !f.IncludeDeleted {
+		q.conditions = append(q.conditions, "deleted_at IS NULL")
+	}
+
+	if f.Query != "" {
+		q.conditions = append(q.conditions, fmt.Sprintf(`name ILIKE '%%' || %s || '%%'`, q.arg(escapeLike(f.Query))))
+	}
+
+	// The columns hold UTC without a time zone, which a bound time with another offset is not converted to.
+	if !f.CreatedAfter.IsZero() {
+		q.conditions = append(q.conditions, "created_at > "+q.arg(f.CreatedAfter.UTC()))
+	}
+
+	if !f.UpdatedAfter.IsZero() {
+		q.conditions = append(q.conditions, "updated_at > "+q.arg(f.UpdatedAfter.UTC()))
+	}
+
+	return q
+}
+
+// after restricts the query to rows past the cursor in the given order.
+func (q *listQuery) after(order listOrder, c *domain.CategoryCursor) error {
+	if c.Sort.Field() != order.field || c.Sort.Desc() != order.desc {
+		return fmt.Errorf("cursor is for sort %q", c.Sort)
+	}
+
+	var value any = c.Time
+	if order.field == "name" {
+		value = c.Name
+	}
+
+	op := ">"
+	if order.desc {
+		op = "<"
+	}
+
+	q.conditions = append(q.conditions, fmt.Sprintf("(%s, id) %s (%s, %s)", order.column, op, q.arg(value), q.arg(c.ID)))
+
+	return nil
+}
+
+func (q *listQuery) arg(v any) string {
+	q.args = append(q.args, v)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+func (q *listQuery) where() string {
+	if len(q.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(q.conditions, " AND ")
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
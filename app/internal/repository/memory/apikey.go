@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+)
+
+type apiKeyRepo struct {
+	mu   sync.RWMutex
+	keys map[string]domain.APIKey
+}
+
+func NewAPIKeyRepo() domain.APIKeyRepository {
+	return &apiKeyRepo{keys: make(map[string]domain.APIKey)}
+}
+
+func (r *apiKeyRepo) Create(ctx context.Context, k *domain.APIKey) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.keys {
+		if existing.ID == k.ID || existing.Hash == k.Hash {
+			return domain.ErrDuplicate
+		}
+	}
+
+	r.keys[k.ID] = cloneAPIKey(k)
+
+	return nil
+}
+
+func (r *apiKeyRepo) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, k := range r.keys {
+		if k.Hash == hash {
+			cp := cloneAPIKey(&k)
+			return &cp, nil
+		}
+	}
+
+	return nil, domain.ErrNotFound
+}
+
+func (r *apiKeyRepo) List(ctx context.Context) ([]*domain.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*domain.APIKey, 0, len(r.keys))
+	for _, k := range r.keys {
+		cp := cloneAPIKey(&k)
+		result = append(result, &cp)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+		return result[i].ID > result[j].ID
+	})
+
+	return result, nil
+}
+
+func (r *apiKeyRepo) Revoke(ctx context.Context, id string, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.keys[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+
+	if k.RevokedAt == nil {
+		k.RevokedAt = &at
+		r.keys[id] = k
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepo) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.keys[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+
+	if k.LastUsedAt == nil || at.After(*k.LastUsedAt) {
+		k.LastUsedAt = &at
+		r.keys[id] = k
+	}
+
+	return nil
+}
+
+func cloneAPIKey(k *domain.APIKey) domain.APIKey {
+	cp := *k
+	cp.Scopes = slices.Clone(k.Scopes)
+	cp.ExpiresAt = cloneTime(k.ExpiresAt)
+	cp.LastUsedAt = cloneTime(k.LastUsedAt)
+	cp.RevokedAt = cloneTime(k.RevokedAt)
+	return cp
+}
@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/repository/memory"
+	"github.com/alfattd/category-service/internal/repository/repositorytest"
+)
+
+func TestMemoryAPIKeyRepo_Contract(t *testing.T) {
+	repositorytest.RunAPIKeyRepository(t, func(t *testing.T) domain.APIKeyRepository {
+		return memory.NewAPIKeyRepo()
+	})
+}
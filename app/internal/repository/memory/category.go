@@ -0,0 +1,598 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/pkg/names"
+)
+
+type categoryRepo struct {
+	mu         sync.RWMutex
+	categories map[string]domain.Category
+	// formerSlugs maps slugs categories used to have to their category.
+	formerSlugs map[string]string
+}
+
+// NewCategoryRepo returns a thread-safe CategoryRepository that keeps everything in memory.
+// It mirrors the Postgres implementation and is meant for local runs and tests. It has no
+// transactions: LockHierarchy does nothing, and atomic batches are refused with 501 because
+// there is nothing to roll back with. Names are ordered by code point, as Postgres orders them
+// with COLLATE "C".
+func NewCategoryRepo() domain.CategoryRepository {
+	return &categoryRepo{categories: make(map[string]domain.Category), formerSlugs: make(map[string]string)}
+}
+
+func (r *categoryRepo) Create(ctx context.Context, c *domain.Category) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.categories[c.ID]; ok {
+		return domain.ErrDuplicate
+	}
+
+	if err := r.checkWrite(c); err != nil {
+		return err
+	}
+
+	c.Version = 1
+	r.categories[c.ID] = clone(c)
+
+	return nil
+}
+
+func (r *categoryRepo) Update(ctx context.Context, c *domain.Category) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.live(c.ID)
+	if !ok {
+		return domain.ErrNotFound
+	}
+
+	if existing.Version != c.Version {
+		return domain.ErrPreconditionFailed
+	}
+
+	if err := r.checkWrite(c); err != nil {
+		return err
+	}
+
+	c.Version++
+	r.categories[c.ID] = clone(c)
+
+	return nil
+}
+
+func (r *categoryRepo) Delete(ctx context.Context, id string, version int64) (*domain.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.live(id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	if version != 0 && existing.Version != version {
+		return nil, domain.ErrPreconditionFailed
+	}
+
+	for _, c := range r.categories {
+		if c.ParentID != nil && *c.ParentID == id && c.DeletedAt == nil {
+			return nil, domain.ErrHasChildren
+		}
+	}
+
+	deleted := cloneOut(existing)
+
+	now := time.Now()
+	existing.DeletedAt = &now
+	existing.Version++
+	r.categories[id] = existing
+
+	return deleted, nil
+}
+
+func (r *categoryRepo) Purge(ctx context.Context, id string, version int64) (*domain.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.categories[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	if version != 0 && existing.Version != version {
+		return nil, domain.ErrPreconditionFailed
+	}
+
+	for _, c := range r.categories {
+		if c.ParentID != nil && *c.ParentID == id {
+			return nil, domain.ErrHasChildren
+		}
+	}
+
+	delete(r.categories, id)
+	for slug, owner := range r.formerSlugs {
+		if owner == id {
+			delete(r.formerSlugs, slug)
+		}
+	}
+
+	return cloneOut(existing), nil
+}
+
+func (r *categoryRepo) Restore(ctx context.Context, id string) (*domain.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.categories[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	if c.DeletedAt == nil {
+		return nil, domain.ErrNotDeleted
+	}
+
+	if c.ParentID != nil {
+		if _, ok := r.live(*c.ParentID); !ok {
+			return nil, fmt.Errorf("%w: parent %s is deleted", domain.ErrInvalidParent, *c.ParentID)
+		}
+	}
+
+	if err := r.checkWrite(&c); err != nil {
+		return nil, err
+	}
+
+	c.DeletedAt = nil
+	c.UpdatedAt = time.Now()
+	c.Version++
+	r.categories[id] = c
+
+	return cloneOut(c), nil
+}
+
+func (r *categoryRepo) GetByID(ctx context.Context, id string) (*domain.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.live(id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	return cloneOut(c), nil
+}
+
+func (r *categoryRepo) GetBySlug(ctx context.Context, slug string) (*domain.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, c := range r.categories {
+		if c.Slug == slug && c.DeletedAt == nil {
+			return cloneOut(c), nil
+		}
+	}
+
+	if c, ok := r.live(r.formerSlugs[slug]); ok {
+		return cloneOut(c), nil
+	}
+
+	return nil, domain.ErrNotFound
+}
+
+func (r *categoryRepo) ListSlugs(ctx context.Context, base string) (map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matches := func(slug string) bool {
+		return slug == base || strings.HasPrefix(slug, base+"-")
+	}
+
+	slugs := make(map[string]string)
+	for slug, id := range r.formerSlugs {
+		if matches(slug) {
+			slugs[slug] = id
+		}
+	}
+	for id, c := range r.categories {
+		if matches(c.Slug) {
+			slugs[c.Slug] = id
+		}
+	}
+
+	return slugs, nil
+}
+
+// LockSlug is a no-op, like LockHierarchy: every write checks and takes its slug at once.
+func (r *categoryRepo) LockSlug(ctx context.Context, root string) error {
+	return ctx.Err()
+}
+
+func (r *categoryRepo) AddFormerSlug(ctx context.Context, id, slug string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.categories[id]; !ok {
+		return domain.ErrInvalidParent
+	}
+
+	r.formerSlugs[slug] = id
+
+	return nil
+}
+
+func (r *categoryRepo) List(ctx context.Context, p domain.PaginationParams) ([]*domain.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	less, err := listOrder(p.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	all := r.filter(p.Filter)
+	r.mu.RUnlock()
+
+	sort.Slice(all, func(i, j int) bool { return less(all[i], all[j]) })
+
+	return paginate(all, (p.Page-1)*p.Limit, p.Limit), nil
+}
+
+func (r *categoryRepo) ListAfter(ctx context.Context, p domain.PaginationParams, after *domain.CategoryCursor) ([]*domain.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	less, err := listOrder(p.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	all := r.filter(p.Filter)
+	r.mu.RUnlock()
+
+	sort.Slice(all, func(i, j int) bool { return less(all[i], all[j]) })
+
+	offset := 0
+	if after != nil {
+		sortBy := p.Sort
+		if sortBy == "" {
+			sortBy = domain.DefaultCategorySort
+		}
+		if after.Sort != sortBy {
+			return nil, fmt.Errorf("cursor is for sort %q", after.Sort)
+		}
+
+		last := &domain.Category{ID: after.ID, Name: after.Name, CreatedAt: after.Time, UpdatedAt: after.Time}
+		offset = sort.Search(len(all), func(i int) bool {
+			return less(last, all[i])
+		})
+	}
+
+	return paginate(all, offset, p.Limit), nil
+}
+
+func (r *categoryRepo) Count(ctx context.Context, f domain.CategoryFilter) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.filter(f)), nil
+}
+
+func (r *categoryRepo) ListChildren(ctx context.Context, parentID string) ([]*domain.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*domain.Category, 0)
+	for _, c := range r.categories {
+		if c.ParentID != nil && *c.ParentID == parentID && c.DeletedAt == nil {
+			result = append(result, cloneOut(c))
+		}
+	}
+
+	sortByName(result)
+
+	return result, nil
+}
+
+func (r *categoryRepo) ListAncestors(ctx context.Context, id string) ([]*domain.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.ancestors(id), nil
+}
+
+func (r *categoryRepo) ListAll(ctx context.Context) ([]*domain.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	all := r.filter(domain.CategoryFilter{})
+	r.mu.RUnlock()
+
+	sortByName(all)
+
+	return all, nil
+}
+
+func (r *categoryRepo) SubtreeHeight(ctx context.Context, id string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.live(id); !ok {
+		return 0, domain.ErrNotFound
+	}
+
+	return r.height(id), nil
+}
+
+// LockHierarchy is a no-op. Without transactions it has nothing to hold a lock for; instead every
+// write checks and changes the hierarchy at once under the repository mutex.
+func (r *categoryRepo) LockHierarchy(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (r *categoryRepo) Move(ctx context.Context, id string, parentID *string, maxDepth int) (*domain.CategoryMove, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.live(id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	parentDepth := 0
+	if parentID != nil {
+		if _, ok := r.live(*parentID); !ok {
+			return nil, fmt.Errorf("%w: parent %s does not exist", domain.ErrInvalidParent, *parentID)
+		}
+
+		chain := append(r.ancestors(*parentID), cloneOut(r.categories[*parentID]))
+		for _, a := range chain {
+			if a.ID == id {
+				return nil, fmt.Errorf("%w: category cannot be moved under itself or its descendant", domain.ErrInvalidParent)
+			}
+		}
+		parentDepth = len(chain)
+	}
+
+	if parentDepth+r.height(id) > maxDepth {
+		return nil, fmt.Errorf("%w: hierarchy is limited to %d levels", domain.ErrMaxDepthExceeded, maxDepth)
+	}
+
+	move := &domain.CategoryMove{Before: cloneOut(c), OldParentID: c.ParentID}
+
+	c.ParentID = cloneString(parentID)
+	c.UpdatedAt = time.Now()
+	c.Version++
+	r.categories[id] = c
+
+	move.Category = cloneOut(c)
+
+	return move, nil
+}
+
+// checkWrite enforces the constraints the Postgres schema has: unique name keys and slugs, and an existing parent.
+func (r *categoryRepo) checkWrite(c *domain.Category) error {
+	key := names.Key(c.Name)
+
+	for id, existing := range r.categories {
+		if id == c.ID || existing.DeletedAt != nil {
+			continue
+		}
+		if names.Key(existing.Name) == key {
+			return &domain.DuplicateError{Field: domain.FieldName, ID: id}
+		}
+		if existing.Slug == c.Slug {
+			return &domain.DuplicateError{Field: domain.FieldSlug, ID: id}
+		}
+	}
+
+	if c.ParentID != nil {
+		if _, ok := r.categories[*c.ParentID]; !ok {
+			return domain.ErrInvalidParent
+		}
+	}
+
+	return nil
+}
+
+// live returns the category unless it is missing or soft-deleted.
+func (r *categoryRepo) live(id string) (domain.Category, bool) {
+	c, ok := r.categories[id]
+	if !ok || c.DeletedAt != nil {
+		return domain.Category{}, false
+	}
+	return c, true
+}
+
+func (r *categoryRepo) ancestors(id string) []*domain.Category {
+	result := make([]*domain.Category, 0)
+
+	c, ok := r.live(id)
+	for ok && c.ParentID != nil && len(result) <= len(r.categories) {
+		if c, ok = r.categories[*c.ParentID]; ok {
+			result = append([]*domain.Category{cloneOut(c)}, result...)
+		}
+	}
+
+	return result
+}
+
+func (r *categoryRepo) height(id string) int {
+	height := 1
+	for _, c := range r.categories {
+		if c.ParentID != nil && *c.ParentID == id {
+			height = max(height, r.height(c.ID)+1)
+		}
+	}
+	return height
+}
+
+func (r *categoryRepo) filter(f domain.CategoryFilter) []*domain.Category {
+	query := strings.ToLower(f.Query)
+
+	result := make([]*domain.Category, 0, len(r.categories))
+	for _, c := range r.categories {
+		if c.DeletedAt != nil && !f.IncludeDeleted {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(c.Name), query) {
+			continue
+		}
+		if !f.CreatedAfter.IsZero() && !c.CreatedAt.After(f.CreatedAfter) {
+			continue
+		}
+		if !f.UpdatedAfter.IsZero() && !c.UpdatedAt.After(f.UpdatedAfter) {
+			continue
+		}
+		result = append(result, cloneOut(c))
+	}
+	return result
+}
+
+func paginate(all []*domain.Category, offset, limit int) []*domain.Category {
+	if offset >= len(all) {
+		return make([]*domain.Category, 0)
+	}
+	end := min(offset+limit, len(all))
+	return all[offset:end]
+}
+
+// listOrder returns the ordering for a listing sort, breaking ties by id like the Postgres listing
+// does. strings.Compare orders names by code point, as COLLATE "C" does there.
+func listOrder(sortBy domain.CategorySort) (func(a, b *domain.Category) bool, error) {
+	if sortBy == "" {
+		sortBy = domain.DefaultCategorySort
+	}
+
+	if !sortBy.Valid() {
+		return nil, fmt.Errorf("unsupported category sort %q", sortBy)
+	}
+
+	compare := func(a, b *domain.Category) int {
+		switch sortBy.Field() {
+		case "name":
+			return strings.Compare(a.Name, b.Name)
+		case "updated_at":
+			return a.UpdatedAt.Compare(b.UpdatedAt)
+		default:
+			return a.CreatedAt.Compare(b.CreatedAt)
+		}
+	}
+
+	return func(a, b *domain.Category) bool {
+		c := compare(a, b)
+		if c == 0 {
+			c = strings.Compare(a.ID, b.ID)
+		}
+		if sortBy.Desc() {
+			return c > 0
+		}
+		return c < 0
+	}, nil
+}
+
+func sortByName(categories []*domain.Category) {
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Name != categories[j].Name {
+			return categories[i].Name < categories[j].Name
+		}
+		return categories[i].ID < categories[j].ID
+	})
+}
+
+func clone(c *domain.Category) domain.Category {
+	cp := *c
+	cp.ParentID = cloneString(c.ParentID)
+	cp.DeletedAt = cloneTime(c.DeletedAt)
+	return cp
+}
+
+func cloneOut(c domain.Category) *domain.Category {
+	cp := clone(&c)
+	return &cp
+}
+
+func cloneString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := *s
+	return &v
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := *t
+	return &v
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/alfattd/category-service/internal/domain"
+)
+
+type revisionRepo struct {
+	mu        sync.RWMutex
+	revisions map[string][]domain.CategoryRevision
+}
+
+func NewRevisionRepo() domain.RevisionRepository {
+	return &revisionRepo{revisions: make(map[string][]domain.CategoryRevision)}
+}
+
+func (r *revisionRepo) Add(ctx context.Context, rev *domain.CategoryRevision) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.revisions[rev.CategoryID] {
+		if existing.ID == rev.ID {
+			return domain.ErrDuplicate
+		}
+	}
+
+	r.revisions[rev.CategoryID] = append(r.revisions[rev.CategoryID], cloneRevision(rev))
+
+	return nil
+}
+
+func (r *revisionRepo) ListByCategory(ctx context.Context, categoryID string, p domain.PaginationParams) ([]*domain.CategoryRevision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := r.revisions[categoryID]
+	result := make([]*domain.CategoryRevision, 0)
+
+	// Stored oldest first, so walk backwards from the offset.
+	for i := len(all) - 1 - (p.Page-1)*p.Limit; i >= 0 && len(result) < p.Limit; i-- {
+		rev := cloneRevision(&all[i])
+		result = append(result, &rev)
+	}
+
+	return result, nil
+}
+
+func (r *revisionRepo) CountByCategory(ctx context.Context, categoryID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.revisions[categoryID]), nil
+}
+
+func cloneRevision(rev *domain.CategoryRevision) domain.CategoryRevision {
+	cp := *rev
+	if rev.Before != nil {
+		cp.Before = cloneOut(*rev.Before)
+	}
+	if rev.After != nil {
+		cp.After = cloneOut(*rev.After)
+	}
+	return cp
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/pkg/requestid"
+	"github.com/alfattd/category-service/internal/pkg/tracing"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type postgresOutboxRepo struct {
+	db *sql.DB
+}
+
+func NewPostgresOutboxRepo(db *sql.DB) domain.OutboxRepository {
+	return &postgresOutboxRepo{db: db}
+}
+
+func (r *postgresOutboxRepo) Add(ctx context.Context, events ...domain.CategoryEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO outbox (id, event_type, category_id, payload, request_id, trace_parent, created_at, next_attempt_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+	`
+
+	now := time.Now()
+	reqID := requestid.FromContext(ctx)
+	traceParent := tracing.TraceParent(ctx)
+
+	for _, e := range events {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to marshal outbox event: %w", err)
+		}
+
+		if _, err := conn(ctx, r.db).ExecContext(ctx, query, uuid.NewString(), e.Type, e.Category.ID, payload, reqID, traceParent, now); err != nil {
+			return mapPostgresError(err)
+		}
+	}
+
+	return nil
+}
+
+// Claim leases messages by moving their next attempt past the lease, in a transaction of its own
+// that ends before anything is published. Claims are serialized so that one relay never misses a
+// lease another has not committed yet.
+func (r *postgresOutboxRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query := `
+	WITH claimed AS (
+		UPDATE outbox
+		SET next_attempt_at = $2
+		WHERE seq IN (
+			SELECT o.seq
+			FROM outbox o
+			WHERE o.sent_at IS NULL AND o.next_attempt_at <= $1
+			AND NOT EXISTS (
+				SELECT 1
+				FROM outbox earlier
+				WHERE earlier.category_id = o.category_id
+				AND earlier.seq < o.seq
+				AND earlier.sent_at IS NULL
+				AND earlier.next_attempt_at > $1
+			)
+			ORDER BY o.seq
+			LIMIT $3
+		)
+		RETURNING seq, id, payload, request_id, trace_parent, attempts, created_at
+	)
+	SELECT id, payload, request_id, trace_parent, attempts, created_at
+	FROM claimed
+	ORDER BY seq
+	`
+
+	var result []*domain.OutboxMessage
+
+	err := withinTx(ctx, r.db, func(ctx context.Context) error {
+		if _, err := conn(ctx, r.db).ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('outbox_claim'))`); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		rows, err := conn(ctx, r.db).QueryContext(ctx, query, now, now.Add(lease), limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		result = make([]*domain.OutboxMessage, 0)
+
+		for rows.Next() {
+			var (
+				m       domain.OutboxMessage
+				payload []byte
+			)
+			if err := rows.Scan(&m.ID, &payload, &m.RequestID, &m.TraceParent, &m.Attempts, &m.CreatedAt); err != nil {
+				return err
+			}
+			if err := json.Unmarshal(payload, &m.Event); err != nil {
+				return fmt.Errorf("failed to unmarshal outbox message %s: %w", m.ID, err)
+			}
+			result = append(result, &m)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *postgresOutboxRepo) MarkSent(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	query := `
+	UPDATE outbox
+	SET sent_at = $1,
+		attempts = attempts + 1,
+		last_error = NULL
+	WHERE id = $2
+	`
+
+	return r.exec(ctx, query, time.Now(), id)
+}
+
+func (r *postgresOutboxRepo) MarkFailed(ctx context.Context, id string, cause error, retryAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	query := `
+	UPDATE outbox
+	SET attempts = attempts + 1,
+		last_error = $1,
+		next_attempt_at = $2
+	WHERE id = $3
+	`
+
+	return r.exec(ctx, query, cause.Error(), retryAt, id)
+}
+
+func (r *postgresOutboxRepo) Release(ctx context.Context, ids ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	query := `
+	UPDATE outbox
+	SET next_attempt_at = $1
+	WHERE id = ANY($2) AND sent_at IS NULL
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), pq.Array(ids))
+	return mapPostgresError(err)
+}
+
+func (r *postgresOutboxRepo) DeleteSent(ctx context.Context, before time.Time, limit int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	query := `
+	DELETE FROM outbox
+	WHERE seq IN (
+		SELECT seq
+		FROM outbox
+		WHERE sent_at < $1
+		ORDER BY seq
+		LIMIT $2
+	)
+	`
+
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, before, limit)
+	if err != nil {
+		return 0, mapPostgresError(err)
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (r *postgresOutboxRepo) exec(ctx context.Context, query string, args ...any) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return mapPostgresError(err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 
 	"github.com/alfattd/category-service/internal/domain"
 )
@@ -14,15 +15,12 @@ func (r *postgresCategoryRepo) GetByID(ctx context.Context, id string) (*domain.
 	}
 
 	query := `
-	SELECT id, name, created_at, updated_at
+	SELECT id, name, slug, parent_id, version, created_at, updated_at, deleted_at
 	FROM categories
-	WHERE id = $1
+	WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	row := r.db.QueryRowContext(ctx, query, id)
-
-	var c domain.Category
-	err := row.Scan(&c.ID, &c.Name, &c.CreatedAt, &c.UpdatedAt)
+	c, err := scanCategory(r.conn(ctx).QueryRowContext(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrNotFound
@@ -30,7 +28,7 @@ func (r *postgresCategoryRepo) GetByID(ctx context.Context, id string) (*domain.
 		return nil, err
 	}
 
-	return &c, nil
+	return c, nil
 }
 
 func (r *postgresCategoryRepo) List(ctx context.Context, p domain.PaginationParams) ([]*domain.Category, error) {
@@ -38,48 +36,185 @@ func (r *postgresCategoryRepo) List(ctx context.Context, p domain.PaginationPara
 		return nil, err
 	}
 
+	order, err := orderBy(p.Sort)
+	if err != nil {
+		return nil, err
+	}
+
 	offset := (p.Page - 1) * p.Limit
+	q := filterCategories(p.Filter)
 
-	query := `
-	SELECT id, name, created_at, updated_at
+	query := fmt.Sprintf(`
+	SELECT id, name, slug, parent_id, version, created_at, updated_at, deleted_at
 	FROM categories
-	ORDER BY created_at DESC
-	LIMIT $1 OFFSET $2
-	`
+	%s
+	ORDER BY %s
+	LIMIT %s OFFSET %s
+	`, q.where(), order.clause(), q.arg(p.Limit), q.arg(offset))
 
-	rows, err := r.db.QueryContext(ctx, query, p.Limit, offset)
+	rows, err := r.conn(ctx).QueryContext(ctx, query, q.args...)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	result := make([]*domain.Category, 0)
+	return scanCategories(rows)
+}
+
+func (r *postgresCategoryRepo) ListAfter(ctx context.Context, p domain.PaginationParams, after *domain.CategoryCursor) ([]*domain.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	order, err := orderBy(p.Sort)
+	if err != nil {
+		return nil, err
+	}
 
-	for rows.Next() {
-		var c domain.Category
-		if err := rows.Scan(&c.ID, &c.Name, &c.CreatedAt, &c.UpdatedAt); err != nil {
+	q := filterCategories(p.Filter)
+	if after != nil {
+		if err := q.after(order, after); err != nil {
 			return nil, err
 		}
-		result = append(result, &c)
 	}
 
-	if err := rows.Err(); err != nil {
+	query := fmt.Sprintf(`
+	SELECT id, name, slug, parent_id, version, created_at, updated_at, deleted_at
+	FROM categories
+	%s
+	ORDER BY %s
+	LIMIT %s
+	`, q.where(), order.clause(), q.arg(p.Limit))
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, q.args...)
+	if err != nil {
 		return nil, err
 	}
 
-	return result, nil
+	return scanCategories(rows)
 }
 
-func (r *postgresCategoryRepo) Count(ctx context.Context) (int, error) {
+func (r *postgresCategoryRepo) Count(ctx context.Context, f domain.CategoryFilter) (int, error) {
 	if err := ctx.Err(); err != nil {
 		return 0, err
 	}
 
+	q := filterCategories(f)
+
 	var total int
-	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM categories`).Scan(&total)
+	err := r.conn(ctx).QueryRowContext(ctx, `SELECT COUNT(*) FROM categories `+q.where(), q.args...).Scan(&total)
 	if err != nil {
 		return 0, err
 	}
 
 	return total, nil
 }
+
+func (r *postgresCategoryRepo) ListChildren(ctx context.Context, parentID string) ([]*domain.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query := `
+	SELECT id, name, slug, parent_id, version, created_at, updated_at, deleted_at
+	FROM categories
+	WHERE parent_id = $1 AND deleted_at IS NULL
+	ORDER BY name COLLATE "C", id
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanCategories(rows)
+}
+
+// ListAncestors returns the ancestors of the category, starting from the root.
+// Should the parents ever form a cycle, the walk stops where it comes round again.
+func (r *postgresCategoryRepo) ListAncestors(ctx context.Context, id string) ([]*domain.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query := `
+	WITH RECURSIVE ancestors AS (
+		SELECT p.id, p.name, p.slug, p.parent_id, p.version, p.created_at, p.updated_at, p.deleted_at, 1 AS distance
+		FROM categories c
+		JOIN categories p ON p.id = c.parent_id
+		WHERE c.id = $1 AND c.deleted_at IS NULL
+		UNION ALL
+		SELECT p.id, p.name, p.slug, p.parent_id, p.version, p.created_at, p.updated_at, p.deleted_at, a.distance + 1
+		FROM ancestors a
+		JOIN categories p ON p.id = a.parent_id
+	) CYCLE id SET is_cycle USING path
+	SELECT id, name, slug, parent_id, version, created_at, updated_at, deleted_at
+	FROM ancestors
+	WHERE NOT is_cycle
+	ORDER BY distance DESC
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanCategories(rows)
+}
+
+func (r *postgresCategoryRepo) ListAll(ctx context.Context) ([]*domain.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query := `
+	SELECT id, name, slug, parent_id, version, created_at, updated_at, deleted_at
+	FROM categories
+	WHERE deleted_at IS NULL
+	ORDER BY name COLLATE "C", id
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanCategories(rows)
+}
+
+// SubtreeHeight returns the number of levels in the subtree rooted at id, counting id itself.
+func (r *postgresCategoryRepo) SubtreeHeight(ctx context.Context, id string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return subtreeHeight(ctx, r.conn(ctx), id)
+}
+
+// subtreeHeight does not descend into a category it has already visited.
+func subtreeHeight(ctx context.Context, q querier, id string) (int, error) {
+	query := `
+	WITH RECURSIVE subtree AS (
+		SELECT id, 1 AS level
+		FROM categories
+		WHERE id = $1 AND deleted_at IS NULL
+		UNION ALL
+		SELECT c.id, s.level + 1
+		FROM categories c
+		JOIN subtree s ON c.parent_id = s.id
+	) CYCLE id SET is_cycle USING path
+	SELECT COALESCE(MAX(level), 0)
+	FROM subtree
+	WHERE NOT is_cycle
+	`
+
+	var height int
+	if err := q.QueryRowContext(ctx, query, id).Scan(&height); err != nil {
+		return 0, err
+	}
+
+	if height == 0 {
+		return 0, domain.ErrNotFound
+	}
+
+	return height, nil
+}
@@ -1,11 +1,13 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 
 	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/pkg/names"
 	"github.com/lib/pq"
 )
 
@@ -17,15 +19,108 @@ func NewPostgresCategoryRepo(db *sql.DB) domain.CategoryRepository {
 	return &postgresCategoryRepo{db: db}
 }
 
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (r *postgresCategoryRepo) conn(ctx context.Context) querier {
+	return conn(ctx, r.db)
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCategory(row rowScanner) (*domain.Category, error) {
+	var (
+		c         domain.Category
+		parentID  sql.NullString
+		deletedAt sql.NullTime
+	)
+
+	if err := row.Scan(&c.ID, &c.Name, &c.Slug, &parentID, &c.Version, &c.CreatedAt, &c.UpdatedAt, &deletedAt); err != nil {
+		return nil, err
+	}
+
+	if parentID.Valid {
+		c.ParentID = &parentID.String
+	}
+
+	if deletedAt.Valid {
+		c.DeletedAt = &deletedAt.Time
+	}
+
+	return &c, nil
+}
+
+func scanCategories(rows *sql.Rows) ([]*domain.Category, error) {
+	defer rows.Close()
+
+	result := make([]*domain.Category, 0)
+
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 func mapPostgresError(err error) error {
 	var pgErr *pq.Error
 	if errors.As(err, &pgErr) {
 		switch pgErr.Code {
 		case "23505":
 			return domain.ErrDuplicate
+		case "23503":
+			return domain.ErrInvalidParent
 		default:
 			return fmt.Errorf("postgres error %s: %w", pgErr.Code, err)
 		}
 	}
 	return err
 }
+
+// duplicateOf maps the error writing c failed with and, when c was refused for its name or slug, names
+// the live category that holds it. The failed statement aborted any transaction, so the lookup runs
+// outside it; a category created earlier in the same transaction is not found there and a plain
+// ErrDuplicate is returned.
+func (r *postgresCategoryRepo) duplicateOf(ctx context.Context, c *domain.Category, err error) error {
+	var pgErr *pq.Error
+	if !errors.As(err, &pgErr) {
+		return mapPostgresError(err)
+	}
+
+	var field, query, value string
+	switch pgErr.Constraint {
+	case "idx_categories_name_key_live":
+		field, value = domain.FieldName, names.Key(c.Name)
+		query = `SELECT id FROM categories WHERE name_key = $1 AND id <> $2 AND deleted_at IS NULL`
+	case "idx_categories_slug_live":
+		field, value = domain.FieldSlug, c.Slug
+		query = `SELECT id FROM categories WHERE slug = $1 AND id <> $2 AND deleted_at IS NULL`
+	default:
+		return mapPostgresError(err)
+	}
+
+	var id string
+	if r.db.QueryRowContext(ctx, query, value, c.ID).Scan(&id) != nil {
+		return domain.ErrDuplicate
+	}
+
+	return &domain.DuplicateError{Field: field, ID: id}
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pq.Error
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
@@ -7,12 +7,19 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strings"
 	"testing"
 	"time"
+	"unicode"
 
 	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/pkg/names"
+	"github.com/alfattd/category-service/internal/pkg/requestid"
+	"github.com/alfattd/category-service/internal/pkg/slug"
 	"github.com/alfattd/category-service/internal/repository"
-	_ "github.com/lib/pq"
+	"github.com/alfattd/category-service/internal/repository/repositorytest"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
@@ -75,17 +82,42 @@ func TestMain(m *testing.M) {
 }
 
 func runMigrations(db *sql.DB) error {
+	migrationFiles, err := listMigrations()
+	if err != nil {
+		return err
+	}
+
+	for _, migrationFile := range migrationFiles {
+		if err := execMigration(context.Background(), db, migrationFile); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func listMigrations() ([]string, error) {
 	_, filename, _, _ := runtime.Caller(0)
 	projectRoot := filepath.Join(filepath.Dir(filename), "..", "..", "..")
-	migrationFile := filepath.Join(projectRoot, "postgres", "migrations", "000001_create_categories_table.up.sql")
+	migrationFiles, err := filepath.Glob(filepath.Join(projectRoot, "postgres", "migrations", "*.up.sql"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migration files: %w", err)
+	}
+	sort.Strings(migrationFiles)
+
+	return migrationFiles, nil
+}
 
+func execMigration(ctx context.Context, db interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}, migrationFile string) error {
 	migration, err := os.ReadFile(migrationFile)
 	if err != nil {
 		return fmt.Errorf("failed to read migration file %s: %w", migrationFile, err)
 	}
 
-	if _, err := db.Exec(string(migration)); err != nil {
-		return fmt.Errorf("failed to exec migration: %w", err)
+	if _, err := db.ExecContext(ctx, string(migration)); err != nil {
+		return fmt.Errorf("failed to exec migration %s: %w", migrationFile, err)
 	}
 
 	return nil
@@ -106,6 +138,7 @@ func newCategory(name string) *domain.Category {
 	return &domain.Category{
 		ID:        fmt.Sprintf("test-id-%d", now.UnixNano()),
 		Name:      name,
+		Slug:      slug.Make(name),
 		CreatedAt: now.Truncate(time.Microsecond),
 		UpdatedAt: now.Truncate(time.Microsecond),
 	}
@@ -216,7 +249,7 @@ func TestRepoDelete_Success(t *testing.T) {
 	cat := newCategory("Electronics")
 	require.NoError(t, repo.Create(ctx, cat))
 
-	err := repo.Delete(ctx, cat.ID)
+	_, err := repo.Delete(ctx, cat.ID, 0)
 	require.NoError(t, err)
 
 	_, err = repo.GetByID(ctx, cat.ID)
@@ -228,10 +261,89 @@ func TestRepoDelete_NotFound_ReturnsErrNotFound(t *testing.T) {
 	repo := repository.NewPostgresCategoryRepo(sharedDB)
 	ctx := context.Background()
 
-	err := repo.Delete(ctx, "id-yang-tidak-ada")
+	_, err := repo.Delete(ctx, "id-yang-tidak-ada", 0)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+// A child created while its parent is being deleted waits for the delete, like the service does
+// under the hierarchy lock, and then finds the parent gone instead of ending up under it.
+func TestRepoDelete_ConcurrentChildCreate_WaitsAndSeesParentDeleted(t *testing.T) {
+	cleanupTable(t)
+	repo := repository.NewPostgresCategoryRepo(sharedDB)
+	tx := repository.NewPostgresTransactor(sharedDB)
+	ctx := context.Background()
+
+	parent := newCategory("Electronics")
+	require.NoError(t, repo.Create(ctx, parent))
+
+	deleting := make(chan struct{})
+	release := make(chan struct{})
+	deleted := make(chan error, 1)
+	go func() {
+		deleted <- tx.WithinTx(ctx, func(ctx context.Context) error {
+			if _, err := repo.Delete(ctx, parent.ID, 0); err != nil {
+				return err
+			}
+			close(deleting)
+			<-release
+			return nil
+		})
+	}()
+	<-deleting
+
+	child := newCategory("Phones")
+	child.ParentID = &parent.ID
+	created := make(chan error, 1)
+	go func() {
+		created <- tx.WithinTx(ctx, func(ctx context.Context) error {
+			if err := repo.LockHierarchy(ctx); err != nil {
+				return err
+			}
+			if _, err := repo.GetByID(ctx, parent.ID); err != nil {
+				return err
+			}
+			return repo.Create(ctx, child)
+		})
+	}()
+
+	select {
+	case err := <-created:
+		t.Fatalf("child create did not wait for the delete: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-deleted)
+	assert.ErrorIs(t, <-created, domain.ErrNotFound)
+
+	_, err := repo.GetByID(ctx, child.ID)
 	assert.ErrorIs(t, err, domain.ErrNotFound)
 }
 
+func TestTransactor_WithinSnapshot_HidesLaterWrites(t *testing.T) {
+	cleanupTable(t)
+	repo := repository.NewPostgresCategoryRepo(sharedDB)
+	tx := repository.NewPostgresTransactor(sharedDB)
+	ctx := context.Background()
+
+	require.NoError(t, repo.Create(ctx, newCategory("Electronics")))
+
+	err := tx.WithinSnapshot(ctx, func(ctx context.Context) error {
+		before, err := repo.Count(ctx, domain.CategoryFilter{})
+		require.NoError(t, err)
+
+		// Written outside the snapshot, after its first read.
+		require.NoError(t, repo.Create(context.Background(), newCategory("Phones")))
+
+		after, err := repo.Count(ctx, domain.CategoryFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+
+		return repo.Create(ctx, newCategory("Books"))
+	})
+	assert.Error(t, err, "a snapshot is read-only")
+}
+
 // ─── GetByID ──────────────────────────────────────────────────────────────────
 
 func TestRepoGetByID_Success(t *testing.T) {
@@ -345,7 +457,7 @@ func TestRepoCount_ReturnsCorrectTotal(t *testing.T) {
 	repo := repository.NewPostgresCategoryRepo(sharedDB)
 	ctx := context.Background()
 
-	count, err := repo.Count(ctx)
+	count, err := repo.Count(ctx, domain.CategoryFilter{})
 	require.NoError(t, err)
 	assert.Equal(t, 0, count)
 
@@ -353,7 +465,306 @@ func TestRepoCount_ReturnsCorrectTotal(t *testing.T) {
 	require.NoError(t, repo.Create(ctx, newCategory("Books")))
 	require.NoError(t, repo.Create(ctx, newCategory("Fashion")))
 
-	count, err = repo.Count(ctx)
+	count, err = repo.Count(ctx, domain.CategoryFilter{})
 	require.NoError(t, err)
 	assert.Equal(t, 3, count)
 }
+
+// ─── Hierarchy ────────────────────────────────────────────────────────────────
+
+func newChildCategory(name string, parent *domain.Category) *domain.Category {
+	c := newCategory(name)
+	c.ParentID = &parent.ID
+	return c
+}
+
+func TestRepoListAll_ReturnsEveryCategory(t *testing.T) {
+	cleanupTable(t)
+	repo := repository.NewPostgresCategoryRepo(sharedDB)
+	ctx := context.Background()
+
+	electronics := newCategory("Electronics")
+	require.NoError(t, repo.Create(ctx, electronics))
+	require.NoError(t, repo.Create(ctx, newChildCategory("Phones", electronics)))
+	require.NoError(t, repo.Create(ctx, newCategory("Books")))
+
+	all, err := repo.ListAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+}
+
+// A cycle cannot be written through the repository, so the test makes one behind its back.
+func TestRepoHierarchyQueries_StopAtCycle(t *testing.T) {
+	cleanupTable(t)
+	repo := repository.NewPostgresCategoryRepo(sharedDB)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	a := newCategory("A")
+	require.NoError(t, repo.Create(ctx, a))
+	b := newChildCategory("B", a)
+	b.ID = a.ID + "-b"
+	require.NoError(t, repo.Create(ctx, b))
+
+	_, err := sharedDB.ExecContext(ctx, "UPDATE categories SET parent_id = $1 WHERE id = $2", b.ID, a.ID)
+	require.NoError(t, err)
+	t.Cleanup(func() { sharedDB.Exec("UPDATE categories SET parent_id = NULL") })
+
+	ancestors, err := repo.ListAncestors(ctx, b.ID)
+	require.NoError(t, err)
+	assert.Len(t, ancestors, 2)
+
+	height, err := repo.SubtreeHeight(ctx, a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, height)
+
+	_, err = repo.Move(ctx, a.ID, nil, 5)
+	require.NoError(t, err)
+}
+
+// ─── Outbox ───────────────────────────────────────────────────────────────────
+
+func cleanupOutbox(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		if _, err := sharedDB.Exec("DELETE FROM outbox"); err != nil {
+			t.Logf("failed to cleanup outbox: %v", err)
+		}
+	})
+}
+
+func TestOutbox_AddWithinTx_ClaimAndMarkSent(t *testing.T) {
+	cleanupTable(t)
+	cleanupOutbox(t)
+	repo := repository.NewPostgresCategoryRepo(sharedDB)
+	outbox := repository.NewPostgresOutboxRepo(sharedDB)
+	tx := repository.NewPostgresTransactor(sharedDB)
+	ctx := requestid.WithContext(context.Background(), "req-1")
+
+	cat := newCategory("Electronics")
+	err := tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := repo.Create(ctx, cat); err != nil {
+			return err
+		}
+		return outbox.Add(ctx, domain.CategoryEvent{Type: domain.EventCategoryCreated, Category: cat})
+	})
+	require.NoError(t, err)
+
+	messages, err := outbox.Claim(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, domain.EventCategoryCreated, messages[0].Event.Type)
+	assert.Equal(t, cat.ID, messages[0].Event.Category.ID)
+	assert.Equal(t, cat.Name, messages[0].Event.Category.Name)
+	assert.Equal(t, "req-1", messages[0].RequestID)
+
+	// A leased message is not claimed again.
+	again, err := outbox.Claim(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+
+	require.NoError(t, outbox.MarkSent(ctx, messages[0].ID))
+	require.NoError(t, outbox.Release(ctx, messages[0].ID))
+
+	again, err = outbox.Claim(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, again, "releasing a sent message does not make it due again")
+}
+
+func TestOutbox_RolledBackTx_LeavesNoMessage(t *testing.T) {
+	cleanupTable(t)
+	cleanupOutbox(t)
+	repo := repository.NewPostgresCategoryRepo(sharedDB)
+	outbox := repository.NewPostgresOutboxRepo(sharedDB)
+	tx := repository.NewPostgresTransactor(sharedDB)
+	ctx := context.Background()
+
+	existing := newCategory("Electronics")
+	require.NoError(t, repo.Create(ctx, existing))
+
+	dup := newCategory("Electronics")
+	dup.ID = "different-id"
+	err := tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := outbox.Add(ctx, domain.CategoryEvent{Type: domain.EventCategoryCreated, Category: dup}); err != nil {
+			return err
+		}
+		return repo.Create(ctx, dup)
+	})
+	require.ErrorIs(t, err, domain.ErrDuplicate)
+
+	var count int
+	require.NoError(t, sharedDB.QueryRow("SELECT COUNT(*) FROM outbox").Scan(&count))
+	assert.Equal(t, 0, count)
+}
+
+func TestOutbox_MarkFailed_HoldsBackLaterEventsOfTheCategory(t *testing.T) {
+	cleanupOutbox(t)
+	outbox := repository.NewPostgresOutboxRepo(sharedDB)
+	ctx := context.Background()
+
+	failing := &domain.Category{ID: "abc-123", Name: "Electronics"}
+	other := &domain.Category{ID: "def-456", Name: "Books"}
+
+	require.NoError(t, outbox.Add(ctx,
+		domain.CategoryEvent{Type: domain.EventCategoryCreated, Category: failing},
+		domain.CategoryEvent{Type: domain.EventCategoryCreated, Category: other},
+		domain.CategoryEvent{Type: domain.EventCategoryDeleted, Category: failing},
+	))
+
+	messages, err := outbox.Claim(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+
+	require.NoError(t, outbox.MarkFailed(ctx, messages[0].ID, assert.AnError, time.Now().Add(time.Hour)))
+	require.NoError(t, outbox.Release(ctx, messages[1].ID, messages[2].ID))
+
+	messages, err = outbox.Claim(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, other.ID, messages[0].Event.Category.ID)
+}
+
+func TestOutbox_DeleteSent_KeepsPendingAndRecentMessages(t *testing.T) {
+	cleanupOutbox(t)
+	outbox := repository.NewPostgresOutboxRepo(sharedDB)
+	ctx := context.Background()
+
+	cat := &domain.Category{ID: "abc-123", Name: "Electronics"}
+	require.NoError(t, outbox.Add(ctx,
+		domain.CategoryEvent{Type: domain.EventCategoryCreated, Category: cat},
+		domain.CategoryEvent{Type: domain.EventCategoryUpdated, Category: cat},
+		domain.CategoryEvent{Type: domain.EventCategoryDeleted, Category: cat},
+	))
+
+	messages, err := outbox.Claim(ctx, 2, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	require.NoError(t, outbox.MarkSent(ctx, messages[0].ID))
+	require.NoError(t, outbox.MarkSent(ctx, messages[1].ID))
+
+	n, err := outbox.DeleteSent(ctx, time.Now().Add(-time.Hour), 10)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	n, err = outbox.DeleteSent(ctx, time.Now().Add(time.Second), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	var count int
+	require.NoError(t, sharedDB.QueryRow("SELECT COUNT(*) FROM outbox").Scan(&count))
+	assert.Equal(t, 2, count)
+}
+
+// ─── Migrations ───────────────────────────────────────────────────────────────
+
+// Every character the Go functions treat specially goes through the SQL ones, between letters
+// so that it is neither trimmed nor left alone at the start of a name.
+func TestNameKeyMatchesGo(t *testing.T) {
+	var samples []string
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		if unicode.Is(unicode.Mn, r) || unicode.IsSpace(r) || unicode.ToLower(r) != r {
+			samples = append(samples, "A"+string(r)+"b")
+		}
+	}
+	samples = append(samples, "  Bóoks  and\tMore ", "ﬁle", "Ⅻ", "Straße", "İstanbul", "Ωmega", "e\u0301")
+
+	rows, err := sharedDB.Query(`
+	SELECT category_name_normalize(s), category_name_key(s)
+	FROM unnest($1::text[]) WITH ORDINALITY AS t (s, n)
+	ORDER BY n
+	`, pq.Array(samples))
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var i int
+	for rows.Next() {
+		var normalized, key string
+		require.NoError(t, rows.Scan(&normalized, &key))
+
+		assert.Equal(t, names.Normalize(samples[i]), normalized, "normalize %+q", samples[i])
+		assert.Equal(t, names.Key(samples[i]), key, "key %+q", samples[i])
+		i++
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, len(samples), i)
+}
+
+func TestMigrationNameKey_RefusesNamesThatBecomeEqual(t *testing.T) {
+	ctx := context.Background()
+
+	// The migrations run again in a schema of their own, on a connection nothing else uses.
+	conn, err := sharedDB.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, "CREATE SCHEMA name_key_migration; SET search_path TO name_key_migration")
+	require.NoError(t, err)
+	defer conn.ExecContext(ctx, "RESET search_path; DROP SCHEMA name_key_migration CASCADE")
+
+	migrationFiles, err := listMigrations()
+	require.NoError(t, err)
+
+	var nameKey string
+	for _, f := range migrationFiles {
+		if strings.Contains(filepath.Base(f), "_add_name_key_") {
+			nameKey = f
+			break
+		}
+		require.NoError(t, execMigration(ctx, conn, f))
+	}
+	require.NotEmpty(t, nameKey)
+
+	_, err = conn.ExecContext(ctx, `
+	INSERT INTO categories (id, name, slug, created_at, updated_at) VALUES
+		('a', 'Home  Garden', 'home-garden', now(), now()),
+		('b', 'Home Garden', 'home-garden-b', now(), now()),
+		('c', 'Cafe'||U&'\0301', 'cafe', now(), now()),
+		('d', 'Café', 'cafe-d', now(), now()),
+		('e', 'Books', 'books', now(), now())
+	`)
+	require.NoError(t, err)
+
+	err = execMigration(ctx, conn, nameKey)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "(a)")
+	assert.Contains(t, err.Error(), "(b)")
+	assert.Contains(t, err.Error(), "(c)")
+	assert.Contains(t, err.Error(), "(d)")
+	assert.NotContains(t, err.Error(), "(e)")
+
+	// Nothing was renamed.
+	var name string
+	require.NoError(t, conn.QueryRowContext(ctx, "SELECT name FROM categories WHERE id = 'b'").Scan(&name))
+	assert.Equal(t, "Home Garden", name)
+}
+
+// ─── Contract ─────────────────────────────────────────────────────────────────
+
+func TestPostgresCategoryRepo_Contract(t *testing.T) {
+	repositorytest.RunCategoryRepository(t, func(t *testing.T) domain.CategoryRepository {
+		cleanupTable(t)
+		return repository.NewPostgresCategoryRepo(sharedDB)
+	})
+}
+
+func TestPostgresRevisionRepo_Contract(t *testing.T) {
+	repositorytest.RunRevisionRepository(t, func(t *testing.T) domain.RevisionRepository {
+		t.Cleanup(func() {
+			if _, err := sharedDB.Exec("DELETE FROM category_revisions"); err != nil {
+				t.Logf("failed to cleanup category_revisions: %v", err)
+			}
+		})
+		return repository.NewPostgresRevisionRepo(sharedDB)
+	})
+}
+
+func TestPostgresAPIKeyRepo_Contract(t *testing.T) {
+	repositorytest.RunAPIKeyRepository(t, func(t *testing.T) domain.APIKeyRepository {
+		t.Cleanup(func() {
+			if _, err := sharedDB.Exec("DELETE FROM api_keys"); err != nil {
+				t.Logf("failed to cleanup api_keys: %v", err)
+			}
+		})
+		return repository.NewPostgresAPIKeyRepo(sharedDB)
+	})
+}
@@ -0,0 +1,145 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// APIKeyFactory returns an empty API key repository for a single test.
+type APIKeyFactory func(t *testing.T) domain.APIKeyRepository
+
+func RunAPIKeyRepository(t *testing.T, newRepo APIKeyFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo domain.APIKeyRepository)
+	}{
+		{"Create_ThenGetByHash", testAPIKeyCreateThenGetByHash},
+		{"Create_DuplicateHash_ReturnsErrDuplicate", testAPIKeyDuplicateHash},
+		{"GetByHash_Unknown_ReturnsErrNotFound", testAPIKeyUnknownHash},
+		{"List_NewestFirst", testAPIKeyList},
+		{"Revoke_KeepsFirstRevocation", testAPIKeyRevoke},
+		{"TouchLastUsed_OnlyMovesForward", testAPIKeyTouch},
+		{"UnknownID_ReturnsErrNotFound", testAPIKeyUnknownID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+func newAPIKey(name string) *domain.APIKey {
+	clock = clock.Add(time.Millisecond)
+	expires := clock.Add(24 * time.Hour)
+	return &domain.APIKey{
+		ID:        uuid.NewString(),
+		Name:      name,
+		Prefix:    "csk_" + name,
+		Hash:      uuid.NewString(),
+		Scopes:    []string{domain.RoleCategoryWrite},
+		ExpiresAt: &expires,
+		CreatedAt: clock,
+	}
+}
+
+func testAPIKeyCreateThenGetByHash(t *testing.T, repo domain.APIKeyRepository) {
+	key := newAPIKey("importer")
+	key.Scopes = []string{domain.RoleCategoryAdmin, domain.RoleCategoryWrite}
+	require.NoError(t, repo.Create(context.Background(), key))
+
+	got, err := repo.GetByHash(context.Background(), key.Hash)
+	require.NoError(t, err)
+
+	assert.Equal(t, key.ID, got.ID)
+	assert.Equal(t, "importer", got.Name)
+	assert.Equal(t, key.Prefix, got.Prefix)
+	assert.Equal(t, key.Scopes, got.Scopes)
+	require.NotNil(t, got.ExpiresAt)
+	assert.True(t, key.ExpiresAt.Equal(*got.ExpiresAt))
+	assert.True(t, key.CreatedAt.Equal(got.CreatedAt))
+	assert.Nil(t, got.LastUsedAt)
+	assert.Nil(t, got.RevokedAt)
+}
+
+func testAPIKeyDuplicateHash(t *testing.T, repo domain.APIKeyRepository) {
+	first := newAPIKey("first")
+	require.NoError(t, repo.Create(context.Background(), first))
+
+	second := newAPIKey("second")
+	second.Hash = first.Hash
+
+	assert.ErrorIs(t, repo.Create(context.Background(), second), domain.ErrDuplicate)
+}
+
+func testAPIKeyUnknownHash(t *testing.T, repo domain.APIKeyRepository) {
+	_, err := repo.GetByHash(context.Background(), "unknown")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testAPIKeyList(t *testing.T, repo domain.APIKeyRepository) {
+	ctx := context.Background()
+
+	empty, err := repo.List(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, empty)
+	assert.Empty(t, empty)
+
+	older := newAPIKey("older")
+	older.ExpiresAt = nil
+	newer := newAPIKey("newer")
+	require.NoError(t, repo.Create(ctx, older))
+	require.NoError(t, repo.Create(ctx, newer))
+
+	keys, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, "newer", keys[0].Name)
+	assert.Equal(t, "older", keys[1].Name)
+	assert.Nil(t, keys[1].ExpiresAt)
+}
+
+func testAPIKeyRevoke(t *testing.T, repo domain.APIKeyRepository) {
+	ctx := context.Background()
+	key := newAPIKey("importer")
+	require.NoError(t, repo.Create(ctx, key))
+
+	first := clock.Add(time.Minute)
+	require.NoError(t, repo.Revoke(ctx, key.ID, first))
+	require.NoError(t, repo.Revoke(ctx, key.ID, first.Add(time.Minute)))
+
+	got, err := repo.GetByHash(ctx, key.Hash)
+	require.NoError(t, err)
+	require.NotNil(t, got.RevokedAt)
+	assert.True(t, first.Equal(*got.RevokedAt))
+	assert.False(t, got.Active(first.Add(time.Second)))
+}
+
+func testAPIKeyTouch(t *testing.T, repo domain.APIKeyRepository) {
+	ctx := context.Background()
+	key := newAPIKey("importer")
+	require.NoError(t, repo.Create(ctx, key))
+
+	later := clock.Add(time.Hour)
+	require.NoError(t, repo.TouchLastUsed(ctx, key.ID, later))
+	require.NoError(t, repo.TouchLastUsed(ctx, key.ID, later.Add(-time.Minute)))
+
+	got, err := repo.GetByHash(ctx, key.Hash)
+	require.NoError(t, err)
+	require.NotNil(t, got.LastUsedAt)
+	assert.True(t, later.Equal(*got.LastUsedAt))
+}
+
+func testAPIKeyUnknownID(t *testing.T, repo domain.APIKeyRepository) {
+	ctx := context.Background()
+	id := uuid.NewString()
+
+	assert.ErrorIs(t, repo.Revoke(ctx, id, clock), domain.ErrNotFound)
+	assert.ErrorIs(t, repo.TouchLastUsed(ctx, id, clock), domain.ErrNotFound)
+}
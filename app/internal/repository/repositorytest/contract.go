@@ -0,0 +1,794 @@
+// Package repositorytest holds the behaviour every domain.CategoryRepository,
+// domain.RevisionRepository and domain.APIKeyRepository implementation has to
+// share, so the Postgres and in-memory versions are verified against the same
+// expectations.
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/pkg/slug"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns an empty repository for a single test.
+type Factory func(t *testing.T) domain.CategoryRepository
+
+func RunCategoryRepository(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo domain.CategoryRepository)
+	}{
+		{"Create_ThenGetByID", testCreateThenGetByID},
+		{"Create_DuplicateName_ReturnsErrDuplicate", testCreateDuplicateName},
+		{"Create_DuplicateID_ReturnsErrDuplicate", testCreateDuplicateID},
+		{"Create_UnknownParent_ReturnsErrInvalidParent", testCreateUnknownParent},
+		{"Update_Success", testUpdateSuccess},
+		{"Update_NotFound_ReturnsErrNotFound", testUpdateNotFound},
+		{"Update_DuplicateName_ReturnsErrDuplicate", testUpdateDuplicateName},
+		{"Update_StaleVersion_ReturnsErrPreconditionFailed", testUpdateStaleVersion},
+		{"Delete_Success", testDeleteSuccess},
+		{"Delete_NotFound_ReturnsErrNotFound", testDeleteNotFound},
+		{"Delete_StaleVersion_ReturnsErrPreconditionFailed", testDeleteStaleVersion},
+		{"Delete_HidesCategoryFromReads", testDeleteHidesCategory},
+		{"Delete_FreesNameAndIgnoresDeletedChildren", testDeleteFreesName},
+		{"Restore_Success", testRestoreSuccess},
+		{"Restore_Errors", testRestoreErrors},
+		{"Purge_RemovesCategory", testPurge},
+		{"Purge_WithChildren_ReturnsErrHasChildren", testPurgeWithChildren},
+		{"Delete_WithChildren_ReturnsErrHasChildren", testDeleteWithChildren},
+		{"GetByID_NotFound_ReturnsErrNotFound", testGetByIDNotFound},
+		{"List_OrderByCreatedAtDesc_Paginated", testListOrderAndPagination},
+		{"List_Empty_ReturnsEmptySlice", testListEmpty},
+		{"ListAfter_WalksEveryCategoryOnce", testListAfter},
+		{"List_SortAndFilter", testListSortAndFilter},
+		{"List_TimeFilterWithOffset_ComparesInstants", testListTimeFilterWithOffset},
+		{"ListAfter_SortedByName", testListAfterSortedByName},
+		{"ListAfter_SortedByName_InCodePointOrder", testListAfterSortedByNameCodePoints},
+		{"Count_RespectsFilter", testCountFilter},
+		{"Count_ReturnsTotal", testCount},
+		{"ListChildren_ReturnsDirectChildrenByName", testListChildren},
+		{"ListAncestors_ReturnsRootFirst", testListAncestors},
+		{"ListAll_ReturnsEveryCategoryByName", testListAll},
+		{"SubtreeHeight_CountsLevels", testSubtreeHeight},
+		{"Move_ReparentsSubtree", testMoveReparents},
+		{"Move_IntoDescendant_ReturnsErrInvalidParent", testMoveIntoDescendant},
+		{"Move_ExceedsMaxDepth_ReturnsErrMaxDepthExceeded", testMoveMaxDepth},
+		{"Create_DuplicateSlug_ReturnsErrDuplicate", testCreateDuplicateSlug},
+		{"GetBySlug_ResolvesCurrentAndFormerSlugs", testGetBySlug},
+		{"ListSlugs_ReturnsCurrentAndFormerWithSuffixes", testListSlugs},
+		{"Purge_DropsFormerSlugs", testPurgeDropsFormerSlugs},
+		{"Create_NameDifferingInCaseOrAccents_ReturnsDuplicateError", testCreateNameKeyDuplicate},
+		{"Update_NameDifferingInCaseOnly_Success", testUpdateNameCase},
+		{"Restore_NameTakenInOtherCase_ReturnsDuplicateError", testRestoreNameKeyDuplicate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+var clock = time.Now().UTC().Truncate(time.Microsecond)
+
+// newCategory returns a category whose timestamps strictly increase between calls,
+// so ordering assertions do not depend on the wall clock resolution.
+func newCategory(name string) *domain.Category {
+	clock = clock.Add(time.Millisecond)
+	return &domain.Category{
+		ID:        uuid.NewString(),
+		Name:      name,
+		Slug:      slug.Make(name),
+		CreatedAt: clock,
+		UpdatedAt: clock,
+	}
+}
+
+func newChild(name string, parent *domain.Category) *domain.Category {
+	c := newCategory(name)
+	c.ParentID = &parent.ID
+	return c
+}
+
+func create(t *testing.T, repo domain.CategoryRepository, categories ...*domain.Category) {
+	t.Helper()
+	for _, c := range categories {
+		require.NoError(t, repo.Create(context.Background(), c))
+	}
+}
+
+func testCreateThenGetByID(t *testing.T, repo domain.CategoryRepository) {
+	parent := newCategory("Electronics")
+	child := newChild("Phones", parent)
+	create(t, repo, parent, child)
+
+	got, err := repo.GetByID(context.Background(), child.ID)
+	require.NoError(t, err)
+	assert.Equal(t, child.ID, got.ID)
+	assert.Equal(t, child.Name, got.Name)
+	require.NotNil(t, got.ParentID)
+	assert.Equal(t, parent.ID, *got.ParentID)
+	assert.True(t, child.CreatedAt.Equal(got.CreatedAt))
+	assert.True(t, child.UpdatedAt.Equal(got.UpdatedAt))
+	assert.Equal(t, int64(1), got.Version)
+}
+
+func testCreateDuplicateName(t *testing.T, repo domain.CategoryRepository) {
+	create(t, repo, newCategory("Electronics"))
+
+	err := repo.Create(context.Background(), newCategory("Electronics"))
+	assert.ErrorIs(t, err, domain.ErrDuplicate)
+}
+
+func testCreateDuplicateID(t *testing.T, repo domain.CategoryRepository) {
+	cat := newCategory("Electronics")
+	create(t, repo, cat)
+
+	cat.Name = "Books"
+	err := repo.Create(context.Background(), cat)
+	assert.ErrorIs(t, err, domain.ErrDuplicate)
+}
+
+func testCreateUnknownParent(t *testing.T, repo domain.CategoryRepository) {
+	cat := newCategory("Phones")
+	missing := uuid.NewString()
+	cat.ParentID = &missing
+
+	err := repo.Create(context.Background(), cat)
+	assert.ErrorIs(t, err, domain.ErrInvalidParent)
+}
+
+func testUpdateSuccess(t *testing.T, repo domain.CategoryRepository) {
+	cat := newCategory("Electronics")
+	create(t, repo, cat)
+
+	cat.Name = "Gadgets"
+	cat.UpdatedAt = cat.UpdatedAt.Add(time.Second)
+	require.NoError(t, repo.Update(context.Background(), cat))
+
+	got, err := repo.GetByID(context.Background(), cat.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Gadgets", got.Name)
+	assert.True(t, cat.UpdatedAt.Equal(got.UpdatedAt))
+	assert.Equal(t, int64(2), cat.Version)
+	assert.Equal(t, int64(2), got.Version)
+}
+
+func testUpdateNotFound(t *testing.T, repo domain.CategoryRepository) {
+	err := repo.Update(context.Background(), newCategory("Electronics"))
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testUpdateDuplicateName(t *testing.T, repo domain.CategoryRepository) {
+	books := newCategory("Books")
+	create(t, repo, newCategory("Electronics"), books)
+
+	books.Name = "Electronics"
+	err := repo.Update(context.Background(), books)
+	assert.ErrorIs(t, err, domain.ErrDuplicate)
+}
+
+func testUpdateStaleVersion(t *testing.T, repo domain.CategoryRepository) {
+	cat := newCategory("Electronics")
+	create(t, repo, cat)
+
+	stale := *cat
+	cat.Name = "Gadgets"
+	require.NoError(t, repo.Update(context.Background(), cat))
+
+	stale.Name = "Devices"
+	err := repo.Update(context.Background(), &stale)
+	assert.ErrorIs(t, err, domain.ErrPreconditionFailed)
+
+	got, err := repo.GetByID(context.Background(), cat.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Gadgets", got.Name)
+}
+
+func testDeleteSuccess(t *testing.T, repo domain.CategoryRepository) {
+	cat := newCategory("Electronics")
+	create(t, repo, cat)
+
+	deleted, err := repo.Delete(context.Background(), cat.ID, cat.Version)
+	require.NoError(t, err)
+	assert.Equal(t, cat.Name, deleted.Name)
+	assert.Nil(t, deleted.DeletedAt)
+
+	_, err = repo.GetByID(context.Background(), cat.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testDeleteNotFound(t *testing.T, repo domain.CategoryRepository) {
+	_, err := repo.Delete(context.Background(), uuid.NewString(), 0)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testDeleteStaleVersion(t *testing.T, repo domain.CategoryRepository) {
+	cat := newCategory("Electronics")
+	create(t, repo, cat)
+
+	_, err := repo.Delete(context.Background(), cat.ID, cat.Version+1)
+	assert.ErrorIs(t, err, domain.ErrPreconditionFailed)
+
+	_, err = repo.GetByID(context.Background(), cat.ID)
+	assert.NoError(t, err)
+}
+
+func testDeleteWithChildren(t *testing.T, repo domain.CategoryRepository) {
+	parent := newCategory("Electronics")
+	create(t, repo, parent, newChild("Phones", parent))
+
+	_, err := repo.Delete(context.Background(), parent.ID, 0)
+	assert.ErrorIs(t, err, domain.ErrHasChildren)
+}
+
+func testDeleteHidesCategory(t *testing.T, repo domain.CategoryRepository) {
+	parent := newCategory("Electronics")
+	child := newChild("Phones", parent)
+	create(t, repo, parent, child)
+
+	ctx := context.Background()
+	_, err := repo.Delete(ctx, child.ID, 0)
+	require.NoError(t, err)
+
+	_, err = repo.GetByID(ctx, child.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	children, err := repo.ListChildren(ctx, parent.ID)
+	require.NoError(t, err)
+	assert.Empty(t, children)
+
+	count, err := repo.Count(ctx, domain.CategoryFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	err = repo.Update(ctx, child)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	_, err = repo.Delete(ctx, child.ID, 0)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	all, err := repo.List(ctx, domain.PaginationParams{Page: 1, Limit: 10, Filter: domain.CategoryFilter{IncludeDeleted: true}})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, child.ID, all[0].ID)
+	assert.NotNil(t, all[0].DeletedAt)
+	assert.Nil(t, all[1].DeletedAt)
+}
+
+func testDeleteFreesName(t *testing.T, repo domain.CategoryRepository) {
+	parent := newCategory("Electronics")
+	child := newChild("Phones", parent)
+	create(t, repo, parent, child)
+
+	ctx := context.Background()
+	_, err := repo.Delete(ctx, child.ID, 0)
+	require.NoError(t, err)
+	_, err = repo.Delete(ctx, parent.ID, 0)
+	require.NoError(t, err)
+
+	create(t, repo, newCategory("Electronics"))
+}
+
+func testRestoreSuccess(t *testing.T, repo domain.CategoryRepository) {
+	cat := newCategory("Electronics")
+	create(t, repo, cat)
+
+	ctx := context.Background()
+	_, err := repo.Delete(ctx, cat.ID, 0)
+	require.NoError(t, err)
+
+	restored, err := repo.Restore(ctx, cat.ID)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Equal(t, int64(3), restored.Version)
+
+	got, err := repo.GetByID(ctx, cat.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), got.Version)
+}
+
+func testRestoreErrors(t *testing.T, repo domain.CategoryRepository) {
+	parent := newCategory("Electronics")
+	child := newChild("Phones", parent)
+	create(t, repo, parent, child)
+
+	ctx := context.Background()
+
+	_, err := repo.Restore(ctx, uuid.NewString())
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	_, err = repo.Restore(ctx, child.ID)
+	assert.ErrorIs(t, err, domain.ErrNotDeleted)
+
+	_, err = repo.Delete(ctx, child.ID, 0)
+	require.NoError(t, err)
+	_, err = repo.Delete(ctx, parent.ID, 0)
+	require.NoError(t, err)
+
+	_, err = repo.Restore(ctx, child.ID)
+	assert.ErrorIs(t, err, domain.ErrInvalidParent)
+
+	create(t, repo, newCategory("Electronics"))
+
+	_, err = repo.Restore(ctx, parent.ID)
+	assert.ErrorIs(t, err, domain.ErrDuplicate)
+}
+
+func testPurge(t *testing.T, repo domain.CategoryRepository) {
+	live := newCategory("Electronics")
+	deleted := newCategory("Books")
+	create(t, repo, live, deleted)
+
+	ctx := context.Background()
+	_, err := repo.Delete(ctx, deleted.ID, 0)
+	require.NoError(t, err)
+
+	purged, err := repo.Purge(ctx, live.ID, live.Version)
+	require.NoError(t, err)
+	assert.Nil(t, purged.DeletedAt)
+
+	purged, err = repo.Purge(ctx, deleted.ID, 0)
+	require.NoError(t, err)
+	assert.NotNil(t, purged.DeletedAt)
+
+	all, err := repo.List(ctx, domain.PaginationParams{Page: 1, Limit: 10, Filter: domain.CategoryFilter{IncludeDeleted: true}})
+	require.NoError(t, err)
+	assert.Empty(t, all)
+
+	_, err = repo.Purge(ctx, live.ID, 0)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testPurgeWithChildren(t *testing.T, repo domain.CategoryRepository) {
+	parent := newCategory("Electronics")
+	child := newChild("Phones", parent)
+	create(t, repo, parent, child)
+
+	ctx := context.Background()
+	_, err := repo.Delete(ctx, child.ID, 0)
+	require.NoError(t, err)
+
+	_, err = repo.Purge(ctx, parent.ID, 0)
+	assert.ErrorIs(t, err, domain.ErrHasChildren)
+}
+
+func testGetByIDNotFound(t *testing.T, repo domain.CategoryRepository) {
+	_, err := repo.GetByID(context.Background(), uuid.NewString())
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testListOrderAndPagination(t *testing.T, repo domain.CategoryRepository) {
+	for _, name := range []string{"A", "B", "C", "D", "E"} {
+		create(t, repo, newCategory(name))
+	}
+
+	ctx := context.Background()
+
+	page1, err := repo.List(ctx, domain.PaginationParams{Page: 1, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page1, 2)
+	assert.Equal(t, "E", page1[0].Name)
+	assert.Equal(t, "D", page1[1].Name)
+
+	page3, err := repo.List(ctx, domain.PaginationParams{Page: 3, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page3, 1)
+	assert.Equal(t, "A", page3[0].Name)
+
+	page4, err := repo.List(ctx, domain.PaginationParams{Page: 4, Limit: 2})
+	require.NoError(t, err)
+	assert.Empty(t, page4)
+}
+
+func testListAfter(t *testing.T, repo domain.CategoryRepository) {
+	first := newCategory("First")
+	tied := newCategory("Tied A")
+	other := newCategory("Tied B")
+	other.CreatedAt = tied.CreatedAt
+	create(t, repo, first, tied, other, newCategory("Last"))
+
+	ctx := context.Background()
+
+	var (
+		seen  []string
+		after *domain.CategoryCursor
+	)
+	for range 4 {
+		page, err := repo.ListAfter(ctx, domain.PaginationParams{Limit: 2}, after)
+		require.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		for _, c := range page {
+			seen = append(seen, c.Name)
+		}
+		cursor := domain.NewCategoryCursor(page[len(page)-1], domain.DefaultCategorySort)
+		after = &cursor
+	}
+
+	require.Len(t, seen, 4)
+	assert.Equal(t, "Last", seen[0])
+	assert.ElementsMatch(t, []string{"Tied A", "Tied B"}, seen[1:3])
+	assert.Equal(t, "First", seen[3])
+}
+
+func testListSortAndFilter(t *testing.T, repo domain.CategoryRepository) {
+	books := newCategory("Books")
+	phones := newCategory("Phones")
+	headphones := newCategory("Headphones")
+	create(t, repo, books, phones, headphones, newCategory("100% Cotton"))
+
+	ctx := context.Background()
+
+	byName, err := repo.List(ctx, domain.PaginationParams{Page: 1, Limit: 10, Sort: domain.SortNameAsc})
+	require.NoError(t, err)
+	require.Len(t, byName, 4)
+	assert.Equal(t, []string{"100% Cotton", "Books", "Headphones", "Phones"}, names(byName))
+
+	search, err := repo.List(ctx, domain.PaginationParams{
+		Page: 1, Limit: 10, Sort: domain.SortNameDesc,
+		Filter: domain.CategoryFilter{Query: "PHONE"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Phones", "Headphones"}, names(search))
+
+	literal, err := repo.List(ctx, domain.PaginationParams{Page: 1, Limit: 10, Filter: domain.CategoryFilter{Query: "%"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"100% Cotton"}, names(literal))
+
+	recent, err := repo.List(ctx, domain.PaginationParams{
+		Page: 1, Limit: 10, Sort: domain.SortCreatedAtAsc,
+		Filter: domain.CategoryFilter{CreatedAfter: phones.CreatedAt},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Headphones", "100% Cotton"}, names(recent))
+}
+
+func testListTimeFilterWithOffset(t *testing.T, repo domain.CategoryRepository) {
+	books := newCategory("Books")
+	phones := newCategory("Phones")
+	create(t, repo, books, phones, newCategory("Headphones"))
+
+	// The same instants as the creation times, written seven hours ahead of UTC.
+	jakarta := time.FixedZone("UTC+7", 7*60*60)
+
+	created, err := repo.List(context.Background(), domain.PaginationParams{
+		Page: 1, Limit: 10, Sort: domain.SortCreatedAtAsc,
+		Filter: domain.CategoryFilter{CreatedAfter: books.CreatedAt.In(jakarta)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Phones", "Headphones"}, names(created))
+
+	updated, err := repo.List(context.Background(), domain.PaginationParams{
+		Page: 1, Limit: 10, Sort: domain.SortCreatedAtAsc,
+		Filter: domain.CategoryFilter{UpdatedAfter: phones.UpdatedAt.In(jakarta)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Headphones"}, names(updated))
+}
+
+func testListAfterSortedByName(t *testing.T, repo domain.CategoryRepository) {
+	for _, name := range []string{"Delta", "Alpha", "Charlie", "Bravo"} {
+		create(t, repo, newCategory(name))
+	}
+
+	ctx := context.Background()
+	p := domain.PaginationParams{Limit: 3, Sort: domain.SortNameDesc}
+
+	first, err := repo.ListAfter(ctx, p, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Delta", "Charlie", "Bravo"}, names(first))
+
+	cursor := domain.NewCategoryCursor(first[1], domain.SortNameDesc)
+	rest, err := repo.ListAfter(ctx, p, &cursor)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bravo", "Alpha"}, names(rest))
+}
+
+// Names sort the same with every driver and database collation: by code point, upper case first.
+func testListAfterSortedByNameCodePoints(t *testing.T, repo domain.CategoryRepository) {
+	for _, name := range []string{"apple", "Zebra", "Éclair", "Eclairs"} {
+		create(t, repo, newCategory(name))
+	}
+
+	ctx := context.Background()
+	p := domain.PaginationParams{Limit: 2, Sort: domain.SortNameAsc}
+
+	first, err := repo.ListAfter(ctx, p, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Eclairs", "Zebra"}, names(first))
+
+	cursor := domain.NewCategoryCursor(first[1], domain.SortNameAsc)
+	rest, err := repo.ListAfter(ctx, p, &cursor)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"apple", "Éclair"}, names(rest))
+}
+
+func testCountFilter(t *testing.T, repo domain.CategoryRepository) {
+	create(t, repo, newCategory("Phones"), newCategory("Headphones"), newCategory("Books"))
+
+	count, err := repo.Count(context.Background(), domain.CategoryFilter{Query: "phone"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func names(categories []*domain.Category) []string {
+	result := make([]string, len(categories))
+	for i, c := range categories {
+		result[i] = c.Name
+	}
+	return result
+}
+
+func testListEmpty(t *testing.T, repo domain.CategoryRepository) {
+	result, err := repo.List(context.Background(), domain.PaginationParams{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Empty(t, result)
+}
+
+func testCount(t *testing.T, repo domain.CategoryRepository) {
+	count, err := repo.Count(context.Background(), domain.CategoryFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	create(t, repo, newCategory("Electronics"), newCategory("Books"), newCategory("Fashion"))
+
+	count, err = repo.Count(context.Background(), domain.CategoryFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
+
+func testListChildren(t *testing.T, repo domain.CategoryRepository) {
+	electronics := newCategory("Electronics")
+	phones := newChild("Phones", electronics)
+	laptops := newChild("Laptops", electronics)
+	create(t, repo, electronics, phones, laptops, newChild("Accessories", phones))
+
+	children, err := repo.ListChildren(context.Background(), electronics.ID)
+	require.NoError(t, err)
+	require.Len(t, children, 2)
+	assert.Equal(t, "Laptops", children[0].Name)
+	assert.Equal(t, "Phones", children[1].Name)
+}
+
+func testListAncestors(t *testing.T, repo domain.CategoryRepository) {
+	electronics := newCategory("Electronics")
+	phones := newChild("Phones", electronics)
+	accessories := newChild("Accessories", phones)
+	create(t, repo, electronics, phones, accessories)
+
+	ancestors, err := repo.ListAncestors(context.Background(), accessories.ID)
+	require.NoError(t, err)
+	require.Len(t, ancestors, 2)
+	assert.Equal(t, electronics.ID, ancestors[0].ID)
+	assert.Equal(t, phones.ID, ancestors[1].ID)
+
+	ancestors, err = repo.ListAncestors(context.Background(), electronics.ID)
+	require.NoError(t, err)
+	assert.Empty(t, ancestors)
+}
+
+func testListAll(t *testing.T, repo domain.CategoryRepository) {
+	electronics := newCategory("Electronics")
+	create(t, repo, electronics, newChild("Phones", electronics), newCategory("Books"))
+
+	all, err := repo.ListAll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, "Books", all[0].Name)
+	assert.Equal(t, "Electronics", all[1].Name)
+	assert.Equal(t, "Phones", all[2].Name)
+}
+
+func testSubtreeHeight(t *testing.T, repo domain.CategoryRepository) {
+	electronics := newCategory("Electronics")
+	phones := newChild("Phones", electronics)
+	create(t, repo, electronics, phones, newChild("Accessories", phones), newChild("Laptops", electronics))
+
+	height, err := repo.SubtreeHeight(context.Background(), electronics.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, height)
+
+	_, err = repo.SubtreeHeight(context.Background(), uuid.NewString())
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testMoveReparents(t *testing.T, repo domain.CategoryRepository) {
+	electronics := newCategory("Electronics")
+	phones := newChild("Phones", electronics)
+	accessories := newChild("Accessories", phones)
+	gadgets := newCategory("Gadgets")
+	create(t, repo, electronics, phones, accessories, gadgets)
+
+	move, err := repo.Move(context.Background(), phones.ID, &gadgets.ID, 5)
+	require.NoError(t, err)
+	require.NotNil(t, move.OldParentID)
+	assert.Equal(t, electronics.ID, *move.OldParentID)
+	require.NotNil(t, move.Category.ParentID)
+	assert.Equal(t, gadgets.ID, *move.Category.ParentID)
+	assert.Equal(t, int64(2), move.Category.Version)
+	require.NotNil(t, move.Before)
+	assert.Equal(t, electronics.ID, *move.Before.ParentID)
+	assert.Equal(t, int64(1), move.Before.Version)
+
+	ancestors, err := repo.ListAncestors(context.Background(), accessories.ID)
+	require.NoError(t, err)
+	require.Len(t, ancestors, 2)
+	assert.Equal(t, gadgets.ID, ancestors[0].ID)
+
+	move, err = repo.Move(context.Background(), phones.ID, nil, 5)
+	require.NoError(t, err)
+	assert.Nil(t, move.Category.ParentID)
+}
+
+func testMoveIntoDescendant(t *testing.T, repo domain.CategoryRepository) {
+	electronics := newCategory("Electronics")
+	phones := newChild("Phones", electronics)
+	create(t, repo, electronics, phones)
+
+	_, err := repo.Move(context.Background(), electronics.ID, &phones.ID, 5)
+	assert.ErrorIs(t, err, domain.ErrInvalidParent)
+
+	_, err = repo.Move(context.Background(), electronics.ID, &electronics.ID, 5)
+	assert.ErrorIs(t, err, domain.ErrInvalidParent)
+}
+
+func testMoveMaxDepth(t *testing.T, repo domain.CategoryRepository) {
+	electronics := newCategory("Electronics")
+	phones := newCategory("Phones")
+	create(t, repo, electronics, phones, newChild("Accessories", phones))
+
+	_, err := repo.Move(context.Background(), phones.ID, &electronics.ID, 2)
+	assert.ErrorIs(t, err, domain.ErrMaxDepthExceeded)
+
+	got, err := repo.GetByID(context.Background(), phones.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.ParentID)
+}
+
+func testCreateDuplicateSlug(t *testing.T, repo domain.CategoryRepository) {
+	first := newCategory("Electronics")
+	create(t, repo, first)
+
+	second := newCategory("Gadgets")
+	second.Slug = first.Slug
+	assert.ErrorIs(t, repo.Create(context.Background(), second), domain.ErrDuplicate)
+
+	_, err := repo.Delete(context.Background(), first.ID, 0)
+	require.NoError(t, err)
+	assert.NoError(t, repo.Create(context.Background(), second))
+}
+
+func testGetBySlug(t *testing.T, repo domain.CategoryRepository) {
+	ctx := context.Background()
+	phones := newCategory("Phones")
+	gadgets := newCategory("Gadgets")
+	create(t, repo, phones, gadgets)
+
+	phones.Slug = "mobiles"
+	require.NoError(t, repo.Update(ctx, phones))
+	require.NoError(t, repo.AddFormerSlug(ctx, phones.ID, "phones"))
+
+	got, err := repo.GetBySlug(ctx, "mobiles")
+	require.NoError(t, err)
+	assert.Equal(t, phones.ID, got.ID)
+	assert.Equal(t, "mobiles", got.Slug)
+
+	got, err = repo.GetBySlug(ctx, "phones")
+	require.NoError(t, err)
+	assert.Equal(t, phones.ID, got.ID)
+
+	// A category that takes over a former slug wins over the one that had it.
+	gadgets.Slug = "phones"
+	require.NoError(t, repo.Update(ctx, gadgets))
+
+	got, err = repo.GetBySlug(ctx, "phones")
+	require.NoError(t, err)
+	assert.Equal(t, gadgets.ID, got.ID)
+
+	_, err = repo.Delete(ctx, phones.ID, 0)
+	require.NoError(t, err)
+
+	_, err = repo.GetBySlug(ctx, "mobiles")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testListSlugs(t *testing.T, repo domain.CategoryRepository) {
+	ctx := context.Background()
+	garden := newCategory("Garden")
+	garden.Slug = "home-garden"
+	second := newCategory("Garden 2")
+	second.Slug = "home-garden-2"
+	other := newCategory("Home Gardening")
+	other.Slug = "home-gardening"
+	deleted := newCategory("Old Garden")
+	deleted.Slug = "home-garden-3"
+	create(t, repo, garden, second, other, deleted)
+
+	_, err := repo.Delete(ctx, deleted.ID, 0)
+	require.NoError(t, err)
+	require.NoError(t, repo.AddFormerSlug(ctx, garden.ID, "home-garden-old"))
+
+	slugs, err := repo.ListSlugs(ctx, "home-garden")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"home-garden":     garden.ID,
+		"home-garden-2":   second.ID,
+		"home-garden-3":   deleted.ID,
+		"home-garden-old": garden.ID,
+	}, slugs)
+}
+
+func testPurgeDropsFormerSlugs(t *testing.T, repo domain.CategoryRepository) {
+	ctx := context.Background()
+	cat := newCategory("Phones")
+	create(t, repo, cat)
+	require.NoError(t, repo.AddFormerSlug(ctx, cat.ID, "mobiles"))
+
+	_, err := repo.Purge(ctx, cat.ID, 0)
+	require.NoError(t, err)
+
+	slugs, err := repo.ListSlugs(ctx, "mobiles")
+	require.NoError(t, err)
+	assert.Empty(t, slugs)
+}
+
+func testCreateNameKeyDuplicate(t *testing.T, repo domain.CategoryRepository) {
+	first := newCategory("Café Books")
+	create(t, repo, first)
+
+	for i, name := range []string{"café books", "Cafe Books", "CAFÉ BOOKS"} {
+		variant := newCategory(name)
+		variant.Slug = fmt.Sprintf("variant-%d", i)
+
+		err := repo.Create(context.Background(), variant)
+
+		var dupErr *domain.DuplicateError
+		require.ErrorAs(t, err, &dupErr, name)
+		assert.Equal(t, domain.FieldName, dupErr.Field)
+		assert.Equal(t, first.ID, dupErr.ID)
+		assert.ErrorIs(t, err, domain.ErrDuplicate)
+	}
+
+	assert.NoError(t, repo.Create(context.Background(), newCategory("Café Book")))
+}
+
+func testUpdateNameCase(t *testing.T, repo domain.CategoryRepository) {
+	cat := newCategory("Books")
+	create(t, repo, cat)
+
+	cat.Name = "BOOKS"
+	require.NoError(t, repo.Update(context.Background(), cat))
+
+	got, err := repo.GetByID(context.Background(), cat.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "BOOKS", got.Name)
+}
+
+func testRestoreNameKeyDuplicate(t *testing.T, repo domain.CategoryRepository) {
+	ctx := context.Background()
+
+	deleted := newCategory("Books")
+	create(t, repo, deleted)
+	_, err := repo.Delete(ctx, deleted.ID, 0)
+	require.NoError(t, err)
+
+	taker := newCategory("BOOKS")
+	taker.Slug = "books-2"
+	create(t, repo, taker)
+
+	_, err = repo.Restore(ctx, deleted.ID)
+
+	var dupErr *domain.DuplicateError
+	require.ErrorAs(t, err, &dupErr)
+	assert.Equal(t, taker.ID, dupErr.ID)
+}
@@ -0,0 +1,109 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RevisionFactory returns an empty revision repository for a single test.
+type RevisionFactory func(t *testing.T) domain.RevisionRepository
+
+func RunRevisionRepository(t *testing.T, newRepo RevisionFactory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo domain.RevisionRepository)
+	}{
+		{"Add_ThenList_RoundTripsSnapshots", testRevisionRoundTrip},
+		{"ListByCategory_NewestFirst_Paginated", testRevisionPagination},
+		{"ListByCategory_Unknown_ReturnsEmptySlice", testRevisionUnknownCategory},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+func newRevision(categoryID, action string, before, after *domain.Category) *domain.CategoryRevision {
+	clock = clock.Add(time.Millisecond)
+	return &domain.CategoryRevision{
+		ID:         uuid.NewString(),
+		CategoryID: categoryID,
+		Action:     action,
+		Before:     before,
+		After:      after,
+		RequestID:  "req-" + action,
+		Actor:      "alice",
+		CreatedAt:  clock,
+	}
+}
+
+func testRevisionRoundTrip(t *testing.T, repo domain.RevisionRepository) {
+	parent := newCategory("Electronics")
+	before := newChild("Phones", parent)
+	after := *before
+	after.Name = "Mobile Phones"
+	after.Version = 2
+
+	rev := newRevision(before.ID, domain.RevisionUpdated, before, &after)
+	require.NoError(t, repo.Add(context.Background(), rev))
+
+	got, err := repo.ListByCategory(context.Background(), before.ID, domain.PaginationParams{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	assert.Equal(t, rev.ID, got[0].ID)
+	assert.Equal(t, domain.RevisionUpdated, got[0].Action)
+	assert.Equal(t, "req-updated", got[0].RequestID)
+	assert.Equal(t, "alice", got[0].Actor)
+	assert.True(t, rev.CreatedAt.Equal(got[0].CreatedAt))
+
+	require.NotNil(t, got[0].Before)
+	assert.Equal(t, "Phones", got[0].Before.Name)
+	require.NotNil(t, got[0].Before.ParentID)
+	assert.Equal(t, parent.ID, *got[0].Before.ParentID)
+
+	require.NotNil(t, got[0].After)
+	assert.Equal(t, "Mobile Phones", got[0].After.Name)
+	assert.Equal(t, int64(2), got[0].After.Version)
+}
+
+func testRevisionPagination(t *testing.T, repo domain.RevisionRepository) {
+	cat := newCategory("Electronics")
+	ctx := context.Background()
+
+	for _, action := range []string{domain.RevisionCreated, domain.RevisionUpdated, domain.RevisionDeleted} {
+		require.NoError(t, repo.Add(ctx, newRevision(cat.ID, action, nil, cat)))
+	}
+	require.NoError(t, repo.Add(ctx, newRevision(uuid.NewString(), domain.RevisionCreated, nil, nil)))
+
+	count, err := repo.CountByCategory(ctx, cat.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	page1, err := repo.ListByCategory(ctx, cat.ID, domain.PaginationParams{Page: 1, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page1, 2)
+	assert.Equal(t, domain.RevisionDeleted, page1[0].Action)
+	assert.Equal(t, domain.RevisionUpdated, page1[1].Action)
+	assert.Nil(t, page1[0].Before)
+
+	page2, err := repo.ListByCategory(ctx, cat.ID, domain.PaginationParams{Page: 2, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page2, 1)
+	assert.Equal(t, domain.RevisionCreated, page2[0].Action)
+}
+
+func testRevisionUnknownCategory(t *testing.T, repo domain.RevisionRepository) {
+	got, err := repo.ListByCategory(context.Background(), uuid.NewString(), domain.PaginationParams{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	assert.NotNil(t, got)
+	assert.Empty(t, got)
+}
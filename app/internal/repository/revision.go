@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/alfattd/category-service/internal/domain"
+)
+
+type postgresRevisionRepo struct {
+	db *sql.DB
+}
+
+func NewPostgresRevisionRepo(db *sql.DB) domain.RevisionRepository {
+	return &postgresRevisionRepo{db: db}
+}
+
+func (r *postgresRevisionRepo) Add(ctx context.Context, rev *domain.CategoryRevision) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	before, err := marshalSnapshot(rev.Before)
+	if err != nil {
+		return err
+	}
+
+	after, err := marshalSnapshot(rev.After)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO category_revisions (id, category_id, action, before, after, request_id, actor, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err = conn(ctx, r.db).ExecContext(ctx, query,
+		rev.ID, rev.CategoryID, rev.Action, before, after, rev.RequestID, rev.Actor, rev.CreatedAt)
+	if err != nil {
+		return mapPostgresError(err)
+	}
+
+	return nil
+}
+
+func (r *postgresRevisionRepo) ListByCategory(ctx context.Context, categoryID string, p domain.PaginationParams) ([]*domain.CategoryRevision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	offset := (p.Page - 1) * p.Limit
+
+	query := `
+	SELECT id, category_id, action, before, after, request_id, actor, created_at
+	FROM category_revisions
+	WHERE category_id = $1
+	ORDER BY seq DESC
+	LIMIT $2 OFFSET $3
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, categoryID, p.Limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*domain.CategoryRevision, 0)
+	for rows.Next() {
+		var (
+			rev           domain.CategoryRevision
+			before, after []byte
+		)
+
+		if err := rows.Scan(&rev.ID, &rev.CategoryID, &rev.Action, &before, &after, &rev.RequestID, &rev.Actor, &rev.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		if rev.Before, err = unmarshalSnapshot(before); err != nil {
+			return nil, err
+		}
+
+		if rev.After, err = unmarshalSnapshot(after); err != nil {
+			return nil, err
+		}
+
+		result = append(result, &rev)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *postgresRevisionRepo) CountByCategory(ctx context.Context, categoryID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	var total int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*) FROM category_revisions WHERE category_id = $1`, categoryID).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// marshalSnapshot returns an untyped nil for a missing snapshot so it is stored as NULL.
+func marshalSnapshot(c *domain.Category) (any, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal category snapshot: %w", err)
+	}
+
+	return b, nil
+}
+
+func unmarshalSnapshot(b []byte) (*domain.Category, error) {
+	if b == nil {
+		return nil, nil
+	}
+
+	var c domain.Category
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal category snapshot: %w", err)
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+)
+
+func (r *postgresCategoryRepo) GetBySlug(ctx context.Context, slug string) (*domain.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// A former slug may since have been given to another category, which then wins.
+	query := `
+	SELECT id, name, slug, parent_id, version, created_at, updated_at, deleted_at
+	FROM categories
+	WHERE deleted_at IS NULL
+		AND (slug = $1 OR id = (SELECT category_id FROM category_slugs WHERE slug = $1))
+	ORDER BY slug = $1 DESC
+	LIMIT 1
+	`
+
+	c, err := scanCategory(r.conn(ctx).QueryRowContext(ctx, query, slug))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (r *postgresCategoryRepo) ListSlugs(ctx context.Context, base string) (map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Current slugs come last, so they win over former ones another category had.
+	query := `
+	SELECT slug, category_id, false AS current FROM category_slugs WHERE slug = $1 OR slug LIKE $2
+	UNION ALL
+	SELECT slug, id, true FROM categories WHERE slug = $1 OR slug LIKE $2
+	ORDER BY current
+	`
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, base, escapeLike(base)+"-%")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slugs := make(map[string]string)
+	for rows.Next() {
+		var (
+			slug, id string
+			current  bool
+		)
+		if err := rows.Scan(&slug, &id, &current); err != nil {
+			return nil, err
+		}
+		slugs[slug] = id
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return slugs, nil
+}
+
+func (r *postgresCategoryRepo) LockSlug(ctx context.Context, root string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, err := r.conn(ctx).ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('category_slugs'), hashtext($1))`, root)
+	return err
+}
+
+func (r *postgresCategoryRepo) AddFormerSlug(ctx context.Context, id, slug string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO category_slugs (slug, category_id, created_at)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (slug) DO UPDATE
+	SET category_id = EXCLUDED.category_id,
+		created_at = EXCLUDED.created_at
+	`
+
+	if _, err := r.conn(ctx).ExecContext(ctx, query, slug, id, time.Now()); err != nil {
+		return mapPostgresError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/alfattd/category-service/internal/repository"
+
+// tracedQuerier wraps every statement in a client span. Rows are read after the span
+// has ended, so it measures the round trip until the first result.
+type tracedQuerier struct {
+	q      querier
+	tracer trace.Tracer
+}
+
+// traced only instruments statements that belong to a trace, which keeps the outbox
+// polling from producing a root span every interval.
+func traced(ctx context.Context, q querier) querier {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return q
+	}
+	return tracedQuerier{q: q, tracer: otel.Tracer(tracerName)}
+}
+
+func (t tracedQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := t.start(ctx, query)
+	res, err := t.q.ExecContext(ctx, query, args...)
+	endSpan(span, err)
+	return res, err
+}
+
+func (t tracedQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, span := t.start(ctx, query)
+	rows, err := t.q.QueryContext(ctx, query, args...)
+	endSpan(span, err)
+	return rows, err
+}
+
+// QueryRowContext defers its error to Scan, so the span cannot tell failed lookups apart.
+func (t tracedQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, span := t.start(ctx, query)
+	row := t.q.QueryRowContext(ctx, query, args...)
+	endSpan(span, row.Err())
+	return row
+}
+
+func (t tracedQuerier) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	query = strings.TrimSpace(query)
+
+	var operation string
+	if fields := strings.Fields(query); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+
+	return t.tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(query),
+		),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/alfattd/category-service/internal/domain"
+)
+
+type txKey struct{}
+
+type postgresTransactor struct {
+	db *sql.DB
+}
+
+func NewPostgresTransactor(db *sql.DB) domain.Transactor {
+	return &postgresTransactor{db: db}
+}
+
+func (t *postgresTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withinTx(ctx, t.db, fn)
+}
+
+func (t *postgresTransactor) WithinSnapshot(ctx context.Context, fn func(ctx context.Context) error) error {
+	return beginTx(ctx, t.db, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, fn)
+}
+
+// withinTx runs fn in a transaction carried by ctx. A transaction already
+// present in ctx is joined instead of starting a nested one.
+func withinTx(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error) error {
+	return beginTx(ctx, db, nil, fn)
+}
+
+// beginTx is withinTx starting transactions with opts.
+func beginTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// conn returns the transaction carried by ctx, falling back to db.
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return traced(ctx, tx)
+	}
+	return traced(ctx, db)
+}
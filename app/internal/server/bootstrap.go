@@ -7,9 +7,10 @@ import (
 
 	"github.com/alfattd/category-service/internal/config"
 	"github.com/alfattd/category-service/internal/pkg/logger"
+	"github.com/alfattd/category-service/internal/pkg/system"
 )
 
-func Build() (*config.Config, *http.Server, func(), *slog.Logger) {
+func Build() (*config.Config, *http.Server, *system.Readiness, func(), *slog.Logger) {
 	log := logger.New()
 
 	cfg := config.Load()
@@ -19,7 +20,7 @@ func Build() (*config.Config, *http.Server, func(), *slog.Logger) {
 		os.Exit(1)
 	}
 
-	srv, cleanup := New(cfg, log)
+	srv, readiness, cleanup := New(cfg, log)
 
-	return cfg, srv, cleanup, log
+	return cfg, srv, readiness, cleanup, log
 }
@@ -1,61 +1,164 @@
 package server
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"os"
 	"time"
 
 	"github.com/alfattd/category-service/internal/config"
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/eventqueue"
 	"github.com/alfattd/category-service/internal/handler"
-	"github.com/alfattd/category-service/internal/pkg/database"
 	"github.com/alfattd/category-service/internal/pkg/middleware"
 	"github.com/alfattd/category-service/internal/pkg/rabbitmq"
 	"github.com/alfattd/category-service/internal/pkg/system"
-	"github.com/alfattd/category-service/internal/repository"
+	"github.com/alfattd/category-service/internal/pkg/tracing"
 	"github.com/alfattd/category-service/internal/service"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-func New(cfg *config.Config, log *slog.Logger) (*http.Server, func()) {
+func New(cfg *config.Config, log *slog.Logger) (*http.Server, *system.Readiness, func()) {
 	mux := http.NewServeMux()
 
-	db, err := database.NewPostgres(cfg.DBUrl())
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TraceExporter, cfg.ServiceName, cfg.ServiceVersion)
 	if err != nil {
-		log.Error("failed to connect to database", "error", err)
+		log.Error("failed to set up tracing", "error", err)
 		os.Exit(1)
 	}
 
-	publisher, err := rabbitmq.NewPublisher(cfg.RabbitMQUrl, "category_events")
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	publisher, err := rabbitmq.NewPublisher(cfg.RabbitMQUrl, cfg.RabbitMQQueue,
+		rabbitmq.WithMetrics(reg),
+		rabbitmq.WithExchange(cfg.RabbitMQExchange, cfg.RabbitMQExchangeType, cfg.RabbitMQRoutingKey),
+		rabbitmq.WithMandatory(cfg.RabbitMQMandatory),
+		rabbitmq.WithEventFormat(cfg.EventFormat, cfg.ServiceName),
+	)
 	if err != nil {
 		log.Error("failed to connect to rabbitmq", "error", err)
 		os.Exit(1)
 	}
 
+	store := newStorage(cfg, publisher, reg, log)
+
+	// Behind the outbox a broker outage only delays events, so it does not take the service out of rotation.
+	checkers := []system.Checker{{Name: "rabbitmq", Critical: !store.outbox, Check: publisher.Ping}}
+
+	// The outbox already keeps requests from waiting on the broker, and its relay has to see each publish succeed.
+	var eventPublisher domain.CategoryEventPublisher = publisher
+	closeQueue := func() {}
+	if cfg.EventQueueEnabled && !store.outbox {
+		queue, err := eventqueue.New(publisher, log,
+			eventqueue.WithSize(cfg.EventQueueSize),
+			eventqueue.WithWorkers(cfg.EventQueueWorkers),
+			eventqueue.WithOverflow(cfg.EventQueueOverflow, cfg.EventQueueSpillDir),
+			eventqueue.WithMetrics(reg),
+		)
+		if err != nil {
+			log.Error("failed to start event queue", "error", err)
+			os.Exit(1)
+		}
+
+		eventPublisher = queue
+		checkers = append(checkers, system.Checker{
+			Name:    "event_queue",
+			Check:   queue.Check,
+			Details: func() any { return queue.Stats() },
+		})
+		closeQueue = func() {
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.EventQueueFlushTimeout)
+			defer cancel()
+			if err := queue.Close(ctx); err != nil {
+				log.Error("failed to flush event queue", "error", err)
+			}
+		}
+	}
+
+	apiKeyService := service.NewAPIKeyService(store.apiKeyRepo, log)
+	usageCtx, stopUsage := context.WithCancel(context.Background())
+	usageDone := make(chan struct{})
+
+	go func() {
+		defer close(usageDone)
+		apiKeyService.Run(usageCtx)
+	}()
+
+	// API key usage is flushed, the relay inside the storage stopped and the event queue flushed
+	// before their connections close. Tracing goes last to flush the spans of everything shut down before it.
 	cleanup := func() {
+		stopUsage()
+		<-usageDone
+		store.close()
+		closeQueue()
 		publisher.Close()
-		if err := db.Close(); err != nil {
-			log.Error("failed to close database", "error", err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error("failed to flush traces", "error", err)
 		}
 	}
 
-	categoryRepo := repository.NewPostgresCategoryRepo(db)
-	categoryService := service.NewCategoryService(categoryRepo, publisher, log)
+	readiness := system.NewReadiness(cfg.ReadinessTimeout, append(checkers, store.checkers...)...)
+
+	serviceOpts := append([]service.Option{service.WithMaxDepth(cfg.CategoryMaxDepth)}, store.serviceOpts...)
+
+	categoryService := service.NewCategoryService(store.categoryRepo, eventPublisher, log, serviceOpts...)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
 
 	mux.HandleFunc("/health", system.Health)
+	mux.HandleFunc("GET /health/live", system.Health)
+	mux.Handle("GET /health/ready", readiness)
 	mux.HandleFunc("/version", system.Version(cfg.ServiceName, cfg.ServiceVersion))
+	mux.Handle("GET /metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg}))
 
-	mux.HandleFunc("GET /categories", categoryHandler.List)
-	mux.HandleFunc("POST /categories", categoryHandler.Create)
-	mux.HandleFunc("GET /categories/{id}", categoryHandler.GetByID)
-	mux.HandleFunc("PUT /categories/{id}", categoryHandler.Update)
-	mux.HandleFunc("DELETE /categories/{id}", categoryHandler.Delete)
+	guard := newGuards(cfg, apiKeyService, log)
+
+	mux.Handle("GET /categories", guard.adminWhen("include_deleted", guard.read)(categoryHandler.List))
+	mux.Handle("POST /categories", guard.write(categoryHandler.Create))
+	mux.Handle("POST /categories:batch", guard.write(categoryHandler.Batch))
+	mux.Handle("GET /categories/tree", guard.read(categoryHandler.Tree))
+	mux.Handle("GET /categories/export", guard.read(categoryHandler.Export))
+	mux.Handle("POST /categories/import", guard.admin(categoryHandler.Import))
+	mux.Handle("GET /categories/{id}", guard.read(categoryHandler.GetByID))
+	mux.Handle("GET /categories/{id}/children", guard.read(categoryHandler.Children))
+	mux.Handle("GET /categories/{id}/ancestors", guard.read(categoryHandler.Ancestors))
+	mux.Handle("PUT /categories/{id}", guard.write(categoryHandler.Update))
+	mux.Handle("PATCH /categories/{id}", guard.write(categoryHandler.Patch))
+	mux.Handle("DELETE /categories/{id}", guard.adminWhen("purge", guard.write)(categoryHandler.Delete))
+	mux.Handle("POST /categories/{id}/move", guard.write(categoryHandler.Move))
+	mux.Handle("POST /categories/{id}/restore", guard.write(categoryHandler.Restore))
+	mux.Handle("GET /categories/{id}/history", guard.read(categoryHandler.History))
+
+	// ServeMux refuses by-slug/{slug} next to {id}/children and the like, as both would match
+	// /categories/by-slug/children, so the slug lookup is routed before the request reaches mux.
+	root := http.NewServeMux()
+	root.Handle("GET /categories/by-slug/{slug}", guard.read(categoryHandler.GetBySlug))
+	root.Handle("/", mux)
+
+	// Without authentication there is nothing API keys could be used for.
+	if guard.enabled {
+		mux.Handle("POST /admin/api-keys", guard.admin(apiKeyHandler.Issue))
+		mux.Handle("GET /admin/api-keys", guard.admin(apiKeyHandler.List))
+		mux.Handle("DELETE /admin/api-keys/{id}", guard.admin(apiKeyHandler.Revoke))
+	}
 
 	h := middleware.Chain(
 		middleware.RequestID,
+		middleware.Tracing,
 		middleware.Recovery(log),
 		middleware.Logging(log),
-	)(mux)
+		middleware.Metrics(reg),
+	)(root)
 
 	srv := &http.Server{
 		Addr:         ":" + cfg.AppPort,
@@ -65,5 +168,5 @@ func New(cfg *config.Config, log *slog.Logger) (*http.Server, func()) {
 		IdleTimeout:  60 * time.Second,
 	}
 
-	return srv, cleanup
+	return srv, readiness, cleanup
 }
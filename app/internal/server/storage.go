@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/alfattd/category-service/internal/config"
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/outbox"
+	"github.com/alfattd/category-service/internal/pkg/database"
+	"github.com/alfattd/category-service/internal/pkg/system"
+	"github.com/alfattd/category-service/internal/repository"
+	"github.com/alfattd/category-service/internal/repository/memory"
+	"github.com/alfattd/category-service/internal/service"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type storage struct {
+	categoryRepo domain.CategoryRepository
+	apiKeyRepo   domain.APIKeyRepository
+	serviceOpts  []service.Option
+	checkers     []system.Checker
+	outbox       bool // events go through the transactional outbox rather than straight to the broker
+	close        func()
+}
+
+func newStorage(cfg *config.Config, publisher domain.CategoryEventPublisher, reg prometheus.Registerer, log *slog.Logger) *storage {
+	if cfg.StorageDriver == config.StorageDriverMemory {
+		log.Warn("using in-memory storage, data is lost on restart")
+
+		return &storage{
+			categoryRepo: memory.NewCategoryRepo(),
+			apiKeyRepo:   memory.NewAPIKeyRepo(),
+			serviceOpts:  []service.Option{service.WithRevisions(memory.NewRevisionRepo())},
+			close:        func() {},
+		}
+	}
+
+	db, err := database.NewPostgres(cfg.DBUrl())
+	if err != nil {
+		log.Error("failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+
+	if err := database.RegisterMetrics(reg, db, cfg.DBName); err != nil {
+		log.Error("failed to register database metrics", "error", err)
+		os.Exit(1)
+	}
+
+	transactor := repository.NewPostgresTransactor(db)
+
+	s := &storage{
+		categoryRepo: repository.NewPostgresCategoryRepo(db),
+		apiKeyRepo:   repository.NewPostgresAPIKeyRepo(db),
+		serviceOpts: []service.Option{
+			service.WithTransactor(transactor),
+			service.WithRevisions(repository.NewPostgresRevisionRepo(db)),
+		},
+		checkers: []system.Checker{{Name: "postgres", Critical: true, Check: db.PingContext}},
+	}
+
+	stopRelay := func() {}
+	if cfg.OutboxEnabled {
+		s.outbox = true
+
+		outboxRepo := repository.NewPostgresOutboxRepo(db)
+		s.serviceOpts = append(s.serviceOpts, service.WithOutbox(outboxRepo))
+
+		relay := outbox.NewRelay(outboxRepo, publisher, cfg.OutboxRetention, log)
+		relayCtx, cancelRelay := context.WithCancel(context.Background())
+		relayDone := make(chan struct{})
+
+		go func() {
+			defer close(relayDone)
+			relay.Run(relayCtx)
+		}()
+
+		stopRelay = func() {
+			cancelRelay()
+			<-relayDone
+		}
+	}
+
+	s.close = func() {
+		stopRelay()
+		if err := db.Close(); err != nil {
+			log.Error("failed to close database", "error", err)
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,190 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/mocks"
+	"github.com/alfattd/category-service/internal/service"
+	"github.com/alfattd/category-service/internal/validator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// ─── Issue ────────────────────────────────────────────────────────────────────
+
+func TestIssueAPIKey_StoresOnlyHash(t *testing.T) {
+	repo := new(mocks.MockAPIKeyRepository)
+
+	var stored *domain.APIKey
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.APIKey")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*domain.APIKey) }).
+		Return(nil)
+
+	svc := service.NewAPIKeyService(repo, testLogger)
+	issued, err := svc.Issue(context.Background(), domain.IssueAPIKeyParams{
+		Name:   " importer ",
+		Scopes: []string{domain.RoleCategoryWrite, domain.RoleCategoryAdmin, domain.RoleCategoryWrite},
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.True(t, strings.HasPrefix(issued.Secret, "csk_"))
+	assert.Equal(t, "importer", stored.Name)
+	assert.Equal(t, sha256Hex(issued.Secret), stored.Hash)
+	assert.NotContains(t, stored.Hash, issued.Secret)
+	assert.True(t, strings.HasPrefix(issued.Secret, stored.Prefix))
+	assert.Less(t, len(stored.Prefix), len(issued.Secret))
+	assert.Equal(t, []string{domain.RoleCategoryAdmin, domain.RoleCategoryWrite}, stored.Scopes)
+}
+
+func TestIssueAPIKey_UnknownScope_ReturnsValidationError(t *testing.T) {
+	repo := new(mocks.MockAPIKeyRepository)
+
+	svc := service.NewAPIKeyService(repo, testLogger)
+	_, err := svc.Issue(context.Background(), domain.IssueAPIKeyParams{Name: "importer", Scopes: []string{"category:delete"}})
+
+	var errs *validator.ErrorsValidator
+	require.ErrorAs(t, err, &errs)
+	repo.AssertNotCalled(t, "Create")
+}
+
+func TestIssueAPIKey_ExpiryInPast_ReturnsValidationError(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	svc := service.NewAPIKeyService(new(mocks.MockAPIKeyRepository), testLogger)
+	_, err := svc.Issue(context.Background(), domain.IssueAPIKeyParams{
+		Name:      "importer",
+		Scopes:    []string{domain.RoleCategoryWrite},
+		ExpiresAt: &past,
+	})
+
+	var errs *validator.ErrorsValidator
+	require.ErrorAs(t, err, &errs)
+	assert.Contains(t, errs.Messages, "expires_at must be in the future")
+}
+
+func TestIssueAPIKey_ExpiryWithOffset_StoredAsUTC(t *testing.T) {
+	repo := new(mocks.MockAPIKeyRepository)
+
+	var stored *domain.APIKey
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.APIKey")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*domain.APIKey) }).
+		Return(nil)
+
+	expiresAt := time.Now().Add(24 * time.Hour).Truncate(time.Second).In(time.FixedZone("UTC+7", 7*60*60))
+
+	svc := service.NewAPIKeyService(repo, testLogger)
+	_, err := svc.Issue(context.Background(), domain.IssueAPIKeyParams{
+		Name:      "importer",
+		Scopes:    []string{domain.RoleCategoryWrite},
+		ExpiresAt: &expiresAt,
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, stored.ExpiresAt)
+	assert.Equal(t, time.UTC, stored.ExpiresAt.Location())
+	assert.True(t, stored.ExpiresAt.Equal(expiresAt))
+}
+
+// ─── Authenticate ─────────────────────────────────────────────────────────────
+
+func TestAuthenticateAPIKey_Success_RecordsUsageOnFlush(t *testing.T) {
+	key := &domain.APIKey{ID: "key-1", Scopes: []string{domain.RoleCategoryWrite}}
+
+	repo := new(mocks.MockAPIKeyRepository)
+	repo.On("GetByHash", mock.Anything, sha256Hex("csk_secret")).Return(key, nil)
+
+	svc := service.NewAPIKeyService(repo, testLogger)
+	got, err := svc.Authenticate(context.Background(), "csk_secret")
+
+	require.NoError(t, err)
+	assert.Equal(t, key, got)
+	repo.AssertNotCalled(t, "TouchLastUsed")
+
+	repo.On("TouchLastUsed", mock.Anything, "key-1", mock.AnythingOfType("time.Time")).Return(nil).Once()
+	svc.Flush(context.Background())
+	svc.Flush(context.Background())
+
+	repo.AssertExpectations(t)
+}
+
+func TestAuthenticateAPIKey_Rejected(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name   string
+		secret string
+		key    *domain.APIKey
+		err    error
+	}{
+		{"wrong prefix", "not-a-key", nil, nil},
+		{"unknown", "csk_unknown", nil, domain.ErrNotFound},
+		{"revoked", "csk_revoked", &domain.APIKey{ID: "key-1", RevokedAt: &past}, nil},
+		{"expired", "csk_expired", &domain.APIKey{ID: "key-2", ExpiresAt: &past}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.MockAPIKeyRepository)
+			if tt.key != nil || tt.err != nil {
+				repo.On("GetByHash", mock.Anything, sha256Hex(tt.secret)).Return(tt.key, tt.err)
+			}
+
+			svc := service.NewAPIKeyService(repo, testLogger)
+			got, err := svc.Authenticate(context.Background(), tt.secret)
+
+			assert.ErrorIs(t, err, domain.ErrInvalidAPIKey)
+			assert.Nil(t, got)
+
+			svc.Flush(context.Background())
+			repo.AssertNotCalled(t, "TouchLastUsed")
+		})
+	}
+}
+
+func TestAuthenticateAPIKey_RepoError_ReturnsError(t *testing.T) {
+	repo := new(mocks.MockAPIKeyRepository)
+	repo.On("GetByHash", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+
+	svc := service.NewAPIKeyService(repo, testLogger)
+	_, err := svc.Authenticate(context.Background(), "csk_secret")
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.NotErrorIs(t, err, domain.ErrInvalidAPIKey)
+}
+
+// ─── Revoke ───────────────────────────────────────────────────────────────────
+
+func TestRevokeAPIKey_NotFound_ReturnsErrNotFound(t *testing.T) {
+	repo := new(mocks.MockAPIKeyRepository)
+	repo.On("Revoke", mock.Anything, "key-1", mock.AnythingOfType("time.Time")).Return(domain.ErrNotFound)
+
+	svc := service.NewAPIKeyService(repo, testLogger)
+	err := svc.Revoke(context.Background(), " key-1 ")
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestRevokeAPIKey_EmptyID_ReturnsValidationError(t *testing.T) {
+	repo := new(mocks.MockAPIKeyRepository)
+
+	svc := service.NewAPIKeyService(repo, testLogger)
+	err := svc.Revoke(context.Background(), " ")
+
+	var errs *validator.ErrorsValidator
+	require.ErrorAs(t, err, &errs)
+	assert.Equal(t, []string{"api key id is required"}, errs.Messages)
+	repo.AssertNotCalled(t, "Revoke", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/validator"
+)
+
+func (s *CategoryService) Batch(ctx context.Context, p domain.BatchParams) ([]domain.BatchResult, error) {
+	if errs := validator.BatchSizeValidator(len(p.Ops)); errs != nil {
+		return nil, errs
+	}
+
+	results := make([]domain.BatchResult, len(p.Ops))
+
+	if !p.Atomic {
+		for i, op := range p.Ops {
+			results[i] = s.apply(ctx, op)
+		}
+		return results, nil
+	}
+
+	if _, ok := s.tx.(noTx); ok {
+		return nil, domain.ErrTxUnsupported
+	}
+
+	var pending []domain.CategoryEvent
+	failed := -1
+
+	err := s.tx.WithinTx(context.WithValue(ctx, pendingEventsKey{}, &pending), func(ctx context.Context) error {
+		for i, op := range p.Ops {
+			results[i] = s.apply(ctx, op)
+			if results[i].Err != nil {
+				failed = i
+				return results[i].Err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if failed < 0 {
+			return nil, err
+		}
+
+		for i := range results {
+			if i != failed {
+				results[i] = domain.BatchResult{Err: domain.ErrBatchAborted}
+			}
+		}
+		return results, nil
+	}
+
+	for _, e := range pending {
+		s.publish(ctx, e)
+	}
+
+	return results, nil
+}
+
+func (s *CategoryService) apply(ctx context.Context, op domain.BatchOp) domain.BatchResult {
+	var r domain.BatchResult
+
+	switch op.Op {
+	case domain.BatchCreate:
+		r.Category, r.Err = s.Create(ctx, domain.CreateCategoryParams{Name: op.Name, Slug: op.Slug, ParentID: op.ParentID})
+	case domain.BatchUpdate:
+		r.Category, r.Err = s.Update(ctx, op.ID, domain.UpdateCategoryParams{Name: op.Name, Slug: op.Slug, ParentID: op.ParentID, Version: op.Version})
+	case domain.BatchDelete:
+		r.Err = s.Delete(ctx, op.ID, domain.DeleteCategoryParams{Version: op.Version})
+	default:
+		errs := &validator.ErrorsValidator{}
+		errs.Add(fmt.Sprintf("op must be one of %s, %s or %s", domain.BatchCreate, domain.BatchUpdate, domain.BatchDelete))
+		r.Err = errs
+	}
+
+	return r
+}
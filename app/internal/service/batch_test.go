@@ -0,0 +1,127 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/mocks"
+	"github.com/alfattd/category-service/internal/service"
+	"github.com/alfattd/category-service/internal/validator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatch_BestEffort_ContinuesAfterFailure(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	repo.On("Delete", mock.Anything, "missing", int64(0)).Return(nil, domain.ErrNotFound)
+	pub.On("PublishCategoryCreated", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	results, err := svc.Batch(context.Background(), domain.BatchParams{Ops: []domain.BatchOp{
+		{Op: domain.BatchCreate, Name: "Electronics"},
+		{Op: domain.BatchDelete, ID: "missing"},
+		{Op: "rename", ID: "abc-123"},
+		{Op: domain.BatchCreate, Name: "Books"},
+	}})
+
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "Electronics", results[0].Category.Name)
+	assert.ErrorIs(t, results[1].Err, domain.ErrNotFound)
+	var valErrs *validator.ErrorsValidator
+	assert.ErrorAs(t, results[2].Err, &valErrs)
+	assert.NoError(t, results[3].Err)
+
+	pub.AssertNumberOfCalls(t, "PublishCategoryCreated", 2)
+}
+
+func TestBatch_Atomic_PublishesAfterCommit(t *testing.T) {
+	existing := &domain.Category{ID: "abc-123", Name: "Old Name", Slug: "old-name"}
+
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+	tx := new(mocks.MockTransactor)
+
+	var committed bool
+	tx.On("WithinTx", mock.Anything).Return(nil)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Category")).
+		Run(func(mock.Arguments) { committed = true }).
+		Return(nil)
+	pub.On("PublishCategoryCreated", mock.Anything, mock.AnythingOfType("*domain.Category")).
+		Run(func(mock.Arguments) { assert.True(t, committed, "event published before the last write") }).
+		Return(nil)
+	pub.On("PublishCategoryUpdated", mock.Anything, mock.AnythingOfType("*domain.Category"), mock.AnythingOfType("*domain.Category"), []string{domain.FieldName, domain.FieldSlug}).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithTransactor(tx))
+	results, err := svc.Batch(context.Background(), domain.BatchParams{Atomic: true, Ops: []domain.BatchOp{
+		{Op: domain.BatchCreate, Name: "Electronics"},
+		{Op: domain.BatchUpdate, ID: "abc-123", Name: "New Name"},
+	}})
+
+	require.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+	assert.NoError(t, results[1].Err)
+
+	pub.AssertExpectations(t)
+}
+
+func TestBatch_Atomic_FailureRollsBackEverything(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+	tx := new(mocks.MockTransactor)
+
+	tx.On("WithinTx", mock.Anything).Return(nil)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil).Once()
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(domain.ErrDuplicate).Once()
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithTransactor(tx))
+	results, err := svc.Batch(context.Background(), domain.BatchParams{Atomic: true, Ops: []domain.BatchOp{
+		{Op: domain.BatchCreate, Name: "Electronics"},
+		{Op: domain.BatchCreate, Name: "Electronics"},
+		{Op: domain.BatchCreate, Name: "Books"},
+	}})
+
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.ErrorIs(t, results[0].Err, domain.ErrBatchAborted)
+	assert.Nil(t, results[0].Category)
+	assert.ErrorIs(t, results[1].Err, domain.ErrDuplicate)
+	assert.ErrorIs(t, results[2].Err, domain.ErrBatchAborted)
+
+	repo.AssertNumberOfCalls(t, "Create", 2)
+	pub.AssertNotCalled(t, "PublishCategoryCreated")
+}
+
+func TestBatch_AtomicWithoutTransactor_ReturnsErrTxUnsupported(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	_, err := svc.Batch(context.Background(), domain.BatchParams{Atomic: true, Ops: []domain.BatchOp{
+		{Op: domain.BatchCreate, Name: "Electronics"},
+	}})
+
+	assert.ErrorIs(t, err, domain.ErrTxUnsupported)
+	repo.AssertNotCalled(t, "Create")
+}
+
+func TestBatch_Empty_ReturnsValidationError(t *testing.T) {
+	svc := service.NewCategoryService(new(mocks.MockCategoryRepository), new(mocks.MockCategoryEventPublisher), testLogger)
+	_, err := svc.Batch(context.Background(), domain.BatchParams{})
+
+	var valErrs *validator.ErrorsValidator
+	assert.ErrorAs(t, err, &valErrs)
+}
@@ -2,49 +2,80 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/alfattd/category-service/internal/domain"
-	"github.com/alfattd/category-service/internal/pkg/requestid"
+	"github.com/alfattd/category-service/internal/pkg/names"
 	"github.com/alfattd/category-service/internal/validator"
 	"github.com/google/uuid"
 )
 
-func (s *CategoryService) Create(ctx context.Context, name string) (*domain.Category, error) {
-	name = strings.TrimSpace(name)
+func (s *CategoryService) Create(ctx context.Context, p domain.CreateCategoryParams) (*domain.Category, error) {
+	name := names.Normalize(p.Name)
+	slug := strings.TrimSpace(p.Slug)
+	parentID := trimParentID(p.ParentID)
 
 	if errs := validator.CategoryNameValidator(name); errs != nil {
 		return nil, errs
 	}
 
+	if errs := validateSlug(slug); errs != nil {
+		return nil, errs
+	}
+
+	if errs := validator.CategoryParentIDValidator(parentID); errs != nil {
+		return nil, errs
+	}
+
 	now := time.Now()
 
 	category := &domain.Category{
 		ID:        uuid.NewString(),
 		Name:      name,
+		Slug:      slug,
+		ParentID:  parentID,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 
-	if err := s.repo.Create(ctx, category); err != nil {
-		return nil, err
-	}
+	err := s.commit(ctx, func(ctx context.Context) ([]domain.CategoryEvent, error) {
+		// Checked under the lock Move takes, so a concurrent move cannot push the parent past the maximum depth.
+		if parentID != nil {
+			if err := s.repo.LockHierarchy(ctx); err != nil {
+				return nil, err
+			}
+			if err := s.checkParent(ctx, "", *parentID); err != nil {
+				return nil, err
+			}
+		}
 
-	if err := s.publisher.PublishCategoryCreated(ctx, category); err != nil {
-		s.log.Error("failed to publish category_created event",
-			"error", err,
-			"id", category.ID,
-			"request_id", requestid.FromContext(ctx),
-		)
+		if err := s.claimSlug(ctx, category, slug == ""); err != nil {
+			return nil, err
+		}
+
+		if err := s.repo.Create(ctx, category); err != nil {
+			return nil, err
+		}
+		if err := s.record(ctx, domain.RevisionCreated, category.ID, nil, category); err != nil {
+			return nil, err
+		}
+		return []domain.CategoryEvent{{Type: domain.EventCategoryCreated, Category: category}}, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return category, nil
 }
 
-func (s *CategoryService) Update(ctx context.Context, id, name string) (*domain.Category, error) {
+func (s *CategoryService) Update(ctx context.Context, id string, p domain.UpdateCategoryParams) (*domain.Category, error) {
 	id = strings.TrimSpace(id)
-	name = strings.TrimSpace(name)
+	name := names.Normalize(p.Name)
+	slug := strings.TrimSpace(p.Slug)
+	parentID := trimParentID(p.ParentID)
 
 	if errs := validator.CategoryIDValidator(id); errs != nil {
 		return nil, errs
@@ -54,47 +85,327 @@ func (s *CategoryService) Update(ctx context.Context, id, name string) (*domain.
 		return nil, errs
 	}
 
+	if errs := validateSlug(slug); errs != nil {
+		return nil, errs
+	}
+
+	if errs := validator.CategoryParentIDValidator(parentID); errs != nil {
+		return nil, errs
+	}
+
 	category, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	if p.Version != 0 && p.Version != category.Version {
+		return nil, domain.ErrPreconditionFailed
+	}
+
+	before := *category
 	category.Name = name
+	category.ParentID = parentID
+	if slug != "" {
+		category.Slug = slug
+	}
+
+	return s.save(ctx, category, &before)
+}
+
+// Patch applies the operations to the stored category and validates the result like Update.
+// A patch that leaves every field as it was is not written, so it does not bump the version.
+func (s *CategoryService) Patch(ctx context.Context, id string, p domain.PatchCategoryParams) (*domain.Category, error) {
+	id = strings.TrimSpace(id)
+
+	if errs := validator.CategoryIDValidator(id); errs != nil {
+		return nil, errs
+	}
+
+	if errs := validator.CategoryPatchValidator(p.Ops); errs != nil {
+		return nil, errs
+	}
+
+	category, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Version != 0 && p.Version != category.Version {
+		return nil, domain.ErrPreconditionFailed
+	}
+
+	before := *category
+	if err := applyPatch(category, p.Ops); err != nil {
+		return nil, err
+	}
+
+	category.Name = names.Normalize(category.Name)
+	category.Slug = strings.TrimSpace(category.Slug)
+	category.ParentID = trimParentID(category.ParentID)
+
+	if errs := validator.CategoryNameValidator(category.Name); errs != nil {
+		return nil, errs
+	}
+
+	if category.Slug != before.Slug {
+		if errs := validateSlug(category.Slug); errs != nil {
+			return nil, errs
+		}
+	}
+
+	if errs := validator.CategoryParentIDValidator(category.ParentID); errs != nil {
+		return nil, errs
+	}
+
+	if len(changedFields(&before, category)) == 0 {
+		return &before, nil
+	}
+
+	return s.save(ctx, category, &before)
+}
+
+// save checks a changed parent, settles the slug and writes category, whose state before the
+// change is before. A slug the category no longer has keeps resolving to it.
+func (s *CategoryService) save(ctx context.Context, category, before *domain.Category) (*domain.Category, error) {
+	reparented := category.ParentID != nil && !sameValue(before.ParentID, category.ParentID)
+	if reparented && *category.ParentID == category.ID {
+		return nil, fmt.Errorf("%w: category cannot be its own parent", domain.ErrInvalidParent)
+	}
+
+	derive := needsSlug(category, before)
+	claim := derive || category.Slug != before.Slug
 	category.UpdatedAt = time.Now()
 
-	if err := s.repo.Update(ctx, category); err != nil {
+	var (
+		changed   []string
+		unchanged bool
+	)
+	err := s.commit(ctx, func(ctx context.Context) ([]domain.CategoryEvent, error) {
+		// Checked under the lock Move takes, so two concurrent re-parentings cannot together form a cycle.
+		if reparented {
+			if err := s.repo.LockHierarchy(ctx); err != nil {
+				return nil, err
+			}
+			if err := s.checkParent(ctx, category.ID, *category.ParentID); err != nil {
+				return nil, err
+			}
+		}
+
+		if claim {
+			if err := s.claimSlug(ctx, category, derive); err != nil {
+				return nil, err
+			}
+		}
+
+		// Removing a slug the name derives again leaves the category as it was, which is not written.
+		changed = changedFields(before, category)
+		if unchanged = derive && len(changed) == 0; unchanged {
+			return nil, nil
+		}
+
+		if err := s.repo.Update(ctx, category); err != nil {
+			return nil, err
+		}
+		if category.Slug != before.Slug {
+			if err := s.repo.AddFormerSlug(ctx, category.ID, before.Slug); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.record(ctx, domain.RevisionUpdated, category.ID, before, category); err != nil {
+			return nil, err
+		}
+		return []domain.CategoryEvent{{Type: domain.EventCategoryUpdated, Category: category, Before: before, ChangedFields: changed}}, nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	if err := s.publisher.PublishCategoryUpdated(ctx, category); err != nil {
-		s.log.Error("failed to publish category_updated event",
-			"error", err,
-			"id", category.ID,
-			"request_id", requestid.FromContext(ctx),
-		)
+	if unchanged {
+		return before, nil
 	}
 
 	return category, nil
 }
 
-func (s *CategoryService) Delete(ctx context.Context, id string) error {
+func (s *CategoryService) Delete(ctx context.Context, id string, p domain.DeleteCategoryParams) error {
 	id = strings.TrimSpace(id)
 
 	if errs := validator.CategoryIDValidator(id); errs != nil {
 		return errs
 	}
 
-	if err := s.repo.Delete(ctx, id); err != nil {
+	if p.Purge {
+		return s.purge(ctx, id, p.Version)
+	}
+
+	return s.commit(ctx, func(ctx context.Context) ([]domain.CategoryEvent, error) {
+		deleted, err := s.repo.Delete(ctx, id, p.Version)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.record(ctx, domain.RevisionDeleted, id, deleted, nil); err != nil {
+			return nil, err
+		}
+		return []domain.CategoryEvent{{Type: domain.EventCategoryDeleted, Category: deleted}}, nil
+	})
+}
+
+// purge only announces the deletion when the category was still live; a soft delete already did otherwise.
+func (s *CategoryService) purge(ctx context.Context, id string, version int64) error {
+	return s.commit(ctx, func(ctx context.Context) ([]domain.CategoryEvent, error) {
+		purged, err := s.repo.Purge(ctx, id, version)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.record(ctx, domain.RevisionPurged, id, purged, nil); err != nil {
+			return nil, err
+		}
+		if purged.DeletedAt != nil {
+			return nil, nil
+		}
+		return []domain.CategoryEvent{{Type: domain.EventCategoryDeleted, Category: purged}}, nil
+	})
+}
+
+func (s *CategoryService) Restore(ctx context.Context, id string) (*domain.Category, error) {
+	id = strings.TrimSpace(id)
+
+	if errs := validator.CategoryIDValidator(id); errs != nil {
+		return nil, errs
+	}
+
+	var category *domain.Category
+
+	err := s.commit(ctx, func(ctx context.Context) ([]domain.CategoryEvent, error) {
+		var err error
+		if category, err = s.repo.Restore(ctx, id); err != nil {
+			return nil, err
+		}
+		if err := s.record(ctx, domain.RevisionRestored, id, nil, category); err != nil {
+			return nil, err
+		}
+		return []domain.CategoryEvent{{Type: domain.EventCategoryRestored, Category: category}}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *CategoryService) Move(ctx context.Context, id string, parentID *string) (*domain.Category, error) {
+	id = strings.TrimSpace(id)
+	parentID = trimParentID(parentID)
+
+	if errs := validator.CategoryIDValidator(id); errs != nil {
+		return nil, errs
+	}
+
+	if errs := validator.CategoryParentIDValidator(parentID); errs != nil {
+		return nil, errs
+	}
+
+	if parentID != nil && *parentID == id {
+		return nil, fmt.Errorf("%w: category cannot be its own parent", domain.ErrInvalidParent)
+	}
+
+	var move *domain.CategoryMove
+
+	err := s.commit(ctx, func(ctx context.Context) ([]domain.CategoryEvent, error) {
+		var err error
+		if move, err = s.repo.Move(ctx, id, parentID, s.maxDepth); err != nil {
+			return nil, err
+		}
+		if err := s.record(ctx, domain.RevisionMoved, id, move.Before, move.Category); err != nil {
+			return nil, err
+		}
+		return []domain.CategoryEvent{{
+			Type:        domain.EventCategoryMoved,
+			Category:    move.Category,
+			OldParentID: move.OldParentID,
+		}}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return move.Category, nil
+}
+
+func (s *CategoryService) getParent(ctx context.Context, parentID string) (*domain.Category, error) {
+	parent, err := s.repo.GetByID(ctx, parentID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("%w: parent %s does not exist", domain.ErrInvalidParent, parentID)
+		}
+		return nil, err
+	}
+
+	return parent, nil
+}
+
+// checkParent verifies that parentID can hold the category id (empty for a new category)
+// without creating a cycle or exceeding the maximum depth.
+func (s *CategoryService) checkParent(ctx context.Context, id, parentID string) error {
+	if parentID == id {
+		return fmt.Errorf("%w: category cannot be its own parent", domain.ErrInvalidParent)
+	}
+
+	if _, err := s.getParent(ctx, parentID); err != nil {
+		return err
+	}
+
+	ancestors, err := s.repo.ListAncestors(ctx, parentID)
+	if err != nil {
 		return err
 	}
 
-	if err := s.publisher.PublishCategoryDeleted(ctx, id); err != nil {
-		s.log.Error("failed to publish category_deleted event",
-			"error", err,
-			"id", id,
-			"request_id", requestid.FromContext(ctx),
-		)
+	for _, a := range ancestors {
+		if a.ID == id {
+			return fmt.Errorf("%w: category cannot be moved under its own descendant", domain.ErrInvalidParent)
+		}
+	}
+
+	height := 1
+	if id != "" {
+		if height, err = s.repo.SubtreeHeight(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	if len(ancestors)+1+height > s.maxDepth {
+		return fmt.Errorf("%w: hierarchy is limited to %d levels", domain.ErrMaxDepthExceeded, s.maxDepth)
 	}
 
 	return nil
 }
+
+func trimParentID(parentID *string) *string {
+	if parentID == nil {
+		return nil
+	}
+
+	trimmed := strings.TrimSpace(*parentID)
+	return &trimmed
+}
+
+func sameValue(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func changedFields(before, after *domain.Category) []string {
+	var changed []string
+	if before.Name != after.Name {
+		changed = append(changed, domain.FieldName)
+	}
+	if before.Slug != after.Slug {
+		changed = append(changed, domain.FieldSlug)
+	}
+	if !sameValue(before.ParentID, after.ParentID) {
+		changed = append(changed, domain.FieldParentID)
+	}
+	return changed
+}
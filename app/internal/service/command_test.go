@@ -6,9 +6,11 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/alfattd/category-service/internal/domain"
 	"github.com/alfattd/category-service/internal/mocks"
+	"github.com/alfattd/category-service/internal/pkg/eventmeta"
 	"github.com/alfattd/category-service/internal/service"
 	"github.com/alfattd/category-service/internal/validator"
 	"github.com/stretchr/testify/assert"
@@ -18,17 +20,30 @@ import (
 
 var testLogger = slog.New(slog.NewTextHandler(os.Stdout, nil))
 
+// freeSlugs makes every slug derived from a name available and accepts keeping former slugs.
+func freeSlugs(repo *mocks.MockCategoryRepository) {
+	repo.On("LockSlug", mock.Anything, mock.Anything).Return(nil).Maybe()
+	repo.On("ListSlugs", mock.Anything, mock.Anything).Return(map[string]string{}, nil).Maybe()
+	repo.On("AddFormerSlug", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+}
+
+// categoryWithID matches a category argument by its id.
+func categoryWithID(id string) any {
+	return mock.MatchedBy(func(c *domain.Category) bool { return c != nil && c.ID == id })
+}
+
 // ─── Create ───────────────────────────────────────────────────────────────────
 
 func TestCreate_Success(t *testing.T) {
 	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
 	pub := new(mocks.MockCategoryEventPublisher)
 
 	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
 	pub.On("PublishCategoryCreated", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
-	cat, err := svc.Create(context.Background(), "Electronics")
+	cat, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: "Electronics"})
 
 	assert.NoError(t, err)
 	assert.NotNil(t, cat)
@@ -41,13 +56,14 @@ func TestCreate_Success(t *testing.T) {
 
 func TestCreate_TrimsWhitespace(t *testing.T) {
 	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
 	pub := new(mocks.MockCategoryEventPublisher)
 
 	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
 	pub.On("PublishCategoryCreated", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
-	cat, err := svc.Create(context.Background(), "  Electronics  ")
+	cat, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: "  Electronics  "})
 
 	assert.NoError(t, err)
 	require.NotNil(t, cat)
@@ -56,12 +72,37 @@ func TestCreate_TrimsWhitespace(t *testing.T) {
 	repo.AssertExpectations(t)
 }
 
+func TestCreate_NormalizesName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Home \t  Garden", "Home Garden"},
+		{"Cafe\u0301", "Caf\u00e9"},
+	}
+
+	for _, tt := range tests {
+		repo := new(mocks.MockCategoryRepository)
+		freeSlugs(repo)
+		pub := new(mocks.MockCategoryEventPublisher)
+
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+		pub.On("PublishCategoryCreated", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+
+		svc := service.NewCategoryService(repo, pub, testLogger)
+		cat, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: tt.name})
+
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, cat.Name)
+	}
+}
+
 func TestCreate_EmptyName_ReturnsValidationError(t *testing.T) {
 	repo := new(mocks.MockCategoryRepository)
 	pub := new(mocks.MockCategoryEventPublisher)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
-	cat, err := svc.Create(context.Background(), "")
+	cat, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: ""})
 
 	require.Error(t, err)
 	assert.Nil(t, cat)
@@ -78,7 +119,7 @@ func TestCreate_WhitespaceOnlyName_ReturnsValidationError(t *testing.T) {
 	pub := new(mocks.MockCategoryEventPublisher)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
-	cat, err := svc.Create(context.Background(), "   ")
+	cat, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: "   "})
 
 	require.Error(t, err)
 	assert.Nil(t, cat)
@@ -94,7 +135,7 @@ func TestCreate_NameTooLong_ReturnsValidationError(t *testing.T) {
 	pub := new(mocks.MockCategoryEventPublisher)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
-	cat, err := svc.Create(context.Background(), strings.Repeat("a", 21))
+	cat, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: strings.Repeat("a", 21)})
 
 	require.Error(t, err)
 	assert.Nil(t, cat)
@@ -111,7 +152,7 @@ func TestCreate_ForbiddenCharacters_ReturnsValidationError(t *testing.T) {
 	pub := new(mocks.MockCategoryEventPublisher)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
-	cat, err := svc.Create(context.Background(), "<script>")
+	cat, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: "<script>"})
 
 	require.Error(t, err)
 	assert.Nil(t, cat)
@@ -125,12 +166,13 @@ func TestCreate_ForbiddenCharacters_ReturnsValidationError(t *testing.T) {
 
 func TestCreate_RepoError_ReturnsError(t *testing.T) {
 	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
 	pub := new(mocks.MockCategoryEventPublisher)
 
 	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(domain.ErrDuplicate)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
-	cat, err := svc.Create(context.Background(), "Electronics")
+	cat, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: "Electronics"})
 
 	assert.ErrorIs(t, err, domain.ErrDuplicate)
 	assert.Nil(t, cat)
@@ -140,49 +182,141 @@ func TestCreate_RepoError_ReturnsError(t *testing.T) {
 
 func TestCreate_PublishError_StillReturnsCategory(t *testing.T) {
 	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
 	pub := new(mocks.MockCategoryEventPublisher)
 
 	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
 	pub.On("PublishCategoryCreated", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(assert.AnError)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
-	cat, err := svc.Create(context.Background(), "Electronics")
+	cat, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: "Electronics"})
 
 	assert.NoError(t, err)
 	assert.NotNil(t, cat)
 }
 
+func TestCreate_WithParent_Success(t *testing.T) {
+	parentID := "parent-1"
+
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("LockHierarchy", mock.Anything).Return(nil)
+	repo.On("GetByID", mock.Anything, parentID).Return(&domain.Category{ID: parentID, Name: "Electronics", Slug: "electronics"}, nil)
+	repo.On("ListAncestors", mock.Anything, parentID).Return([]*domain.Category{}, nil)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	pub.On("PublishCategoryCreated", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: "Phones", ParentID: &parentID})
+
+	require.NoError(t, err)
+	require.NotNil(t, cat.ParentID)
+	assert.Equal(t, parentID, *cat.ParentID)
+
+	repo.AssertExpectations(t)
+}
+
+func TestCreate_WithParent_ChecksParentUnderHierarchyLock(t *testing.T) {
+	parentID := "parent-1"
+
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	tx := new(mocks.MockTransactor)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	var calls []string
+	record := func(name string) func(mock.Arguments) {
+		return func(mock.Arguments) { calls = append(calls, name) }
+	}
+
+	tx.On("WithinTx", mock.Anything).Run(record("WithinTx")).Return(nil)
+	repo.On("LockHierarchy", mock.Anything).Run(record("LockHierarchy")).Return(nil)
+	repo.On("GetByID", mock.Anything, parentID).Run(record("GetByID")).Return(&domain.Category{ID: parentID}, nil)
+	repo.On("ListAncestors", mock.Anything, parentID).Run(record("ListAncestors")).Return([]*domain.Category{}, nil)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Run(record("Create")).Return(nil)
+	pub.On("PublishCategoryCreated", mock.Anything, mock.Anything).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithTransactor(tx))
+	_, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: "Phones", ParentID: &parentID})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"WithinTx", "LockHierarchy", "GetByID", "ListAncestors", "Create"}, calls)
+}
+
+func TestCreate_ParentNotFound_ReturnsErrInvalidParent(t *testing.T) {
+	parentID := "not-exist"
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	freeSlugs(repo)
+	repo.On("LockHierarchy", mock.Anything).Return(nil)
+	repo.On("GetByID", mock.Anything, parentID).Return(nil, domain.ErrNotFound)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: "Phones", ParentID: &parentID})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidParent)
+	assert.Nil(t, cat)
+
+	repo.AssertNotCalled(t, "Create")
+}
+
+func TestCreate_ParentAtMaxDepth_ReturnsErrMaxDepthExceeded(t *testing.T) {
+	parentID := "level-2"
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	freeSlugs(repo)
+	repo.On("LockHierarchy", mock.Anything).Return(nil)
+	repo.On("GetByID", mock.Anything, parentID).Return(&domain.Category{ID: parentID}, nil)
+	repo.On("ListAncestors", mock.Anything, parentID).Return([]*domain.Category{{ID: "level-1"}}, nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithMaxDepth(2))
+	cat, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: "Phones", ParentID: &parentID})
+
+	assert.ErrorIs(t, err, domain.ErrMaxDepthExceeded)
+	assert.Nil(t, cat)
+
+	repo.AssertNotCalled(t, "Create")
+}
+
 // ─── Update ───────────────────────────────────────────────────────────────────
 
 func TestUpdate_Success(t *testing.T) {
-	existing := &domain.Category{ID: "abc-123", Name: "Old Name"}
+	existing := &domain.Category{ID: "abc-123", Name: "Old Name", Slug: "old-name"}
 
 	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
 	pub := new(mocks.MockCategoryEventPublisher)
 
 	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
 	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
-	pub.On("PublishCategoryUpdated", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	pub.On("PublishCategoryUpdated", mock.Anything, mock.AnythingOfType("*domain.Category"), mock.AnythingOfType("*domain.Category"), mock.Anything).Return(nil)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
-	cat, err := svc.Update(context.Background(), "abc-123", "New Name")
+	cat, err := svc.Update(context.Background(), "abc-123", domain.UpdateCategoryParams{Name: "New Name"})
 
 	assert.NoError(t, err)
 	assert.Equal(t, "New Name", cat.Name)
 }
 
 func TestUpdate_TrimsWhitespace(t *testing.T) {
-	existing := &domain.Category{ID: "abc-123", Name: "Old Name"}
+	existing := &domain.Category{ID: "abc-123", Name: "Old Name", Slug: "old-name"}
 
 	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
 	pub := new(mocks.MockCategoryEventPublisher)
 
 	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
 	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
-	pub.On("PublishCategoryUpdated", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	pub.On("PublishCategoryUpdated", mock.Anything, mock.AnythingOfType("*domain.Category"), mock.AnythingOfType("*domain.Category"), mock.Anything).Return(nil)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
-	cat, err := svc.Update(context.Background(), "abc-123", "  New Name  ")
+	cat, err := svc.Update(context.Background(), "abc-123", domain.UpdateCategoryParams{Name: "  New Name  "})
 
 	assert.NoError(t, err)
 	require.NotNil(t, cat)
@@ -194,7 +328,7 @@ func TestUpdate_EmptyID_ReturnsValidationError(t *testing.T) {
 	pub := new(mocks.MockCategoryEventPublisher)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
-	cat, err := svc.Update(context.Background(), "", "New Name")
+	cat, err := svc.Update(context.Background(), "", domain.UpdateCategoryParams{Name: "New Name"})
 
 	require.Error(t, err)
 	assert.Nil(t, cat)
@@ -210,7 +344,7 @@ func TestUpdate_InvalidName_ReturnsValidationError(t *testing.T) {
 	pub := new(mocks.MockCategoryEventPublisher)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
-	cat, err := svc.Update(context.Background(), "abc-123", "<bad>")
+	cat, err := svc.Update(context.Background(), "abc-123", domain.UpdateCategoryParams{Name: "<bad>"})
 
 	require.Error(t, err)
 	assert.Nil(t, cat)
@@ -228,25 +362,342 @@ func TestUpdate_NotFound_ReturnsErrNotFound(t *testing.T) {
 	repo.On("GetByID", mock.Anything, "not-exist").Return(nil, domain.ErrNotFound)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
-	cat, err := svc.Update(context.Background(), "not-exist", "New Name")
+	cat, err := svc.Update(context.Background(), "not-exist", domain.UpdateCategoryParams{Name: "New Name"})
 
 	assert.ErrorIs(t, err, domain.ErrNotFound)
 	assert.Nil(t, cat)
 }
 
+func TestUpdate_StaleVersion_ReturnsErrPreconditionFailed(t *testing.T) {
+	existing := &domain.Category{ID: "abc-123", Name: "Old Name", Slug: "old-name", Version: 3}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Update(context.Background(), "abc-123", domain.UpdateCategoryParams{Name: "New Name", Version: 2})
+
+	assert.ErrorIs(t, err, domain.ErrPreconditionFailed)
+	assert.Nil(t, cat)
+	repo.AssertNotCalled(t, "Update")
+}
+
+func TestUpdate_SelfParent_ReturnsErrInvalidParent(t *testing.T) {
+	existing := &domain.Category{ID: "abc-123", Name: "Old Name", Slug: "old-name"}
+	parentID := "abc-123"
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Update(context.Background(), "abc-123", domain.UpdateCategoryParams{Name: "New Name", ParentID: &parentID})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidParent)
+	assert.Nil(t, cat)
+
+	repo.AssertNotCalled(t, "Update")
+}
+
+func TestUpdate_ParentIsDescendant_ReturnsErrInvalidParent(t *testing.T) {
+	existing := &domain.Category{ID: "root", Name: "Electronics", Slug: "electronics"}
+	grandchildID := "grandchild"
+	childID := "child"
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, "root").Return(existing, nil)
+	repo.On("GetByID", mock.Anything, grandchildID).Return(&domain.Category{ID: grandchildID, ParentID: &childID}, nil)
+	repo.On("LockHierarchy", mock.Anything).Return(nil)
+	repo.On("ListAncestors", mock.Anything, grandchildID).Return([]*domain.Category{
+		{ID: "root"},
+		{ID: childID},
+	}, nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Update(context.Background(), "root", domain.UpdateCategoryParams{Name: "Electronics", ParentID: &grandchildID})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidParent)
+	assert.Nil(t, cat)
+
+	repo.AssertNotCalled(t, "Update")
+}
+
+func TestUpdate_ChangeParent_ChecksParentUnderHierarchyLock(t *testing.T) {
+	existing := &domain.Category{ID: "abc-123", Name: "Phones", Slug: "phones"}
+	parentID := "parent-1"
+
+	repo := new(mocks.MockCategoryRepository)
+	tx := new(mocks.MockTransactor)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	var calls []string
+	record := func(name string) func(mock.Arguments) {
+		return func(mock.Arguments) { calls = append(calls, name) }
+	}
+
+	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
+	tx.On("WithinTx", mock.Anything).Run(record("WithinTx")).Return(nil)
+	repo.On("LockHierarchy", mock.Anything).Run(record("LockHierarchy")).Return(nil)
+	repo.On("GetByID", mock.Anything, parentID).Run(record("GetByID")).Return(&domain.Category{ID: parentID}, nil)
+	repo.On("ListAncestors", mock.Anything, parentID).Run(record("ListAncestors")).Return([]*domain.Category{}, nil)
+	repo.On("SubtreeHeight", mock.Anything, "abc-123").Run(record("SubtreeHeight")).Return(1, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Category")).Run(record("Update")).Return(nil)
+	pub.On("PublishCategoryUpdated", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithTransactor(tx))
+	_, err := svc.Update(context.Background(), "abc-123", domain.UpdateCategoryParams{Name: "Phones", ParentID: &parentID})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"WithinTx", "LockHierarchy", "GetByID", "ListAncestors", "SubtreeHeight", "Update"}, calls)
+}
+
+func TestUpdate_ChangeParent_Success(t *testing.T) {
+	existing := &domain.Category{ID: "abc-123", Name: "Phones", Slug: "phones"}
+	parentID := "parent-1"
+
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
+	repo.On("GetByID", mock.Anything, parentID).Return(&domain.Category{ID: parentID}, nil)
+	repo.On("LockHierarchy", mock.Anything).Return(nil)
+	repo.On("ListAncestors", mock.Anything, parentID).Return([]*domain.Category{}, nil)
+	repo.On("SubtreeHeight", mock.Anything, "abc-123").Return(1, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	pub.On("PublishCategoryUpdated", mock.Anything, mock.AnythingOfType("*domain.Category"), mock.AnythingOfType("*domain.Category"), mock.Anything).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Update(context.Background(), "abc-123", domain.UpdateCategoryParams{Name: "Phones", ParentID: &parentID})
+
+	require.NoError(t, err)
+	require.NotNil(t, cat.ParentID)
+	assert.Equal(t, parentID, *cat.ParentID)
+
+	repo.AssertExpectations(t)
+}
+
+func TestUpdate_PublishesPreviousValuesAndChangedFields(t *testing.T) {
+	existing := &domain.Category{ID: "abc-123", Name: "Old Name", Slug: "old-name", Version: 4}
+
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	pub.On("PublishCategoryUpdated", mock.Anything,
+		mock.MatchedBy(func(before *domain.Category) bool {
+			return before.Name == "Old Name" && before.Slug == "old-name" && before.Version == 4
+		}),
+		mock.MatchedBy(func(after *domain.Category) bool {
+			return after.Name == "New Name" && after.Slug == "new-name"
+		}),
+		[]string{domain.FieldName, domain.FieldSlug},
+	).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	_, err := svc.Update(context.Background(), "abc-123", domain.UpdateCategoryParams{Name: "New Name"})
+
+	require.NoError(t, err)
+	pub.AssertExpectations(t)
+}
+
+// ─── Patch ────────────────────────────────────────────────────────────────────
+
+func TestPatch_OnlyChangesSuppliedFields(t *testing.T) {
+	parentID := "parent-1"
+	existing := &domain.Category{ID: "abc-123", Name: "Phones", Slug: "phones", ParentID: &parentID, Version: 2}
+
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	pub.On("PublishCategoryUpdated", mock.Anything, mock.AnythingOfType("*domain.Category"), mock.AnythingOfType("*domain.Category"), []string{domain.FieldName, domain.FieldSlug}).Return(nil)
+
+	newName := "  Smartphones  "
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Patch(context.Background(), "abc-123", domain.PatchCategoryParams{
+		Ops:     []domain.PatchOp{{Op: domain.PatchReplace, Path: domain.FieldName, Value: &newName}},
+		Version: 2,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Smartphones", cat.Name)
+	require.NotNil(t, cat.ParentID)
+	assert.Equal(t, parentID, *cat.ParentID)
+
+	repo.AssertNotCalled(t, "ListAncestors", mock.Anything, mock.Anything)
+	pub.AssertExpectations(t)
+}
+
+func TestPatch_RemoveParent_MovesToRoot(t *testing.T) {
+	parentID := "parent-1"
+	existing := &domain.Category{ID: "abc-123", Name: "Phones", Slug: "phones", ParentID: &parentID}
+
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	pub.On("PublishCategoryUpdated", mock.Anything, mock.AnythingOfType("*domain.Category"), mock.AnythingOfType("*domain.Category"), []string{domain.FieldParentID}).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Patch(context.Background(), "abc-123", domain.PatchCategoryParams{
+		Ops: []domain.PatchOp{{Op: domain.PatchRemove, Path: domain.FieldParentID}},
+	})
+
+	require.NoError(t, err)
+	assert.Nil(t, cat.ParentID)
+	assert.Equal(t, "Phones", cat.Name)
+}
+
+func TestPatch_NewParent_ChecksHierarchy(t *testing.T) {
+	existing := &domain.Category{ID: "abc-123", Name: "Phones", Slug: "phones"}
+	parentID := "level-2"
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
+	repo.On("GetByID", mock.Anything, parentID).Return(&domain.Category{ID: parentID}, nil)
+	repo.On("LockHierarchy", mock.Anything).Return(nil)
+	repo.On("ListAncestors", mock.Anything, parentID).Return([]*domain.Category{{ID: "level-1"}}, nil)
+	repo.On("SubtreeHeight", mock.Anything, "abc-123").Return(1, nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithMaxDepth(2))
+	cat, err := svc.Patch(context.Background(), "abc-123", domain.PatchCategoryParams{
+		Ops: []domain.PatchOp{{Op: domain.PatchAdd, Path: domain.FieldParentID, Value: &parentID}},
+	})
+
+	assert.ErrorIs(t, err, domain.ErrMaxDepthExceeded)
+	assert.Nil(t, cat)
+
+	repo.AssertNotCalled(t, "Update")
+}
+
+func TestPatch_FailedTest_ReturnsErrPatchTestFailed(t *testing.T) {
+	existing := &domain.Category{ID: "abc-123", Name: "Phones", Slug: "phones"}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
+
+	expected, newName := "Tablets", "Smartphones"
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Patch(context.Background(), "abc-123", domain.PatchCategoryParams{
+		Ops: []domain.PatchOp{
+			{Op: domain.PatchTest, Path: domain.FieldName, Value: &expected},
+			{Op: domain.PatchReplace, Path: domain.FieldName, Value: &newName},
+		},
+	})
+
+	assert.ErrorIs(t, err, domain.ErrPatchTestFailed)
+	assert.Nil(t, cat)
+
+	repo.AssertNotCalled(t, "Update")
+}
+
+func TestPatch_RemoveName_ReturnsValidationError(t *testing.T) {
+	existing := &domain.Category{ID: "abc-123", Name: "Phones", Slug: "phones"}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	_, err := svc.Patch(context.Background(), "abc-123", domain.PatchCategoryParams{
+		Ops: []domain.PatchOp{{Op: domain.PatchRemove, Path: domain.FieldName}},
+	})
+
+	var valErrs *validator.ErrorsValidator
+	require.ErrorAs(t, err, &valErrs)
+	assert.Contains(t, valErrs.Messages, "name is required")
+
+	repo.AssertNotCalled(t, "Update")
+}
+
+func TestPatch_UnknownPath_ReturnsValidationErrorWithoutLookup(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	_, err := svc.Patch(context.Background(), "abc-123", domain.PatchCategoryParams{
+		Ops: []domain.PatchOp{{Op: domain.PatchRemove, Path: "version"}},
+	})
+
+	var valErrs *validator.ErrorsValidator
+	require.ErrorAs(t, err, &valErrs)
+
+	repo.AssertNotCalled(t, "GetByID")
+}
+
+func TestPatch_NoChanges_SkipsWrite(t *testing.T) {
+	existing := &domain.Category{ID: "abc-123", Name: "Phones", Slug: "phones", Version: 4}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
+
+	name := "Phones"
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Patch(context.Background(), "abc-123", domain.PatchCategoryParams{
+		Ops: []domain.PatchOp{{Op: domain.PatchReplace, Path: domain.FieldName, Value: &name}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), cat.Version)
+
+	repo.AssertNotCalled(t, "Update")
+	pub.AssertNotCalled(t, "PublishCategoryUpdated")
+}
+
+func TestPatch_StaleVersion_ReturnsErrPreconditionFailed(t *testing.T) {
+	existing := &domain.Category{ID: "abc-123", Name: "Phones", Slug: "phones", Version: 3}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
+
+	name := "Smartphones"
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	_, err := svc.Patch(context.Background(), "abc-123", domain.PatchCategoryParams{
+		Ops:     []domain.PatchOp{{Op: domain.PatchReplace, Path: domain.FieldName, Value: &name}},
+		Version: 2,
+	})
+
+	assert.ErrorIs(t, err, domain.ErrPreconditionFailed)
+	repo.AssertNotCalled(t, "Update")
+}
+
 // ─── Delete ───────────────────────────────────────────────────────────────────
 
 func TestDelete_Success(t *testing.T) {
 	repo := new(mocks.MockCategoryRepository)
 	pub := new(mocks.MockCategoryEventPublisher)
 
-	repo.On("Delete", mock.Anything, "abc-123").Return(nil)
-	pub.On("PublishCategoryDeleted", mock.Anything, "abc-123").Return(nil)
+	last := &domain.Category{ID: "abc-123", Name: "Phones", Slug: "phones", Version: 3}
+
+	repo.On("Delete", mock.Anything, "abc-123", int64(0)).Return(last, nil)
+	pub.On("PublishCategoryDeleted", mock.Anything, last).Return(nil)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
-	err := svc.Delete(context.Background(), "abc-123")
+	err := svc.Delete(context.Background(), "abc-123", domain.DeleteCategoryParams{})
 
 	assert.NoError(t, err)
+	pub.AssertExpectations(t)
 }
 
 func TestDelete_EmptyID_ReturnsValidationError(t *testing.T) {
@@ -254,7 +705,7 @@ func TestDelete_EmptyID_ReturnsValidationError(t *testing.T) {
 	pub := new(mocks.MockCategoryEventPublisher)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
-	err := svc.Delete(context.Background(), "")
+	err := svc.Delete(context.Background(), "", domain.DeleteCategoryParams{})
 
 	require.Error(t, err)
 
@@ -268,23 +719,268 @@ func TestDelete_NotFound_ReturnsErrNotFound(t *testing.T) {
 	repo := new(mocks.MockCategoryRepository)
 	pub := new(mocks.MockCategoryEventPublisher)
 
-	repo.On("Delete", mock.Anything, "not-exist").Return(domain.ErrNotFound)
+	repo.On("Delete", mock.Anything, "not-exist", int64(0)).Return(nil, domain.ErrNotFound)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
-	err := svc.Delete(context.Background(), "not-exist")
+	err := svc.Delete(context.Background(), "not-exist", domain.DeleteCategoryParams{})
 
 	assert.ErrorIs(t, err, domain.ErrNotFound)
 }
 
+func TestDelete_HasChildren_ReturnsErrHasChildren(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Delete", mock.Anything, "abc-123", int64(0)).Return(nil, domain.ErrHasChildren)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	err := svc.Delete(context.Background(), "abc-123", domain.DeleteCategoryParams{})
+
+	assert.ErrorIs(t, err, domain.ErrHasChildren)
+
+	pub.AssertNotCalled(t, "PublishCategoryDeleted")
+}
+
 func TestDelete_PublishError_StillReturnsNil(t *testing.T) {
 	repo := new(mocks.MockCategoryRepository)
 	pub := new(mocks.MockCategoryEventPublisher)
 
-	repo.On("Delete", mock.Anything, "abc-123").Return(nil)
-	pub.On("PublishCategoryDeleted", mock.Anything, "abc-123").Return(assert.AnError)
+	repo.On("Delete", mock.Anything, "abc-123", int64(0)).Return(&domain.Category{ID: "abc-123"}, nil)
+	pub.On("PublishCategoryDeleted", mock.Anything, categoryWithID("abc-123")).Return(assert.AnError)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
-	err := svc.Delete(context.Background(), "abc-123")
+	err := svc.Delete(context.Background(), "abc-123", domain.DeleteCategoryParams{})
 
 	assert.NoError(t, err)
 }
+
+func TestDelete_PurgeLiveCategory_PublishesDeleted(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Purge", mock.Anything, "abc-123", int64(2)).Return(&domain.Category{ID: "abc-123"}, nil)
+	pub.On("PublishCategoryDeleted", mock.Anything, categoryWithID("abc-123")).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	err := svc.Delete(context.Background(), "abc-123", domain.DeleteCategoryParams{Version: 2, Purge: true})
+
+	assert.NoError(t, err)
+	repo.AssertNotCalled(t, "Delete")
+	pub.AssertExpectations(t)
+}
+
+func TestDelete_PurgeSoftDeletedCategory_DoesNotPublish(t *testing.T) {
+	deletedAt := time.Now()
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Purge", mock.Anything, "abc-123", int64(0)).Return(&domain.Category{ID: "abc-123", DeletedAt: &deletedAt}, nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	err := svc.Delete(context.Background(), "abc-123", domain.DeleteCategoryParams{Purge: true})
+
+	assert.NoError(t, err)
+	pub.AssertNotCalled(t, "PublishCategoryDeleted")
+}
+
+// ─── Restore ──────────────────────────────────────────────────────────────────
+
+func TestRestore_Success_PublishesRestored(t *testing.T) {
+	restored := &domain.Category{ID: "abc-123", Name: "Electronics", Slug: "electronics", Version: 3}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Restore", mock.Anything, "abc-123").Return(restored, nil)
+	pub.On("PublishCategoryRestored", mock.Anything, restored).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Restore(context.Background(), " abc-123 ")
+
+	require.NoError(t, err)
+	assert.Equal(t, restored, cat)
+	pub.AssertExpectations(t)
+}
+
+func TestRestore_NotDeleted_ReturnsErrNotDeleted(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Restore", mock.Anything, "abc-123").Return(nil, domain.ErrNotDeleted)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Restore(context.Background(), "abc-123")
+
+	assert.ErrorIs(t, err, domain.ErrNotDeleted)
+	assert.Nil(t, cat)
+	pub.AssertNotCalled(t, "PublishCategoryRestored")
+}
+
+// ─── Move ─────────────────────────────────────────────────────────────────────
+
+func TestMove_Success(t *testing.T) {
+	oldParentID := "old-parent"
+	newParentID := "new-parent"
+	moved := &domain.Category{ID: "abc-123", Name: "Phones", Slug: "phones", ParentID: &newParentID}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Move", mock.Anything, "abc-123", &newParentID, 5).
+		Return(&domain.CategoryMove{Category: moved, OldParentID: &oldParentID}, nil)
+	pub.On("PublishCategoryMoved", mock.Anything, moved, &oldParentID).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Move(context.Background(), "abc-123", &newParentID)
+
+	require.NoError(t, err)
+	assert.Equal(t, moved, cat)
+
+	repo.AssertExpectations(t)
+	pub.AssertExpectations(t)
+}
+
+func TestMove_ToRoot_PassesNilParent(t *testing.T) {
+	oldParentID := "old-parent"
+	moved := &domain.Category{ID: "abc-123", Name: "Phones", Slug: "phones"}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Move", mock.Anything, "abc-123", (*string)(nil), 3).
+		Return(&domain.CategoryMove{Category: moved, OldParentID: &oldParentID}, nil)
+	pub.On("PublishCategoryMoved", mock.Anything, moved, &oldParentID).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithMaxDepth(3))
+	_, err := svc.Move(context.Background(), "abc-123", nil)
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestMove_SelfParent_ReturnsErrInvalidParent(t *testing.T) {
+	id := "abc-123"
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Move(context.Background(), id, &id)
+
+	assert.ErrorIs(t, err, domain.ErrInvalidParent)
+	assert.Nil(t, cat)
+
+	repo.AssertNotCalled(t, "Move")
+}
+
+func TestMove_RepoError_NotPublished(t *testing.T) {
+	parentID := "parent-1"
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Move", mock.Anything, "abc-123", &parentID, 5).Return(nil, domain.ErrMaxDepthExceeded)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Move(context.Background(), "abc-123", &parentID)
+
+	assert.ErrorIs(t, err, domain.ErrMaxDepthExceeded)
+	assert.Nil(t, cat)
+
+	pub.AssertNotCalled(t, "PublishCategoryMoved")
+}
+
+// ─── Outbox ───────────────────────────────────────────────────────────────────
+
+func TestCreate_WithOutbox_RecordsEventInsteadOfPublishing(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+	tx := new(mocks.MockTransactor)
+	outbox := new(mocks.MockOutboxRepository)
+
+	tx.On("WithinTx", mock.Anything).Return(nil)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	outbox.On("Add", mock.Anything, mock.MatchedBy(func(events []domain.CategoryEvent) bool {
+		return len(events) == 1 && events[0].Type == domain.EventCategoryCreated && events[0].Category.Name == "Electronics" &&
+			events[0].ID != "" && !events[0].OccurredAt.IsZero()
+	})).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithTransactor(tx), service.WithOutbox(outbox))
+	cat, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: "Electronics"})
+
+	require.NoError(t, err)
+	assert.NotNil(t, cat)
+
+	tx.AssertExpectations(t)
+	outbox.AssertExpectations(t)
+	pub.AssertNotCalled(t, "PublishCategoryCreated")
+}
+
+func TestCreate_PublishesWithEventIdentity(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	pub.On("PublishCategoryCreated", mock.MatchedBy(func(ctx context.Context) bool {
+		m, ok := eventmeta.FromContext(ctx)
+		return ok && m.ID != "" && !m.Time.IsZero()
+	}), mock.AnythingOfType("*domain.Category")).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	_, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: "Electronics"})
+
+	require.NoError(t, err)
+	pub.AssertExpectations(t)
+}
+
+func TestCreate_OutboxError_ReturnsError(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+	tx := new(mocks.MockTransactor)
+	outbox := new(mocks.MockOutboxRepository)
+
+	tx.On("WithinTx", mock.Anything).Return(nil)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	outbox.On("Add", mock.Anything, mock.Anything).Return(assert.AnError)
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithTransactor(tx), service.WithOutbox(outbox))
+	cat, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: "Electronics"})
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Nil(t, cat)
+}
+
+func TestDelete_WithOutbox_RepoError_SkipsOutbox(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+	tx := new(mocks.MockTransactor)
+	outbox := new(mocks.MockOutboxRepository)
+
+	tx.On("WithinTx", mock.Anything).Return(nil)
+	repo.On("Delete", mock.Anything, "not-exist", int64(0)).Return(nil, domain.ErrNotFound)
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithTransactor(tx), service.WithOutbox(outbox))
+	err := svc.Delete(context.Background(), "not-exist", domain.DeleteCategoryParams{})
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	outbox.AssertNotCalled(t, "Add")
+}
+
+func TestDelete_TransactionError_NotPublished(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+	tx := new(mocks.MockTransactor)
+
+	tx.On("WithinTx", mock.Anything).Return(assert.AnError)
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithTransactor(tx))
+	err := svc.Delete(context.Background(), "abc-123", domain.DeleteCategoryParams{})
+
+	assert.ErrorIs(t, err, assert.AnError)
+	repo.AssertNotCalled(t, "Delete")
+	pub.AssertNotCalled(t, "PublishCategoryDeleted")
+}
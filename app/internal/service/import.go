@@ -0,0 +1,492 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/pkg/names"
+	"github.com/alfattd/category-service/internal/validator"
+	"github.com/google/uuid"
+)
+
+// parkedNamePrefix is longer than any valid name and has characters no slug has, so parked
+// names and slugs cannot collide with real ones.
+const parkedNamePrefix = "import in progress: "
+
+type importPlan struct {
+	result  domain.ImportResult
+	creates []*domain.Category
+	updates []importUpdate
+	deletes []*domain.Category
+}
+
+type importUpdate struct {
+	before  domain.Category
+	after   *domain.Category
+	changed []string
+}
+
+// Import checks the catalog the import would produce as a whole before touching anything,
+// so rows may refer to parents further down the file and names may move between categories.
+func (s *CategoryService) Import(ctx context.Context, p domain.ImportParams) (*domain.ImportResult, error) {
+	if errs := validator.ImportModeValidator(p.Mode); errs != nil {
+		return nil, errs
+	}
+
+	rows, errs := normalizeImportRows(p.Rows)
+	if errs != nil {
+		return nil, errs
+	}
+
+	// Applying parks names before writing the final ones, so without a transaction to roll back
+	// a failing write would leave parked names and half of the file in the catalog.
+	if _, ok := s.tx.(noTx); ok && !p.DryRun {
+		return nil, domain.ErrTxUnsupported
+	}
+
+	var plan *importPlan
+
+	err := s.commit(ctx, func(ctx context.Context) ([]domain.CategoryEvent, error) {
+		// The catalog is read and checked under the lock Move takes, so no concurrent change to
+		// the hierarchy can invalidate the parents, cycles and depths the plan was checked for,
+		// and under the slug locks single writes take, so no one claims the slugs it settles on.
+		if !p.DryRun {
+			if err := s.repo.LockHierarchy(ctx); err != nil {
+				return nil, err
+			}
+			if err := s.lockImportedSlugs(ctx, rows); err != nil {
+				return nil, err
+			}
+		}
+
+		current := make(map[string]*domain.Category)
+		err := s.each(ctx, domain.CategoryFilter{IncludeDeleted: true}, func(c *domain.Category) error {
+			current[c.ID] = c
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if plan, err = s.planImport(ctx, current, rows, p.Mode); err != nil {
+			return nil, err
+		}
+
+		if p.DryRun {
+			return nil, nil
+		}
+
+		return s.applyImport(ctx, plan)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &plan.result, nil
+}
+
+func normalizeImportRows(rows []domain.ImportRow) ([]domain.ImportRow, *validator.ErrorsValidator) {
+	errs := &validator.ErrorsValidator{}
+
+	if len(rows) == 0 {
+		errs.Add("import must contain at least one row")
+		return nil, errs
+	}
+
+	seen := make(map[string]int, len(rows))
+	normalized := make([]domain.ImportRow, len(rows))
+
+	for i, r := range rows {
+		r.ID = strings.TrimSpace(r.ID)
+		r.Name = names.Normalize(r.Name)
+		r.Slug = strings.TrimSpace(r.Slug)
+		r.ParentID = trimParentID(r.ParentID)
+
+		addRowErrors(errs, r.Row, validator.CategoryNameValidator(r.Name))
+		addRowErrors(errs, r.Row, validateSlug(r.Slug))
+		addRowErrors(errs, r.Row, validator.CategoryParentIDValidator(r.ParentID))
+
+		if r.ID == "" {
+			r.ID = uuid.NewString()
+		} else if first, ok := seen[r.ID]; ok {
+			errs.Add(fmt.Sprintf("row %d: id %s is already used by row %d", r.Row, r.ID, first))
+		} else {
+			seen[r.ID] = r.Row
+		}
+
+		normalized[i] = r
+	}
+
+	if errs.HasErrors() {
+		return nil, errs
+	}
+
+	return normalized, nil
+}
+
+func addRowErrors(errs *validator.ErrorsValidator, row int, rowErrs *validator.ErrorsValidator) {
+	if rowErrs == nil {
+		return
+	}
+	for _, msg := range rowErrs.Messages {
+		errs.Add(fmt.Sprintf("row %d: %s", row, msg))
+	}
+}
+
+// planImport works out the catalog after the import and the changes leading there.
+// current holds every stored category, soft-deleted ones included.
+func (s *CategoryService) planImport(ctx context.Context, current map[string]*domain.Category, rows []domain.ImportRow, mode string) (*importPlan, error) {
+	plan := &importPlan{}
+	errs := &validator.ErrorsValidator{}
+
+	final := make(map[string]*domain.Category)
+	rowOf := make(map[string]int, len(rows))
+	updates := make(map[string]*importUpdate)
+
+	if mode != domain.ImportReplace {
+		for id, c := range current {
+			if c.DeletedAt == nil {
+				final[id] = c
+			}
+		}
+	}
+
+	now := time.Now()
+
+	for _, r := range rows {
+		rowOf[r.ID] = r.Row
+		existing, ok := current[r.ID]
+
+		switch {
+		case !ok:
+			c := &domain.Category{ID: r.ID, Name: r.Name, Slug: r.Slug, ParentID: r.ParentID, CreatedAt: now, UpdatedAt: now}
+			final[r.ID] = c
+			plan.creates = append(plan.creates, c)
+		case existing.DeletedAt != nil:
+			errs.Add(fmt.Sprintf("row %d: category %s is deleted, restore it before importing it", r.Row, r.ID))
+		case mode == domain.ImportCreateOnly:
+			errs.Add(fmt.Sprintf("row %d: category %s already exists", r.Row, r.ID))
+		default:
+			after := *existing
+			after.Name = r.Name
+			after.ParentID = r.ParentID
+			if r.Slug != "" {
+				after.Slug = r.Slug
+			}
+			final[r.ID] = &after
+			updates[r.ID] = &importUpdate{before: *existing, after: &after}
+		}
+	}
+
+	if mode == domain.ImportReplace {
+		for id, c := range current {
+			if _, kept := final[id]; !kept && c.DeletedAt == nil {
+				plan.deletes = append(plan.deletes, c)
+			}
+		}
+	}
+
+	if errs.HasErrors() {
+		return nil, errs
+	}
+
+	if err := s.checkImportedSlugs(ctx, rows, updates); err != nil {
+		return nil, err
+	}
+
+	if err := s.deriveImportedSlugs(ctx, final, rows, updates); err != nil {
+		return nil, err
+	}
+
+	if errs := s.checkImportedCatalog(final, rows, rowOf); errs != nil {
+		return nil, errs
+	}
+
+	for _, r := range rows {
+		if u, ok := updates[r.ID]; ok {
+			if u.changed = changedFields(&u.before, u.after); len(u.changed) == 0 {
+				plan.result.Unchanged++
+				continue
+			}
+			plan.updates = append(plan.updates, *u)
+			plan.result.Changes = append(plan.result.Changes, domain.ImportChange{
+				Row: r.Row, Action: domain.ImportUpdated, Category: u.after, ChangedFields: u.changed,
+			})
+		} else {
+			plan.result.Changes = append(plan.result.Changes, domain.ImportChange{Row: r.Row, Action: domain.ImportCreated, Category: final[r.ID]})
+		}
+	}
+
+	// Children go before their parents, which cannot be deleted while they have live children.
+	live := make(map[string]*domain.Category)
+	for id, c := range current {
+		if c.DeletedAt == nil {
+			live[id] = c
+		}
+	}
+	depths := categoryDepths(live)
+	slices.SortFunc(plan.deletes, func(a, b *domain.Category) int {
+		return cmp.Or(cmp.Compare(depths[b.ID], depths[a.ID]), strings.Compare(a.ID, b.ID))
+	})
+	for _, c := range plan.deletes {
+		plan.result.Changes = append(plan.result.Changes, domain.ImportChange{Action: domain.ImportDeleted, Category: c})
+	}
+
+	depths = categoryDepths(final)
+	slices.SortStableFunc(plan.creates, func(a, b *domain.Category) int {
+		return cmp.Compare(depths[a.ID], depths[b.ID])
+	})
+
+	plan.result.Created = len(plan.creates)
+	plan.result.Updated = len(plan.updates)
+	plan.result.Deleted = len(plan.deletes)
+
+	return plan, nil
+}
+
+// checkImportedSlugs refuses the slugs rows set that another category has or had, as claimSlug
+// does for single writes, since those keep resolving to the other category.
+func (s *CategoryService) checkImportedSlugs(ctx context.Context, rows []domain.ImportRow, updates map[string]*importUpdate) error {
+	errs := &validator.ErrorsValidator{}
+
+	for _, r := range rows {
+		if r.Slug == "" {
+			continue
+		}
+		if u, ok := updates[r.ID]; ok && u.before.Slug == r.Slug {
+			continue
+		}
+
+		taken, err := s.repo.ListSlugs(ctx, r.Slug)
+		if err != nil {
+			return err
+		}
+
+		if owner, ok := taken[r.Slug]; ok && owner != r.ID {
+			errs.Add(fmt.Sprintf("row %d: slug %q is already used by category %s", r.Row, r.Slug, owner))
+		}
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+
+	return nil
+}
+
+// deriveImportedSlugs gives the categories that need one a slug derived from their name, as
+// claimSlug does for single writes, keeping clear of the slugs the rest of the catalog ends up with.
+func (s *CategoryService) deriveImportedSlugs(ctx context.Context, final map[string]*domain.Category, rows []domain.ImportRow, updates map[string]*importUpdate) error {
+	var derive []*domain.Category
+	for _, r := range rows {
+		c := final[r.ID]
+		if u, ok := updates[r.ID]; ok {
+			if c.Slug == u.before.Slug && c.Name != u.before.Name {
+				derive = append(derive, c)
+			}
+		} else if c.Slug == "" {
+			derive = append(derive, c)
+		}
+	}
+
+	reserved := make(map[string]string, len(final))
+	for id, c := range final {
+		if !slices.Contains(derive, c) {
+			reserved[c.Slug] = id
+		}
+	}
+
+	for _, c := range derive {
+		derived, err := s.uniqueSlug(ctx, c.ID, c.Name, reserved)
+		if err != nil {
+			return err
+		}
+		c.Slug = derived
+		reserved[derived] = c.ID
+	}
+
+	return nil
+}
+
+// checkImportedCatalog applies the rules single writes are held to across the whole catalog:
+// unique names and slugs, existing parents, no cycles and the maximum depth.
+func (s *CategoryService) checkImportedCatalog(final map[string]*domain.Category, rows []domain.ImportRow, rowOf map[string]int) *validator.ErrorsValidator {
+	errs := &validator.ErrorsValidator{}
+
+	describe := func(id string) string {
+		if row, ok := rowOf[id]; ok {
+			return fmt.Sprintf("row %d", row)
+		}
+		return "category " + id
+	}
+
+	// Categories the file leaves alone come first, so that conflicts are reported on the rows.
+	order := make([]string, 0, len(final))
+	for id := range final {
+		if _, ok := rowOf[id]; !ok {
+			order = append(order, id)
+		}
+	}
+	slices.Sort(order)
+	for _, r := range rows {
+		if _, ok := final[r.ID]; ok {
+			order = append(order, r.ID)
+		}
+	}
+
+	// Names have to differ in more than case and accents, like the storage requires.
+	nameKeys := make(map[string]string, len(final))
+	slugs := make(map[string]string, len(final))
+	for _, id := range order {
+		name, slug := final[id].Name, final[id].Slug
+		if other, ok := nameKeys[names.Key(name)]; ok {
+			errs.Add(fmt.Sprintf("%s: name %q is already used by %s", describe(id), name, describe(other)))
+		} else {
+			nameKeys[names.Key(name)] = id
+		}
+		if other, ok := slugs[slug]; ok {
+			errs.Add(fmt.Sprintf("%s: slug %q is already used by %s", describe(id), slug, describe(other)))
+		} else {
+			slugs[slug] = id
+		}
+	}
+
+	depths := categoryDepths(final)
+	for _, id := range order {
+		c := final[id]
+
+		switch {
+		case c.ParentID != nil && final[*c.ParentID] == nil:
+			errs.Add(fmt.Sprintf("%s: parent %s does not exist", describe(id), *c.ParentID))
+		case depths[id] < 0:
+			errs.Add(fmt.Sprintf("%s: parent chain contains a cycle", describe(id)))
+		case depths[id] == s.maxDepth+1:
+			errs.Add(fmt.Sprintf("%s: hierarchy is limited to %d levels", describe(id), s.maxDepth))
+		}
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+
+	return nil
+}
+
+// categoryDepths returns the level of every category, roots being at 1. Categories whose
+// parent chain loops get -1; a missing parent counts as the root.
+func categoryDepths(categories map[string]*domain.Category) map[string]int {
+	depths := make(map[string]int, len(categories))
+
+	for id := range categories {
+		var path []string
+		onPath := make(map[string]bool)
+
+		base := 0
+		for cur := id; ; {
+			if d, ok := depths[cur]; ok {
+				base = d
+				break
+			}
+
+			c, ok := categories[cur]
+			if !ok {
+				break
+			}
+
+			if onPath[cur] {
+				base = -1
+				break
+			}
+
+			onPath[cur] = true
+			path = append(path, cur)
+
+			if c.ParentID == nil {
+				break
+			}
+			cur = *c.ParentID
+		}
+
+		for i := len(path) - 1; i >= 0; i-- {
+			if base >= 0 {
+				base++
+			}
+			depths[path[i]] = base
+		}
+	}
+
+	return depths
+}
+
+// applyImport writes a plan that planImport has checked. The catalog passes through states
+// that would break the unique names or the parent foreign key if written in one go, so
+// categories that change are first parked at the root under a name no one else can have.
+// Every category still gets a single revision and event describing its net change.
+func (s *CategoryService) applyImport(ctx context.Context, plan *importPlan) ([]domain.CategoryEvent, error) {
+	now := time.Now()
+	var events []domain.CategoryEvent
+
+	for _, u := range plan.updates {
+		name, slug, parentID := u.after.Name, u.after.Slug, u.after.ParentID
+
+		if slices.Contains(u.changed, domain.FieldName) {
+			u.after.Name = parkedNamePrefix + u.after.ID
+		}
+		if slices.Contains(u.changed, domain.FieldSlug) {
+			u.after.Slug = parkedNamePrefix + u.after.ID
+		}
+		if slices.Contains(u.changed, domain.FieldParentID) {
+			u.after.ParentID = nil
+		}
+
+		u.after.UpdatedAt = now
+		if err := s.repo.Update(ctx, u.after); err != nil {
+			return nil, err
+		}
+
+		u.after.Name, u.after.Slug, u.after.ParentID = name, slug, parentID
+	}
+
+	for _, c := range plan.deletes {
+		deleted, err := s.repo.Delete(ctx, c.ID, c.Version)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.record(ctx, domain.RevisionDeleted, c.ID, deleted, nil); err != nil {
+			return nil, err
+		}
+		events = append(events, domain.CategoryEvent{Type: domain.EventCategoryDeleted, Category: deleted})
+	}
+
+	for _, c := range plan.creates {
+		if err := s.repo.Create(ctx, c); err != nil {
+			return nil, err
+		}
+		if err := s.record(ctx, domain.RevisionCreated, c.ID, nil, c); err != nil {
+			return nil, err
+		}
+		events = append(events, domain.CategoryEvent{Type: domain.EventCategoryCreated, Category: c})
+	}
+
+	for _, u := range plan.updates {
+		if err := s.repo.Update(ctx, u.after); err != nil {
+			return nil, err
+		}
+		if u.after.Slug != u.before.Slug {
+			if err := s.repo.AddFormerSlug(ctx, u.after.ID, u.before.Slug); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.record(ctx, domain.RevisionUpdated, u.after.ID, &u.before, u.after); err != nil {
+			return nil, err
+		}
+		events = append(events, domain.CategoryEvent{Type: domain.EventCategoryUpdated, Category: u.after, Before: &u.before, ChangedFields: u.changed})
+	}
+
+	return events, nil
+}
@@ -0,0 +1,401 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/mocks"
+	"github.com/alfattd/category-service/internal/service"
+	"github.com/alfattd/category-service/internal/validator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func strPtr(s string) *string { return &s }
+
+var deletedAt = time.Now()
+
+// catalog makes the repository hold categories, soft-deleted ones included.
+func catalog(repo *mocks.MockCategoryRepository, categories ...*domain.Category) {
+	repo.On("LockHierarchy", mock.Anything).Return(nil).Maybe()
+	repo.On("LockSlug", mock.Anything, mock.Anything).Return(nil).Maybe()
+	repo.On("ListAfter", mock.Anything, mock.MatchedBy(func(p domain.PaginationParams) bool {
+		return p.Filter.IncludeDeleted
+	}), (*domain.CategoryCursor)(nil)).Return(categories, nil)
+}
+
+// importTx runs imports, which need a transaction, as if in one.
+func importTx() *mocks.MockTransactor {
+	tx := new(mocks.MockTransactor)
+	tx.On("WithinTx", mock.Anything).Return(nil)
+	return tx
+}
+
+func importErrors(t *testing.T, err error) []string {
+	t.Helper()
+	var valErrs *validator.ErrorsValidator
+	require.ErrorAs(t, err, &valErrs)
+	return valErrs.Messages
+}
+
+func TestImport_CreateOnly_ParentLaterInFile_CreatesParentFirst(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	catalog(repo)
+
+	var created []string
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).
+		Run(func(args mock.Arguments) { created = append(created, args.Get(1).(*domain.Category).ID) }).
+		Return(nil)
+	pub.On("PublishCategoryCreated", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithTransactor(importTx()))
+	res, err := svc.Import(context.Background(), domain.ImportParams{Mode: domain.ImportCreateOnly, Rows: []domain.ImportRow{
+		{Row: 2, ID: "phones", Name: " Phones ", ParentID: strPtr("electronics")},
+		{Row: 3, ID: "electronics", Name: "Electronics"},
+	}})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Created)
+	assert.Equal(t, []string{"electronics", "phones"}, created)
+	assert.Equal(t, "Phones", res.Changes[0].Category.Name)
+	pub.AssertNumberOfCalls(t, "PublishCategoryCreated", 2)
+}
+
+func TestImport_ReadsCatalogUnderHierarchyLock(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	tx := new(mocks.MockTransactor)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	var calls []string
+	record := func(name string) func(mock.Arguments) {
+		return func(mock.Arguments) { calls = append(calls, name) }
+	}
+
+	tx.On("WithinTx", mock.Anything).Run(record("WithinTx")).Return(nil)
+	repo.On("LockHierarchy", mock.Anything).Run(record("LockHierarchy")).Return(nil)
+	repo.On("ListAfter", mock.Anything, mock.Anything, mock.Anything).Run(record("ListAfter")).Return([]*domain.Category{
+		{ID: "electronics", Name: "Electronics", Slug: "electronics"},
+	}, nil)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Run(record("Create")).Return(nil)
+	pub.On("PublishCategoryCreated", mock.Anything, mock.Anything).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithTransactor(tx))
+	_, err := svc.Import(context.Background(), domain.ImportParams{Mode: domain.ImportUpsert, Rows: []domain.ImportRow{
+		{Row: 2, ID: "phones", Name: "Phones", ParentID: strPtr("electronics")},
+	}})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"WithinTx", "LockHierarchy", "ListAfter", "Create"}, calls)
+}
+
+func TestImport_CreateOnly_ExistingID_ReturnsRowError(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	catalog(repo, &domain.Category{ID: "electronics", Name: "Electronics", Slug: "electronics"})
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithTransactor(importTx()))
+	_, err := svc.Import(context.Background(), domain.ImportParams{Mode: domain.ImportCreateOnly, Rows: []domain.ImportRow{
+		{Row: 2, ID: "electronics", Name: "Electronics"},
+	}})
+
+	assert.Equal(t, []string{"row 2: category electronics already exists"}, importErrors(t, err))
+	repo.AssertNotCalled(t, "Create")
+}
+
+func TestImport_InvalidRows_ReturnsRowNumberedErrors(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithTransactor(importTx()))
+	_, err := svc.Import(context.Background(), domain.ImportParams{Mode: domain.ImportUpsert, Rows: []domain.ImportRow{
+		{Row: 2, ID: "a", Name: ""},
+		{Row: 3, ID: "b", Name: "<b>"},
+		{Row: 4, ID: "a", Name: "Again"},
+	}})
+
+	assert.Equal(t, []string{
+		"row 2: name is required",
+		"row 3: name contains invalid characters (< > \" ' ; & \\ / { } ( ) [ ] are not allowed)",
+		"row 4: id a is already used by row 2",
+	}, importErrors(t, err))
+	repo.AssertNotCalled(t, "ListAfter")
+}
+
+func TestImport_CatalogRules_ReturnsRowNumberedErrors(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	catalog(repo,
+		&domain.Category{ID: "books", Name: "Books", Slug: "books"},
+		&domain.Category{ID: "old", Name: "Old", Slug: "old", DeletedAt: &deletedAt},
+	)
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithMaxDepth(2), service.WithTransactor(importTx()))
+	_, err := svc.Import(context.Background(), domain.ImportParams{Mode: domain.ImportUpsert, Rows: []domain.ImportRow{
+		{Row: 2, ID: "novels", Name: " bóoks "},
+		{Row: 3, ID: "orphan", Name: "Orphan", ParentID: strPtr("missing")},
+		{Row: 4, ID: "x", Name: "X", ParentID: strPtr("y")},
+		{Row: 5, ID: "y", Name: "Y", ParentID: strPtr("x")},
+		{Row: 6, ID: "l2", Name: "L2", ParentID: strPtr("books")},
+		{Row: 7, ID: "l3", Name: "L3", ParentID: strPtr("l2")},
+	}})
+
+	assert.Equal(t, []string{
+		`row 2: name "bóoks" is already used by category books`,
+		"row 3: parent missing does not exist",
+		"row 4: parent chain contains a cycle",
+		"row 5: parent chain contains a cycle",
+		"row 7: hierarchy is limited to 2 levels",
+	}, importErrors(t, err))
+
+	_, err = svc.Import(context.Background(), domain.ImportParams{Mode: domain.ImportUpsert, Rows: []domain.ImportRow{
+		{Row: 2, ID: "old", Name: "Old"},
+	}})
+	assert.Equal(t, []string{"row 2: category old is deleted, restore it before importing it"}, importErrors(t, err))
+
+	repo.AssertNotCalled(t, "Create")
+	repo.AssertNotCalled(t, "Update")
+}
+
+func TestImport_DryRun_ReportsWithoutWriting(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	catalog(repo,
+		&domain.Category{ID: "books", Name: "Books", Slug: "books"},
+		&domain.Category{ID: "music", Name: "Music", Slug: "music"},
+		&domain.Category{ID: "toys", Name: "Toys", Slug: "toys"},
+	)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	res, err := svc.Import(context.Background(), domain.ImportParams{Mode: domain.ImportReplace, DryRun: true, Rows: []domain.ImportRow{
+		{Row: 2, ID: "books", Name: "Books"},
+		{Row: 3, ID: "music", Name: "Records"},
+		{Row: 4, ID: "games", Name: "Games"},
+	}})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Created)
+	assert.Equal(t, 1, res.Updated)
+	assert.Equal(t, 1, res.Deleted)
+	assert.Equal(t, 1, res.Unchanged)
+
+	require.Len(t, res.Changes, 3)
+	assert.Equal(t, domain.ImportChange{Row: 3, Action: domain.ImportUpdated, Category: res.Changes[0].Category, ChangedFields: []string{domain.FieldName, domain.FieldSlug}}, res.Changes[0])
+	assert.Equal(t, "records", res.Changes[0].Category.Slug)
+	assert.Equal(t, domain.ImportCreated, res.Changes[1].Action)
+	assert.Equal(t, domain.ImportDeleted, res.Changes[2].Action)
+	assert.Equal(t, "toys", res.Changes[2].Category.ID)
+
+	repo.AssertNotCalled(t, "Create")
+	repo.AssertNotCalled(t, "Update")
+	repo.AssertNotCalled(t, "Delete")
+}
+
+func TestImport_Replace_ParksChangesAndDeletesChildrenFirst(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	catalog(repo,
+		&domain.Category{ID: "a", Name: "A", Slug: "a", Version: 1},
+		&domain.Category{ID: "b", Name: "B", Slug: "b", ParentID: strPtr("a"), Version: 1},
+		&domain.Category{ID: "old", Name: "Old", Slug: "old", Version: 1},
+		&domain.Category{ID: "old-child", Name: "Old Child", Slug: "old-child", ParentID: strPtr("old"), Version: 1},
+	)
+
+	var writes []string
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Category")).
+		Run(func(args mock.Arguments) {
+			c := args.Get(1).(*domain.Category)
+			writes = append(writes, "update "+c.ID+" "+c.Name+" "+c.Slug)
+			c.Version++
+		}).
+		Return(nil)
+	repo.On("Delete", mock.Anything, mock.Anything, int64(1)).
+		Run(func(args mock.Arguments) { writes = append(writes, "delete "+args.String(1)) }).
+		Return(&domain.Category{}, nil)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).
+		Run(func(args mock.Arguments) { writes = append(writes, "create "+args.Get(1).(*domain.Category).ID) }).
+		Return(nil)
+	pub.On("PublishCategoryUpdated", mock.Anything, mock.AnythingOfType("*domain.Category"), mock.AnythingOfType("*domain.Category"), []string{domain.FieldName, domain.FieldSlug}).Return(nil)
+	pub.On("PublishCategoryDeleted", mock.Anything, mock.Anything).Return(nil)
+	pub.On("PublishCategoryCreated", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+
+	// A and B swap names and with them slugs, which only works if one of them steps aside first.
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithTransactor(importTx()))
+	res, err := svc.Import(context.Background(), domain.ImportParams{Mode: domain.ImportReplace, Rows: []domain.ImportRow{
+		{Row: 2, ID: "a", Name: "B"},
+		{Row: 3, ID: "b", Name: "A", ParentID: strPtr("a")},
+		{Row: 4, ID: "c", Name: "Old"},
+	}})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"update a import in progress: a import in progress: a",
+		"update b import in progress: b import in progress: b",
+		"delete old-child",
+		"delete old",
+		"create c",
+		"update a B b",
+		"update b A a",
+	}, writes)
+	assert.Equal(t, 2, res.Deleted)
+
+	repo.AssertCalled(t, "AddFormerSlug", mock.Anything, "a", "a")
+	repo.AssertCalled(t, "AddFormerSlug", mock.Anything, "b", "b")
+
+	pub.AssertNumberOfCalls(t, "PublishCategoryUpdated", 2)
+	pub.AssertNumberOfCalls(t, "PublishCategoryDeleted", 2)
+}
+
+func TestImport_WithoutTransactor_ReturnsErrTxUnsupported(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+
+	svc := service.NewCategoryService(repo, new(mocks.MockCategoryEventPublisher), testLogger)
+	_, err := svc.Import(context.Background(), domain.ImportParams{Mode: domain.ImportUpsert, Rows: []domain.ImportRow{
+		{Row: 2, ID: "electronics", Name: "Electronics"},
+	}})
+
+	assert.ErrorIs(t, err, domain.ErrTxUnsupported)
+	repo.AssertNotCalled(t, "ListAfter", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImport_InvalidMode_ReturnsValidationError(t *testing.T) {
+	svc := service.NewCategoryService(new(mocks.MockCategoryRepository), new(mocks.MockCategoryEventPublisher), testLogger)
+	_, err := svc.Import(context.Background(), domain.ImportParams{Mode: "merge", Rows: []domain.ImportRow{{Row: 1, Name: "A"}}})
+
+	assert.Equal(t, []string{"mode must be one of create-only, upsert or replace"}, importErrors(t, err))
+}
+
+func TestImport_NoRows_ReturnsValidationError(t *testing.T) {
+	svc := service.NewCategoryService(new(mocks.MockCategoryRepository), new(mocks.MockCategoryEventPublisher), testLogger)
+	_, err := svc.Import(context.Background(), domain.ImportParams{Mode: domain.ImportReplace})
+
+	assert.Equal(t, []string{"import must contain at least one row"}, importErrors(t, err))
+}
+
+func TestExport_PagesThroughCatalog(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	categories := []*domain.Category{{ID: "a", Name: "A", Slug: "a"}, {ID: "b", Name: "B", Slug: "b"}}
+	repo.On("ListAfter", mock.Anything, mock.MatchedBy(func(p domain.PaginationParams) bool {
+		return !p.Filter.IncludeDeleted && p.Sort == domain.SortCreatedAtAsc
+	}), (*domain.CategoryCursor)(nil)).Return(categories, nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+
+	var ids []string
+	err := svc.Export(context.Background(), func(c *domain.Category) error {
+		ids = append(ids, c.ID)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, ids)
+	repo.AssertNumberOfCalls(t, "ListAfter", 1)
+}
+
+func TestExport_ReadsWithinSnapshot(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	tx := new(mocks.MockTransactor)
+
+	var calls []string
+	tx.On("WithinSnapshot", mock.Anything).Run(func(mock.Arguments) { calls = append(calls, "WithinSnapshot") }).Return(nil)
+	repo.On("ListAfter", mock.Anything, mock.Anything, (*domain.CategoryCursor)(nil)).
+		Run(func(mock.Arguments) { calls = append(calls, "ListAfter") }).
+		Return([]*domain.Category{{ID: "a", Name: "A", Slug: "a"}}, nil)
+
+	svc := service.NewCategoryService(repo, new(mocks.MockCategoryEventPublisher), testLogger, service.WithTransactor(tx))
+	err := svc.Export(context.Background(), func(*domain.Category) error { return nil })
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"WithinSnapshot", "ListAfter"}, calls)
+	tx.AssertNotCalled(t, "WithinTx", mock.Anything)
+}
+
+func TestImport_Slugs_DerivedAroundTakenOnes(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	catalog(repo, &domain.Category{ID: "garden", Name: "Garden", Slug: "home-garden"})
+	repo.On("ListSlugs", mock.Anything, "home-garden").Return(map[string]string{"home-garden": "garden"}, nil)
+	repo.On("ListSlugs", mock.Anything, "home-garden-3").Return(map[string]string{}, nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithTransactor(importTx()))
+	res, err := svc.Import(context.Background(), domain.ImportParams{Mode: domain.ImportUpsert, DryRun: true, Rows: []domain.ImportRow{
+		{Row: 2, ID: "a", Name: "Home + Garden"},
+		{Row: 3, ID: "b", Name: "Home Garden"},
+		{Row: 4, ID: "c", Name: "Home", Slug: "home-garden-3"},
+	}})
+
+	require.NoError(t, err)
+	require.Len(t, res.Changes, 3)
+	assert.Equal(t, "home-garden-2", res.Changes[0].Category.Slug)
+	assert.Equal(t, "home-garden-4", res.Changes[1].Category.Slug)
+	assert.Equal(t, "home-garden-3", res.Changes[2].Category.Slug)
+
+	_, err = svc.Import(context.Background(), domain.ImportParams{Mode: domain.ImportUpsert, Rows: []domain.ImportRow{
+		{Row: 2, ID: "a", Name: "Outdoor", Slug: "home-garden"},
+		{Row: 3, ID: "b", Name: "Indoor", Slug: "Not A Slug"},
+	}})
+	assert.Equal(t, []string{"row 3: slug must consist of lowercase letters, digits and single hyphens between them"}, importErrors(t, err))
+
+	_, err = svc.Import(context.Background(), domain.ImportParams{Mode: domain.ImportUpsert, Rows: []domain.ImportRow{
+		{Row: 2, ID: "a", Name: "Outdoor", Slug: "home-garden"},
+	}})
+	assert.Equal(t, []string{`row 2: slug "home-garden" is already used by category garden`}, importErrors(t, err))
+}
+
+func TestImport_SlugFormerlyOfAnotherCategory_ReturnsValidationError(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+
+	catalog(repo, &domain.Category{ID: "garden", Name: "Garden", Slug: "yard"})
+	repo.On("ListSlugs", mock.Anything, "garden").Return(map[string]string{"garden": "garden"}, nil)
+
+	svc := service.NewCategoryService(repo, new(mocks.MockCategoryEventPublisher), testLogger, service.WithTransactor(importTx()))
+	_, err := svc.Import(context.Background(), domain.ImportParams{Mode: domain.ImportUpsert, Rows: []domain.ImportRow{
+		{Row: 2, ID: "a", Name: "Outdoor", Slug: "garden"},
+	}})
+
+	assert.Equal(t, []string{`row 2: slug "garden" is already used by category garden`}, importErrors(t, err))
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestImport_LocksEverySlugRootOnce_InOrder(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	var roots []string
+	repo.On("LockHierarchy", mock.Anything).Return(nil)
+	repo.On("LockSlug", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { roots = append(roots, args.String(1)) }).
+		Return(nil)
+	freeSlugs(repo)
+	catalog(repo)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	pub.On("PublishCategoryCreated", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithTransactor(importTx()))
+	_, err := svc.Import(context.Background(), domain.ImportParams{Mode: domain.ImportCreateOnly, Rows: []domain.ImportRow{
+		{Row: 2, ID: "a", Name: "Route 66"},
+		{Row: 3, ID: "b", Name: "Books", Slug: "route-66-2"},
+		{Row: 4, ID: "c", Name: "Audio"},
+	}})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"audio", "books", "route"}, roots)
+}
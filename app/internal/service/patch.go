@@ -0,0 +1,66 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/alfattd/category-service/internal/domain"
+)
+
+// applyPatch runs the operations of an RFC 6902 JSON Patch against c. The patchable fields
+// always exist in the category document, so "add" behaves like "replace" and "remove" sets
+// the field to null. Operations are expected to have passed CategoryPatchValidator.
+func applyPatch(c *domain.Category, ops []domain.PatchOp) error {
+	for i, op := range ops {
+		switch op.Op {
+		case domain.PatchAdd, domain.PatchReplace:
+			setField(c, op.Path, op.Value)
+		case domain.PatchRemove:
+			setField(c, op.Path, nil)
+		case domain.PatchMove:
+			value := getField(c, op.From)
+			setField(c, op.From, nil)
+			setField(c, op.Path, value)
+		case domain.PatchCopy:
+			setField(c, op.Path, getField(c, op.From))
+		case domain.PatchTest:
+			if !sameValue(getField(c, op.Path), op.Value) {
+				return fmt.Errorf("%w: operation %d: /%s does not have the expected value", domain.ErrPatchTestFailed, i, op.Path)
+			}
+		}
+	}
+
+	return nil
+}
+
+func getField(c *domain.Category, field string) *string {
+	switch field {
+	case domain.FieldName:
+		name := c.Name
+		return &name
+	case domain.FieldSlug:
+		slug := c.Slug
+		return &slug
+	case domain.FieldParentID:
+		return c.ParentID
+	}
+	return nil
+}
+
+// setField stores value in field. A null name becomes empty and fails validation as a missing
+// name, while a null slug becomes empty and is derived from the name again.
+func setField(c *domain.Category, field string, value *string) {
+	switch field {
+	case domain.FieldName:
+		c.Name = ""
+		if value != nil {
+			c.Name = *value
+		}
+	case domain.FieldSlug:
+		c.Slug = ""
+		if value != nil {
+			c.Slug = *value
+		}
+	case domain.FieldParentID:
+		c.ParentID = value
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"math"
+	"strings"
 
 	"github.com/alfattd/category-service/internal/domain"
 	"github.com/alfattd/category-service/internal/validator"
@@ -12,6 +13,7 @@ const (
 	defaultPage  = 1
 	defaultLimit = 10
 	maxLimit     = 100
+	eachPageSize = 500
 )
 
 func (s *CategoryService) GetByID(ctx context.Context, id string) (*domain.Category, error) {
@@ -23,6 +25,86 @@ func (s *CategoryService) GetByID(ctx context.Context, id string) (*domain.Categ
 }
 
 func (s *CategoryService) List(ctx context.Context, p domain.PaginationParams) (*domain.PaginatedResult[*domain.Category], error) {
+	p = normalizePage(p)
+
+	if p.Sort != "" && !p.Sort.Valid() {
+		errs := &validator.ErrorsValidator{}
+		errs.Add("sort must be one of " + joinSorts(domain.CategorySorts))
+		return nil, errs
+	}
+
+	p.Filter.Query = strings.TrimSpace(p.Filter.Query)
+
+	if p.Cursor != nil {
+		return s.listAfter(ctx, p)
+	}
+
+	total, err := s.repo.Count(ctx, p.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.repo.List(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.PaginatedResult[*domain.Category]{
+		Data:       categories,
+		Page:       p.Page,
+		Limit:      p.Limit,
+		Total:      total,
+		TotalPages: totalPages(total, p.Limit),
+	}
+
+	// Lets clients switch to keyset pagination from any numbered page.
+	if p.Page < result.TotalPages && len(categories) > 0 {
+		result.NextCursor = domain.NewCategoryCursor(categories[len(categories)-1], sortOrDefault(p.Sort)).Encode()
+	}
+
+	return result, nil
+}
+
+func (s *CategoryService) listAfter(ctx context.Context, p domain.PaginationParams) (*domain.PaginatedResult[*domain.Category], error) {
+	var after *domain.CategoryCursor
+	if *p.Cursor != "" {
+		cursor, err := domain.DecodeCategoryCursor(*p.Cursor)
+		if err != nil || cursor.Sort != sortOrDefault(p.Sort) {
+			errs := &validator.ErrorsValidator{}
+			errs.Add("cursor is invalid")
+			return nil, errs
+		}
+		after = &cursor
+	}
+
+	// One extra row tells whether there is a next page without counting.
+	fetch := p
+	fetch.Limit++
+
+	categories, err := s.repo.ListAfter(ctx, fetch, after)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.PaginatedResult[*domain.Category]{Limit: p.Limit}
+
+	if len(categories) > p.Limit {
+		categories = categories[:p.Limit]
+		result.NextCursor = domain.NewCategoryCursor(categories[p.Limit-1], sortOrDefault(p.Sort)).Encode()
+	}
+	result.Data = categories
+
+	if p.WithTotal {
+		if result.Total, err = s.repo.Count(ctx, p.Filter); err != nil {
+			return nil, err
+		}
+		result.TotalPages = totalPages(result.Total, p.Limit)
+	}
+
+	return result, nil
+}
+
+func normalizePage(p domain.PaginationParams) domain.PaginationParams {
 	if p.Page < 1 {
 		p.Page = defaultPage
 	}
@@ -33,26 +115,117 @@ func (s *CategoryService) List(ctx context.Context, p domain.PaginationParams) (
 		p.Limit = maxLimit
 	}
 
-	total, err := s.repo.Count(ctx)
-	if err != nil {
+	return p
+}
+
+func sortOrDefault(sort domain.CategorySort) domain.CategorySort {
+	if sort == "" {
+		return domain.DefaultCategorySort
+	}
+	return sort
+}
+
+func joinSorts(sorts []domain.CategorySort) string {
+	names := make([]string, len(sorts))
+	for i, s := range sorts {
+		names[i] = string(s)
+	}
+	return strings.Join(names, ", ")
+}
+
+func totalPages(total, limit int) int {
+	pages := int(math.Ceil(float64(total) / float64(limit)))
+	if pages == 0 {
+		return 1
+	}
+	return pages
+}
+
+func (s *CategoryService) Children(ctx context.Context, id string) ([]*domain.Category, error) {
+	if errs := validator.CategoryIDValidator(id); errs != nil {
+		return nil, errs
+	}
+
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
 		return nil, err
 	}
 
-	categories, err := s.repo.List(ctx, p)
+	return s.repo.ListChildren(ctx, id)
+}
+
+func (s *CategoryService) Ancestors(ctx context.Context, id string) ([]*domain.Category, error) {
+	if errs := validator.CategoryIDValidator(id); errs != nil {
+		return nil, errs
+	}
+
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListAncestors(ctx, id)
+}
+
+func (s *CategoryService) Tree(ctx context.Context) ([]*domain.CategoryNode, error) {
+	categories, err := s.repo.ListAll(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	totalPages := int(math.Ceil(float64(total) / float64(p.Limit)))
-	if totalPages == 0 {
-		totalPages = 1
+	return buildTree(categories), nil
+}
+
+// Export reads every page from one snapshot, so a move or import running meanwhile cannot leave
+// the file with rows from different states of the catalog, which the import would then reject.
+func (s *CategoryService) Export(ctx context.Context, fn func(*domain.Category) error) error {
+	return s.tx.WithinSnapshot(ctx, func(ctx context.Context) error {
+		return s.each(ctx, domain.CategoryFilter{}, fn)
+	})
+}
+
+// each pages through the categories matching f by keyset, oldest first.
+func (s *CategoryService) each(ctx context.Context, f domain.CategoryFilter, fn func(*domain.Category) error) error {
+	p := domain.PaginationParams{Limit: eachPageSize, Sort: domain.SortCreatedAtAsc, Filter: f}
+
+	var after *domain.CategoryCursor
+	for {
+		page, err := s.repo.ListAfter(ctx, p, after)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range page {
+			if err := fn(c); err != nil {
+				return err
+			}
+		}
+
+		if len(page) < p.Limit {
+			return nil
+		}
+
+		cursor := domain.NewCategoryCursor(page[len(page)-1], p.Sort)
+		after = &cursor
 	}
+}
 
-	return &domain.PaginatedResult[*domain.Category]{
-		Data:       categories,
-		Page:       p.Page,
-		Limit:      p.Limit,
-		Total:      total,
-		TotalPages: totalPages,
-	}, nil
+// buildTree assembles categories into a forest, preserving the input order among siblings.
+func buildTree(categories []*domain.Category) []*domain.CategoryNode {
+	nodes := make(map[string]*domain.CategoryNode, len(categories))
+	for _, c := range categories {
+		nodes[c.ID] = &domain.CategoryNode{Category: c, Children: []*domain.CategoryNode{}}
+	}
+
+	roots := make([]*domain.CategoryNode, 0)
+	for _, c := range categories {
+		node := nodes[c.ID]
+		if c.ParentID != nil {
+			if parent, ok := nodes[*c.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	return roots
 }
@@ -90,15 +90,15 @@ func TestGetByID_RepoError_ReturnsError(t *testing.T) {
 
 func TestList_Success(t *testing.T) {
 	categories := []*domain.Category{
-		{ID: "1", Name: "Electronics", CreatedAt: time.Now(), UpdatedAt: time.Now()},
-		{ID: "2", Name: "Books", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: "1", Name: "Electronics", Slug: "electronics", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: "2", Name: "Books", Slug: "books", CreatedAt: time.Now(), UpdatedAt: time.Now()},
 	}
 	p := domain.PaginationParams{Page: 1, Limit: 10}
 
 	repo := new(mocks.MockCategoryRepository)
 	pub := new(mocks.MockCategoryEventPublisher)
 
-	repo.On("Count", mock.Anything).Return(2, nil)
+	repo.On("Count", mock.Anything, domain.CategoryFilter{}).Return(2, nil)
 	repo.On("List", mock.Anything, p).Return(categories, nil)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
@@ -121,7 +121,7 @@ func TestList_DefaultsAppliedWhenParamsInvalid(t *testing.T) {
 	repo := new(mocks.MockCategoryRepository)
 	pub := new(mocks.MockCategoryEventPublisher)
 
-	repo.On("Count", mock.Anything).Return(0, nil)
+	repo.On("Count", mock.Anything, domain.CategoryFilter{}).Return(0, nil)
 	repo.On("List", mock.Anything, expectedP).Return([]*domain.Category{}, nil)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
@@ -141,7 +141,7 @@ func TestList_LimitCappedAtMax(t *testing.T) {
 	repo := new(mocks.MockCategoryRepository)
 	pub := new(mocks.MockCategoryEventPublisher)
 
-	repo.On("Count", mock.Anything).Return(0, nil)
+	repo.On("Count", mock.Anything, domain.CategoryFilter{}).Return(0, nil)
 	repo.On("List", mock.Anything, expectedP).Return([]*domain.Category{}, nil)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
@@ -159,7 +159,7 @@ func TestList_TotalPagesCalculation(t *testing.T) {
 	repo := new(mocks.MockCategoryRepository)
 	pub := new(mocks.MockCategoryEventPublisher)
 
-	repo.On("Count", mock.Anything).Return(95, nil)
+	repo.On("Count", mock.Anything, domain.CategoryFilter{}).Return(95, nil)
 	repo.On("List", mock.Anything, p).Return([]*domain.Category{}, nil)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
@@ -178,7 +178,7 @@ func TestList_Empty_ReturnsEmptySliceWithMeta(t *testing.T) {
 	repo := new(mocks.MockCategoryRepository)
 	pub := new(mocks.MockCategoryEventPublisher)
 
-	repo.On("Count", mock.Anything).Return(0, nil)
+	repo.On("Count", mock.Anything, domain.CategoryFilter{}).Return(0, nil)
 	repo.On("List", mock.Anything, p).Return([]*domain.Category{}, nil)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
@@ -198,7 +198,7 @@ func TestList_CountError_ReturnsError(t *testing.T) {
 	repo := new(mocks.MockCategoryRepository)
 	pub := new(mocks.MockCategoryEventPublisher)
 
-	repo.On("Count", mock.Anything).Return(0, assert.AnError)
+	repo.On("Count", mock.Anything, domain.CategoryFilter{}).Return(0, assert.AnError)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
 	result, err := svc.List(context.Background(), p)
@@ -210,13 +210,140 @@ func TestList_CountError_ReturnsError(t *testing.T) {
 	repo.AssertExpectations(t)
 }
 
+func TestList_Cursor_ReturnsNextCursorWithoutCounting(t *testing.T) {
+	now := time.Now()
+	categories := []*domain.Category{
+		{ID: "3", Name: "Toys", Slug: "toys", CreatedAt: now},
+		{ID: "2", Name: "Books", Slug: "books", CreatedAt: now.Add(-time.Minute)},
+		{ID: "1", Name: "Electronics", Slug: "electronics", CreatedAt: now.Add(-2 * time.Minute)},
+	}
+	cursor := ""
+	p := domain.PaginationParams{Limit: 2, Cursor: &cursor}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("ListAfter", mock.Anything, domain.PaginationParams{Page: 1, Limit: 3, Cursor: &cursor}, (*domain.CategoryCursor)(nil)).Return(categories, nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	result, err := svc.List(context.Background(), p)
+
+	require.NoError(t, err)
+	assert.Len(t, result.Data, 2)
+	require.NotEmpty(t, result.NextCursor)
+
+	next, err := domain.DecodeCategoryCursor(result.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, "2", next.ID)
+
+	repo.AssertNotCalled(t, "Count")
+	repo.AssertExpectations(t)
+}
+
+func TestList_Cursor_ContinuesAfterCursor(t *testing.T) {
+	last := &domain.Category{ID: "2", Name: "Books", Slug: "books", CreatedAt: time.Now().UTC()}
+	after := domain.NewCategoryCursor(last, domain.DefaultCategorySort)
+	cursor := after.Encode()
+	p := domain.PaginationParams{Limit: 2, Cursor: &cursor, WithTotal: true}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("ListAfter", mock.Anything, mock.Anything, mock.MatchedBy(func(c *domain.CategoryCursor) bool {
+		return c != nil && c.ID == after.ID && c.Time.Equal(after.Time)
+	})).Return([]*domain.Category{{ID: "1", Name: "Electronics", Slug: "electronics"}}, nil)
+	repo.On("Count", mock.Anything, domain.CategoryFilter{}).Return(3, nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	result, err := svc.List(context.Background(), p)
+
+	require.NoError(t, err)
+	assert.Len(t, result.Data, 1)
+	assert.Empty(t, result.NextCursor)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 2, result.TotalPages)
+
+	repo.AssertExpectations(t)
+}
+
+func TestList_InvalidCursor_ReturnsValidationError(t *testing.T) {
+	cursor := "not-a-cursor"
+	p := domain.PaginationParams{Limit: 10, Cursor: &cursor}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	result, err := svc.List(context.Background(), p)
+
+	assert.Nil(t, result)
+
+	var valErrs *validator.ErrorsValidator
+	assert.ErrorAs(t, err, &valErrs)
+
+	repo.AssertNotCalled(t, "ListAfter")
+}
+
+func TestList_SortAndFilter_PassedToRepository(t *testing.T) {
+	filter := domain.CategoryFilter{Query: "phone"}
+	p := domain.PaginationParams{Page: 1, Limit: 10, Sort: domain.SortNameAsc, Filter: domain.CategoryFilter{Query: "  phone "}}
+	expectedP := domain.PaginationParams{Page: 1, Limit: 10, Sort: domain.SortNameAsc, Filter: filter}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("Count", mock.Anything, filter).Return(1, nil)
+	repo.On("List", mock.Anything, expectedP).Return([]*domain.Category{{ID: "1", Name: "Phones", Slug: "phones"}}, nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	result, err := svc.List(context.Background(), p)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Total)
+
+	repo.AssertExpectations(t)
+}
+
+func TestList_UnknownSort_ReturnsValidationError(t *testing.T) {
+	p := domain.PaginationParams{Page: 1, Limit: 10, Sort: "id; DROP TABLE categories"}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	result, err := svc.List(context.Background(), p)
+
+	assert.Nil(t, result)
+
+	var valErrs *validator.ErrorsValidator
+	assert.ErrorAs(t, err, &valErrs)
+
+	repo.AssertNotCalled(t, "List")
+}
+
+func TestList_CursorFromOtherSort_ReturnsValidationError(t *testing.T) {
+	cursor := domain.NewCategoryCursor(&domain.Category{ID: "1", Name: "Books", Slug: "books"}, domain.SortNameAsc).Encode()
+	p := domain.PaginationParams{Limit: 10, Cursor: &cursor, Sort: domain.SortCreatedAtAsc}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	_, err := svc.List(context.Background(), p)
+
+	var valErrs *validator.ErrorsValidator
+	assert.ErrorAs(t, err, &valErrs)
+
+	repo.AssertNotCalled(t, "ListAfter")
+}
+
 func TestList_RepoError_ReturnsError(t *testing.T) {
 	p := domain.PaginationParams{Page: 1, Limit: 10}
 
 	repo := new(mocks.MockCategoryRepository)
 	pub := new(mocks.MockCategoryEventPublisher)
 
-	repo.On("Count", mock.Anything).Return(5, nil)
+	repo.On("Count", mock.Anything, domain.CategoryFilter{}).Return(5, nil)
 	repo.On("List", mock.Anything, p).Return(nil, assert.AnError)
 
 	svc := service.NewCategoryService(repo, pub, testLogger)
@@ -227,3 +354,104 @@ func TestList_RepoError_ReturnsError(t *testing.T) {
 
 	repo.AssertExpectations(t)
 }
+
+// ─── Hierarchy ────────────────────────────────────────────────────────────────
+
+func TestChildren_Success(t *testing.T) {
+	parentID := "parent-1"
+	children := []*domain.Category{
+		{ID: "1", Name: "Laptops", Slug: "laptops", ParentID: &parentID},
+		{ID: "2", Name: "Phones", Slug: "phones", ParentID: &parentID},
+	}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, parentID).Return(&domain.Category{ID: parentID}, nil)
+	repo.On("ListChildren", mock.Anything, parentID).Return(children, nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	result, err := svc.Children(context.Background(), parentID)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+
+	repo.AssertExpectations(t)
+}
+
+func TestChildren_NotFound_ReturnsErrNotFound(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, "not-exist").Return(nil, domain.ErrNotFound)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	result, err := svc.Children(context.Background(), "not-exist")
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.Nil(t, result)
+
+	repo.AssertNotCalled(t, "ListChildren")
+}
+
+func TestAncestors_Success(t *testing.T) {
+	rootID := "root"
+	ancestors := []*domain.Category{{ID: rootID, Name: "Electronics"}}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, "child").Return(&domain.Category{ID: "child", ParentID: &rootID}, nil)
+	repo.On("ListAncestors", mock.Anything, "child").Return(ancestors, nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	result, err := svc.Ancestors(context.Background(), "child")
+
+	assert.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, rootID, result[0].ID)
+
+	repo.AssertExpectations(t)
+}
+
+func TestTree_BuildsHierarchy(t *testing.T) {
+	electronicsID := "electronics"
+	phonesID := "phones"
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("ListAll", mock.Anything).Return([]*domain.Category{
+		{ID: "accessories", Name: "Accessories", Slug: "accessories", ParentID: &phonesID},
+		{ID: "books", Name: "Books", Slug: "books"},
+		{ID: electronicsID, Name: "Electronics"},
+		{ID: phonesID, Name: "Phones", ParentID: &electronicsID},
+	}, nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	tree, err := svc.Tree(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, tree, 2)
+	assert.Equal(t, "books", tree[0].Category.ID)
+	assert.Empty(t, tree[0].Children)
+
+	require.Len(t, tree[1].Children, 1)
+	phones := tree[1].Children[0]
+	assert.Equal(t, phonesID, phones.Category.ID)
+	require.Len(t, phones.Children, 1)
+	assert.Equal(t, "accessories", phones.Children[0].Category.ID)
+}
+
+func TestTree_RepoError_ReturnsError(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("ListAll", mock.Anything).Return(nil, assert.AnError)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	tree, err := svc.Tree(context.Background())
+
+	assert.Error(t, err)
+	assert.Nil(t, tree)
+}
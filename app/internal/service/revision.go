@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/pkg/actor"
+	"github.com/alfattd/category-service/internal/pkg/requestid"
+	"github.com/alfattd/category-service/internal/validator"
+	"github.com/google/uuid"
+)
+
+// record stores a revision for a change made in the current transaction. It does nothing without WithRevisions.
+func (s *CategoryService) record(ctx context.Context, action, categoryID string, before, after *domain.Category) error {
+	if s.revisions == nil {
+		return nil
+	}
+
+	return s.revisions.Add(ctx, &domain.CategoryRevision{
+		ID:         uuid.NewString(),
+		CategoryID: categoryID,
+		Action:     action,
+		Before:     before,
+		After:      after,
+		RequestID:  requestid.FromContext(ctx),
+		Actor:      actor.FromContext(ctx),
+		CreatedAt:  time.Now(),
+	})
+}
+
+// History returns the revisions of a category newest first. Deleted and purged categories keep their history.
+func (s *CategoryService) History(ctx context.Context, id string, p domain.PaginationParams) (*domain.PaginatedResult[*domain.CategoryRevision], error) {
+	id = strings.TrimSpace(id)
+
+	if errs := validator.CategoryIDValidator(id); errs != nil {
+		return nil, errs
+	}
+
+	p = normalizePage(p)
+
+	result := &domain.PaginatedResult[*domain.CategoryRevision]{
+		Data:       []*domain.CategoryRevision{},
+		Page:       p.Page,
+		Limit:      p.Limit,
+		TotalPages: 1,
+	}
+
+	if s.revisions == nil {
+		if _, err := s.repo.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	total, err := s.revisions.CountByCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if total == 0 {
+		// Without any revision the category can only be known if it predates history recording.
+		if _, err := s.repo.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	revisions, err := s.revisions.ListByCategory(ctx, id, p)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Data = revisions
+	result.Total = total
+	result.TotalPages = totalPages(total, p.Limit)
+
+	return result, nil
+}
@@ -0,0 +1,153 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/mocks"
+	"github.com/alfattd/category-service/internal/pkg/actor"
+	"github.com/alfattd/category-service/internal/pkg/requestid"
+	"github.com/alfattd/category-service/internal/service"
+	"github.com/alfattd/category-service/internal/validator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// ─── Recording ────────────────────────────────────────────────────────────────
+
+func TestUpdate_WithRevisions_RecordsBeforeAndAfter(t *testing.T) {
+	existing := &domain.Category{ID: "abc-123", Name: "Old Name", Slug: "old-name", Version: 1}
+
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+	revisions := new(mocks.MockRevisionRepository)
+
+	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	pub.On("PublishCategoryUpdated", mock.Anything, mock.AnythingOfType("*domain.Category"), mock.AnythingOfType("*domain.Category"), mock.Anything).Return(nil)
+
+	var recorded *domain.CategoryRevision
+	revisions.On("Add", mock.Anything, mock.AnythingOfType("*domain.CategoryRevision")).
+		Run(func(args mock.Arguments) { recorded = args.Get(1).(*domain.CategoryRevision) }).
+		Return(nil)
+
+	ctx := requestid.WithContext(context.Background(), "req-1")
+	ctx = actor.WithContext(ctx, "alice")
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithRevisions(revisions))
+	_, err := svc.Update(ctx, "abc-123", domain.UpdateCategoryParams{Name: "New Name"})
+
+	require.NoError(t, err)
+	require.NotNil(t, recorded)
+	assert.NotEmpty(t, recorded.ID)
+	assert.Equal(t, "abc-123", recorded.CategoryID)
+	assert.Equal(t, domain.RevisionUpdated, recorded.Action)
+	assert.Equal(t, "Old Name", recorded.Before.Name)
+	assert.Equal(t, "New Name", recorded.After.Name)
+	assert.Equal(t, "req-1", recorded.RequestID)
+	assert.Equal(t, "alice", recorded.Actor)
+	assert.False(t, recorded.CreatedAt.IsZero())
+}
+
+func TestDelete_WithRevisions_RecordsLastState(t *testing.T) {
+	last := &domain.Category{ID: "abc-123", Name: "Electronics", Slug: "electronics", Version: 2}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+	revisions := new(mocks.MockRevisionRepository)
+
+	repo.On("Delete", mock.Anything, "abc-123", int64(0)).Return(last, nil)
+	pub.On("PublishCategoryDeleted", mock.Anything, categoryWithID("abc-123")).Return(nil)
+	revisions.On("Add", mock.Anything, mock.MatchedBy(func(r *domain.CategoryRevision) bool {
+		return r.Action == domain.RevisionDeleted && r.Before == last && r.After == nil
+	})).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithRevisions(revisions))
+	err := svc.Delete(context.Background(), "abc-123", domain.DeleteCategoryParams{})
+
+	require.NoError(t, err)
+	revisions.AssertExpectations(t)
+}
+
+func TestCreate_RevisionError_ReturnsErrorAndDoesNotPublish(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	freeSlugs(repo)
+	pub := new(mocks.MockCategoryEventPublisher)
+	revisions := new(mocks.MockRevisionRepository)
+
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	revisions.On("Add", mock.Anything, mock.Anything).Return(assert.AnError)
+
+	svc := service.NewCategoryService(repo, pub, testLogger, service.WithRevisions(revisions))
+	cat, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: "Electronics"})
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Nil(t, cat)
+	pub.AssertNotCalled(t, "PublishCategoryCreated")
+}
+
+// ─── History ──────────────────────────────────────────────────────────────────
+
+func TestHistory_Success(t *testing.T) {
+	revs := []*domain.CategoryRevision{
+		{ID: "rev-2", CategoryID: "abc-123", Action: domain.RevisionUpdated},
+		{ID: "rev-1", CategoryID: "abc-123", Action: domain.RevisionCreated},
+	}
+
+	repo := new(mocks.MockCategoryRepository)
+	revisions := new(mocks.MockRevisionRepository)
+
+	revisions.On("CountByCategory", mock.Anything, "abc-123").Return(3, nil)
+	revisions.On("ListByCategory", mock.Anything, "abc-123", domain.PaginationParams{Page: 1, Limit: 2}).Return(revs, nil)
+
+	svc := service.NewCategoryService(repo, nil, testLogger, service.WithRevisions(revisions))
+	result, err := svc.History(context.Background(), "abc-123", domain.PaginationParams{Page: 1, Limit: 2})
+
+	require.NoError(t, err)
+	assert.Equal(t, revs, result.Data)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 2, result.TotalPages)
+	repo.AssertNotCalled(t, "GetByID")
+}
+
+func TestHistory_NoRevisions_UnknownCategory_ReturnsErrNotFound(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	revisions := new(mocks.MockRevisionRepository)
+
+	revisions.On("CountByCategory", mock.Anything, "abc-123").Return(0, nil)
+	repo.On("GetByID", mock.Anything, "abc-123").Return(nil, domain.ErrNotFound)
+
+	svc := service.NewCategoryService(repo, nil, testLogger, service.WithRevisions(revisions))
+	result, err := svc.History(context.Background(), "abc-123", domain.PaginationParams{})
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.Nil(t, result)
+}
+
+func TestHistory_NoRevisions_KnownCategory_ReturnsEmptyPage(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	revisions := new(mocks.MockRevisionRepository)
+
+	revisions.On("CountByCategory", mock.Anything, "abc-123").Return(0, nil)
+	repo.On("GetByID", mock.Anything, "abc-123").Return(&domain.Category{ID: "abc-123"}, nil)
+
+	svc := service.NewCategoryService(repo, nil, testLogger, service.WithRevisions(revisions))
+	result, err := svc.History(context.Background(), "abc-123", domain.PaginationParams{})
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Data)
+	assert.Equal(t, 1, result.Page)
+	assert.Equal(t, 10, result.Limit)
+	revisions.AssertNotCalled(t, "ListByCategory")
+}
+
+func TestHistory_EmptyID_ReturnsValidationError(t *testing.T) {
+	svc := service.NewCategoryService(new(mocks.MockCategoryRepository), nil, testLogger)
+	_, err := svc.History(context.Background(), " ", domain.PaginationParams{})
+
+	var errs *validator.ErrorsValidator
+	assert.ErrorAs(t, err, &errs)
+}
@@ -1,27 +1,147 @@
 package service
 
 import (
+	"context"
 	"log/slog"
+	"time"
 
 	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/pkg/eventmeta"
+	"github.com/alfattd/category-service/internal/pkg/requestid"
+	"github.com/google/uuid"
 )
 
+const defaultMaxDepth = 5
+
 type CategoryService struct {
 	repo      domain.CategoryRepository
 	publisher domain.CategoryEventPublisher
 	log       *slog.Logger
+	maxDepth  int
+	tx        domain.Transactor
+	outbox    domain.OutboxRepository
+	revisions domain.RevisionRepository
 }
 
 var _ domain.CategoryService = (*CategoryService)(nil)
 
+type Option func(*CategoryService)
+
+// WithMaxDepth limits how many levels a category hierarchy may have. Root categories are at depth 1.
+func WithMaxDepth(depth int) Option {
+	return func(s *CategoryService) {
+		if depth > 0 {
+			s.maxDepth = depth
+		}
+	}
+}
+
+// WithTransactor makes every write, together with its outbox rows, run in one transaction.
+func WithTransactor(tx domain.Transactor) Option {
+	return func(s *CategoryService) {
+		s.tx = tx
+	}
+}
+
+// WithOutbox records events in the outbox instead of publishing them directly.
+// It should be combined with WithTransactor backed by the same database.
+func WithOutbox(outbox domain.OutboxRepository) Option {
+	return func(s *CategoryService) {
+		s.outbox = outbox
+	}
+}
+
+// WithRevisions records a revision for every change, in the same transaction as the change itself.
+func WithRevisions(revisions domain.RevisionRepository) Option {
+	return func(s *CategoryService) {
+		s.revisions = revisions
+	}
+}
+
 func NewCategoryService(
 	repo domain.CategoryRepository,
 	publisher domain.CategoryEventPublisher,
 	log *slog.Logger,
+	opts ...Option,
 ) *CategoryService {
-	return &CategoryService{
+	s := &CategoryService{
 		repo:      repo,
 		publisher: publisher,
 		log:       log,
+		maxDepth:  defaultMaxDepth,
+		tx:        noTx{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+type noTx struct{}
+
+func (noTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (noTx) WithinSnapshot(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// pendingEventsKey marks a context whose commits are joined into an enclosing transaction.
+// Their events are collected there and published once that transaction has committed.
+type pendingEventsKey struct{}
+
+// commit runs fn in a transaction. The events fn returns are written to the outbox in
+// that same transaction, or published once it has committed when there is no outbox.
+func (s *CategoryService) commit(ctx context.Context, fn func(ctx context.Context) ([]domain.CategoryEvent, error)) error {
+	var events []domain.CategoryEvent
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if events, err = fn(ctx); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for i := range events {
+			events[i].ID, events[i].OccurredAt = uuid.NewString(), now
+		}
+
+		if s.outbox != nil {
+			return s.outbox.Add(ctx, events...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.outbox == nil {
+		if pending, ok := ctx.Value(pendingEventsKey{}).(*[]domain.CategoryEvent); ok {
+			*pending = append(*pending, events...)
+			return nil
+		}
+
+		for _, e := range events {
+			s.publish(ctx, e)
+		}
+	}
+
+	return nil
+}
+
+// publish delivers e directly. Failures are logged and do not fail the request.
+func (s *CategoryService) publish(ctx context.Context, e domain.CategoryEvent) {
+	pubCtx := eventmeta.WithContext(ctx, eventmeta.Meta{ID: e.ID, Time: e.OccurredAt})
+
+	if err := e.Publish(pubCtx, s.publisher); err != nil {
+		s.log.Error("failed to publish "+e.Type+" event",
+			"error", err,
+			"id", e.Category.ID,
+			"request_id", requestid.FromContext(ctx),
+		)
 	}
 }
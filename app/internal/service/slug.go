@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/pkg/slug"
+	"github.com/alfattd/category-service/internal/validator"
+)
+
+func (s *CategoryService) GetBySlug(ctx context.Context, slug string) (*domain.Category, error) {
+	slug = strings.TrimSpace(slug)
+
+	if slug == "" {
+		errs := &validator.ErrorsValidator{}
+		errs.Add("slug is required")
+		return nil, errs
+	}
+
+	return s.repo.GetBySlug(ctx, slug)
+}
+
+// needsSlug reports whether the slug of c follows its name: unless the write sets a slug of its
+// own, a rename, or removing the slug, gives c one derived from the name. before is nil for a new
+// category.
+func needsSlug(c, before *domain.Category) bool {
+	return c.Slug == "" || before != nil && c.Slug == before.Slug && c.Name != before.Name
+}
+
+// claimSlug settles the slug c is written with and has to run in the transaction that writes it.
+// With derive, c gets a slug derived from its name; otherwise the slug the write sets must not be
+// one another category has or had, since that keeps resolving to the other category. Writes of
+// slugs with the same root wait for each other, so concurrent ones cannot settle on the same slug.
+func (s *CategoryService) claimSlug(ctx context.Context, c *domain.Category, derive bool) error {
+	if derive {
+		if err := s.repo.LockSlug(ctx, slug.Root(slug.Make(c.Name))); err != nil {
+			return err
+		}
+
+		derived, err := s.uniqueSlug(ctx, c.ID, c.Name, nil)
+		if err != nil {
+			return err
+		}
+
+		c.Slug = derived
+		return nil
+	}
+
+	if err := s.repo.LockSlug(ctx, slug.Root(c.Slug)); err != nil {
+		return err
+	}
+
+	taken, err := s.repo.ListSlugs(ctx, c.Slug)
+	if err != nil {
+		return err
+	}
+
+	if owner, ok := taken[c.Slug]; ok && owner != c.ID {
+		return &domain.DuplicateError{Field: domain.FieldSlug, ID: owner}
+	}
+
+	return nil
+}
+
+// lockImportedSlugs takes the lock claimSlug takes for every root the rows may claim, whether
+// they set a slug or get one derived from their name. Roots are locked in order, so concurrent
+// imports cannot deadlock.
+func (s *CategoryService) lockImportedSlugs(ctx context.Context, rows []domain.ImportRow) error {
+	roots := make([]string, 0, 2*len(rows))
+	for _, r := range rows {
+		roots = append(roots, slug.Root(slug.Make(r.Name)))
+		if r.Slug != "" {
+			roots = append(roots, slug.Root(r.Slug))
+		}
+	}
+
+	slices.Sort(roots)
+	for _, root := range slices.Compact(roots) {
+		if err := s.repo.LockSlug(ctx, root); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// uniqueSlug derives a slug from name that no other category has or had, appending -2, -3 and
+// so on until one is free. reserved holds slugs, mapped to their category, that writes still to
+// be made are going to take.
+func (s *CategoryService) uniqueSlug(ctx context.Context, id, name string, reserved map[string]string) (string, error) {
+	base := slug.Make(name)
+
+	taken, err := s.repo.ListSlugs(ctx, base)
+	if err != nil {
+		return "", err
+	}
+
+	for n := 1; ; n++ {
+		candidate := slug.WithSuffix(base, n)
+
+		if owner, ok := taken[candidate]; ok && owner != id {
+			continue
+		}
+		if owner, ok := reserved[candidate]; ok && owner != id {
+			continue
+		}
+
+		return candidate, nil
+	}
+}
+
+// validateSlug checks a slug the client chose; an empty one is left to claimSlug.
+func validateSlug(slug string) *validator.ErrorsValidator {
+	if slug == "" {
+		return nil
+	}
+	return validator.CategorySlugValidator(slug)
+}
@@ -0,0 +1,196 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alfattd/category-service/internal/domain"
+	"github.com/alfattd/category-service/internal/mocks"
+	"github.com/alfattd/category-service/internal/service"
+	"github.com/alfattd/category-service/internal/validator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreate_DerivesSlugFromName(t *testing.T) {
+	tests := []struct {
+		name  string
+		taken map[string]string
+		want  string
+	}{
+		{"Home + Garden", map[string]string{}, "home-garden"},
+		{"Café Déjà Vu", map[string]string{}, "cafe-deja-vu"},
+		{"Книги", map[string]string{}, "knigi"},
+		{"Straße", map[string]string{}, "strasse"},
+		{"家居", map[string]string{}, "category"},
+		{"Home Garden", map[string]string{"home-garden": "other-1", "home-garden-2": "other-2"}, "home-garden-3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.MockCategoryRepository)
+			pub := new(mocks.MockCategoryEventPublisher)
+
+			repo.On("LockSlug", mock.Anything, mock.Anything).Return(nil)
+			repo.On("ListSlugs", mock.Anything, mock.Anything).Return(tt.taken, nil)
+			repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+			pub.On("PublishCategoryCreated", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+
+			svc := service.NewCategoryService(repo, pub, testLogger)
+			cat, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: tt.name})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cat.Slug)
+		})
+	}
+}
+
+func TestCreate_WithSlug_KeepsIt(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("LockSlug", mock.Anything, "garden").Return(nil)
+	repo.On("ListSlugs", mock.Anything, "garden").Return(map[string]string{"garden-2": "other-1"}, nil)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	pub.On("PublishCategoryCreated", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: "Home Garden", Slug: " garden "})
+
+	require.NoError(t, err)
+	assert.Equal(t, "garden", cat.Slug)
+	repo.AssertExpectations(t)
+}
+
+func TestCreate_WithTakenSlug_ReturnsDuplicateError(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+
+	// Locked by its root, so a concurrent write deriving "route-66-2" waits for this one.
+	repo.On("LockSlug", mock.Anything, "route").Return(nil)
+	repo.On("ListSlugs", mock.Anything, "route-66-2").Return(map[string]string{"route-66-2": "other-1"}, nil)
+
+	svc := service.NewCategoryService(repo, new(mocks.MockCategoryEventPublisher), testLogger)
+	_, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: "Route 66", Slug: "route-66-2"})
+
+	var dup *domain.DuplicateError
+	require.ErrorAs(t, err, &dup)
+	assert.Equal(t, domain.FieldSlug, dup.Field)
+	assert.Equal(t, "other-1", dup.ID)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreate_InvalidSlug_ReturnsValidationError(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+
+	svc := service.NewCategoryService(repo, new(mocks.MockCategoryEventPublisher), testLogger)
+	_, err := svc.Create(context.Background(), domain.CreateCategoryParams{Name: "Home Garden", Slug: "Home--Garden"})
+
+	var valErrs *validator.ErrorsValidator
+	require.ErrorAs(t, err, &valErrs)
+	assert.Equal(t, []string{"slug must consist of lowercase letters, digits and single hyphens between them"}, valErrs.Messages)
+	repo.AssertNotCalled(t, "Create")
+}
+
+func TestUpdate_Rename_DerivesSlugAndKeepsFormerOne(t *testing.T) {
+	existing := &domain.Category{ID: "abc-123", Name: "Garden", Slug: "garden", Version: 1}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
+	repo.On("LockSlug", mock.Anything, "outdoor").Return(nil)
+	repo.On("ListSlugs", mock.Anything, "outdoor").Return(map[string]string{"outdoor": "abc-123"}, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	repo.On("AddFormerSlug", mock.Anything, "abc-123", "garden").Return(nil)
+	pub.On("PublishCategoryUpdated", mock.Anything, mock.AnythingOfType("*domain.Category"), mock.AnythingOfType("*domain.Category"), []string{domain.FieldName, domain.FieldSlug}).Return(nil)
+
+	// "outdoor" used to belong to this very category, so it can have it back.
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Update(context.Background(), "abc-123", domain.UpdateCategoryParams{Name: "Outdoor"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "outdoor", cat.Slug)
+	repo.AssertExpectations(t)
+}
+
+func TestUpdate_RenameWithSlug_KeepsGivenSlug(t *testing.T) {
+	existing := &domain.Category{ID: "abc-123", Name: "Garden", Slug: "garden", Version: 1}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
+	repo.On("LockSlug", mock.Anything, "yard").Return(nil)
+	repo.On("ListSlugs", mock.Anything, "yard").Return(map[string]string{"yard": "abc-123"}, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	repo.On("AddFormerSlug", mock.Anything, "abc-123", "garden").Return(nil)
+	pub.On("PublishCategoryUpdated", mock.Anything, mock.AnythingOfType("*domain.Category"), mock.AnythingOfType("*domain.Category"), []string{domain.FieldName, domain.FieldSlug}).Return(nil)
+
+	// "yard" used to belong to this very category, so it can have it back.
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Update(context.Background(), "abc-123", domain.UpdateCategoryParams{Name: "Outdoor", Slug: "yard"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "yard", cat.Slug)
+	repo.AssertExpectations(t)
+}
+
+func TestUpdate_SameName_KeepsSlug(t *testing.T) {
+	parentID := "parent-1"
+	existing := &domain.Category{ID: "abc-123", Name: "Garden", Slug: "my-garden", Version: 1}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
+	repo.On("GetByID", mock.Anything, parentID).Return(&domain.Category{ID: parentID}, nil)
+	repo.On("LockHierarchy", mock.Anything).Return(nil)
+	repo.On("ListAncestors", mock.Anything, parentID).Return([]*domain.Category{}, nil)
+	repo.On("SubtreeHeight", mock.Anything, "abc-123").Return(1, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	pub.On("PublishCategoryUpdated", mock.Anything, mock.AnythingOfType("*domain.Category"), mock.AnythingOfType("*domain.Category"), []string{domain.FieldParentID}).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Update(context.Background(), "abc-123", domain.UpdateCategoryParams{Name: "Garden", ParentID: &parentID})
+
+	require.NoError(t, err)
+	assert.Equal(t, "my-garden", cat.Slug)
+	repo.AssertNotCalled(t, "LockSlug", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "ListSlugs", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "AddFormerSlug", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPatch_RemoveSlug_DerivesItAgain(t *testing.T) {
+	existing := &domain.Category{ID: "abc-123", Name: "Garden", Slug: "my-garden", Version: 1}
+
+	repo := new(mocks.MockCategoryRepository)
+	pub := new(mocks.MockCategoryEventPublisher)
+
+	repo.On("GetByID", mock.Anything, "abc-123").Return(existing, nil)
+	repo.On("LockSlug", mock.Anything, "garden").Return(nil)
+	repo.On("ListSlugs", mock.Anything, "garden").Return(map[string]string{}, nil)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Category")).Return(nil)
+	repo.On("AddFormerSlug", mock.Anything, "abc-123", "my-garden").Return(nil)
+	pub.On("PublishCategoryUpdated", mock.Anything, mock.AnythingOfType("*domain.Category"), mock.AnythingOfType("*domain.Category"), []string{domain.FieldSlug}).Return(nil)
+
+	svc := service.NewCategoryService(repo, pub, testLogger)
+	cat, err := svc.Patch(context.Background(), "abc-123", domain.PatchCategoryParams{
+		Ops: []domain.PatchOp{{Op: domain.PatchRemove, Path: domain.FieldSlug}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "garden", cat.Slug)
+	repo.AssertExpectations(t)
+}
+
+func TestGetBySlug_EmptySlug_ReturnsValidationError(t *testing.T) {
+	repo := new(mocks.MockCategoryRepository)
+
+	svc := service.NewCategoryService(repo, new(mocks.MockCategoryEventPublisher), testLogger)
+	_, err := svc.GetBySlug(context.Background(), " ")
+
+	var valErrs *validator.ErrorsValidator
+	assert.ErrorAs(t, err, &valErrs)
+	repo.AssertNotCalled(t, "GetBySlug", mock.Anything, mock.Anything)
+}
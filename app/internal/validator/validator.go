@@ -1,8 +1,14 @@
 package validator
 
 import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"unicode"
+
+	"github.com/alfattd/category-service/internal/domain"
 )
 
 type ErrorsValidator struct {
@@ -78,6 +84,167 @@ func CategoryIDValidator(id string) *ErrorsValidator {
 	return nil
 }
 
+func CategoryParentIDValidator(parentID *string) *ErrorsValidator {
+	if parentID == nil {
+		return nil
+	}
+
+	errs := &ErrorsValidator{}
+
+	if hasOnlyWhitespace(*parentID) {
+		errs.Add("parent_id must not be blank")
+		return errs
+	}
+
+	return nil
+}
+
+const MaxCategorySlugLength = 60
+
+// CategorySlugValidator checks a slug a client chose. Slugs derived from names always pass.
+func CategorySlugValidator(slug string) *ErrorsValidator {
+	errs := &ErrorsValidator{}
+
+	if len(slug) > MaxCategorySlugLength {
+		errs.Add(fmt.Sprintf("slug must not exceed %d characters", MaxCategorySlugLength))
+	}
+
+	if !slugPattern.MatchString(slug) {
+		errs.Add("slug must consist of lowercase letters, digits and single hyphens between them")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+
+	return nil
+}
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+var patchableFields = []string{domain.FieldName, domain.FieldSlug, domain.FieldParentID}
+
+// CategoryPatchValidator checks that every operation is a JSON Patch operation on a field
+// clients may change. Values are left to the validators of the fields they end up in.
+func CategoryPatchValidator(ops []domain.PatchOp) *ErrorsValidator {
+	errs := &ErrorsValidator{}
+
+	for _, op := range ops {
+		switch op.Op {
+		case domain.PatchAdd, domain.PatchRemove, domain.PatchReplace, domain.PatchTest:
+		case domain.PatchMove, domain.PatchCopy:
+			if !slices.Contains(patchableFields, op.From) {
+				errs.Add(fmt.Sprintf("/%s cannot be patched", op.From))
+			}
+		default:
+			errs.Add(fmt.Sprintf("op %q is not supported", op.Op))
+			continue
+		}
+
+		if !slices.Contains(patchableFields, op.Path) {
+			errs.Add(fmt.Sprintf("/%s cannot be patched", op.Path))
+		}
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+
+	return nil
+}
+
+const MaxBatchSize = 500
+
+func BatchSizeValidator(n int) *ErrorsValidator {
+	errs := &ErrorsValidator{}
+
+	if n == 0 {
+		errs.Add("operations must not be empty")
+		return errs
+	}
+
+	if n > MaxBatchSize {
+		errs.Add(fmt.Sprintf("operations must not exceed %d items", MaxBatchSize))
+		return errs
+	}
+
+	return nil
+}
+
+func ImportModeValidator(mode string) *ErrorsValidator {
+	switch mode {
+	case domain.ImportCreateOnly, domain.ImportUpsert, domain.ImportReplace:
+		return nil
+	}
+
+	errs := &ErrorsValidator{}
+	errs.Add(fmt.Sprintf("mode must be one of %s, %s or %s", domain.ImportCreateOnly, domain.ImportUpsert, domain.ImportReplace))
+	return errs
+}
+
+const MaxAPIKeyNameLength = 100
+
+func APIKeyNameValidator(name string) *ErrorsValidator {
+	errs := &ErrorsValidator{}
+
+	if hasOnlyWhitespace(name) {
+		errs.Add("name is required")
+		return errs
+	}
+
+	if len([]rune(name)) > MaxAPIKeyNameLength {
+		errs.Add("name must not exceed 100 characters")
+	}
+
+	if hasForbiddenRunes(name) {
+		errs.Add("name contains invalid characters (< > \" ' ; & \\ / { } ( ) [ ] are not allowed)")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+
+	return nil
+}
+
+func APIKeyIDValidator(id string) *ErrorsValidator {
+	errs := &ErrorsValidator{}
+
+	if id == "" {
+		errs.Add("api key id is required")
+		return errs
+	}
+
+	if hasOnlyWhitespace(id) {
+		errs.Add("api key id must not be blank")
+		return errs
+	}
+
+	return nil
+}
+
+// APIKeyScopesValidator requires at least one scope, each taken from allowed.
+func APIKeyScopesValidator(scopes, allowed []string) *ErrorsValidator {
+	errs := &ErrorsValidator{}
+
+	if len(scopes) == 0 {
+		errs.Add("scopes must not be empty")
+		return errs
+	}
+
+	for _, s := range scopes {
+		if !slices.Contains(allowed, s) {
+			errs.Add("scope " + strconv.Quote(s) + " is not one of " + strings.Join(allowed, ", "))
+		}
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+
+	return nil
+}
+
 func hasForbiddenRunes(s string) bool {
 	for _, r := range s {
 		if forbiddenRunes[r] {
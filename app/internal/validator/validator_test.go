@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/alfattd/category-service/internal/domain"
 	"github.com/alfattd/category-service/internal/validator"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -118,6 +119,85 @@ func TestValidateCategoryID_WhitespaceOnly(t *testing.T) {
 	}
 }
 
+// ─── CategoryParentIDValidator ────────────────────────────────────────────────
+
+func TestValidateCategoryParentID_Nil(t *testing.T) {
+	errs := validator.CategoryParentIDValidator(nil)
+	assert.Nil(t, errs)
+}
+
+func TestValidateCategoryParentID_Valid(t *testing.T) {
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	errs := validator.CategoryParentIDValidator(&id)
+	assert.Nil(t, errs)
+}
+
+func TestValidateCategoryParentID_Blank(t *testing.T) {
+	cases := []string{"", "   ", "\t"}
+	for _, id := range cases {
+		t.Run("blank", func(t *testing.T) {
+			errs := validator.CategoryParentIDValidator(&id)
+			require.NotNil(t, errs)
+			assert.Contains(t, errs.Messages, "parent_id must not be blank")
+		})
+	}
+}
+
+// ─── CategoryPatchValidator ───────────────────────────────────────────────────
+
+func TestValidateCategoryPatch_Valid(t *testing.T) {
+	ops := []domain.PatchOp{
+		{Op: domain.PatchReplace, Path: domain.FieldName},
+		{Op: domain.PatchRemove, Path: domain.FieldParentID},
+		{Op: domain.PatchMove, Path: domain.FieldParentID, From: domain.FieldName},
+		{Op: domain.PatchTest, Path: domain.FieldName},
+	}
+
+	assert.Nil(t, validator.CategoryPatchValidator(ops))
+	assert.Nil(t, validator.CategoryPatchValidator(nil))
+}
+
+func TestValidateCategoryPatch_Invalid(t *testing.T) {
+	errs := validator.CategoryPatchValidator([]domain.PatchOp{
+		{Op: "merge", Path: domain.FieldName},
+		{Op: domain.PatchReplace, Path: "id"},
+		{Op: domain.PatchCopy, Path: domain.FieldName, From: "version"},
+	})
+
+	require.NotNil(t, errs)
+	assert.Equal(t, []string{
+		`op "merge" is not supported`,
+		"/id cannot be patched",
+		"/version cannot be patched",
+	}, errs.Messages)
+}
+
+// ─── APIKeyIDValidator ────────────────────────────────────────────────────────
+
+func TestValidateAPIKeyID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want []string
+	}{
+		{"valid", "550e8400-e29b-41d4-a716-446655440000", nil},
+		{"empty", "", []string{"api key id is required"}},
+		{"whitespace only", " \t", []string{"api key id must not be blank"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validator.APIKeyIDValidator(tt.id)
+			if tt.want == nil {
+				assert.Nil(t, errs)
+				return
+			}
+			require.NotNil(t, errs)
+			assert.Equal(t, tt.want, errs.Messages)
+		})
+	}
+}
+
 // ─── ErrorsValidator ──────────────────────────────────────────────────────────
 
 func TestValidationErrors_Error(t *testing.T) {
@@ -0,0 +1,4 @@
+ALTER TABLE categories
+    ADD COLUMN parent_id TEXT REFERENCES categories (id);
+
+CREATE INDEX idx_categories_parent_id ON categories (parent_id);
@@ -0,0 +1 @@
+CREATE INDEX idx_categories_created_at_id ON categories (created_at DESC, id DESC);
@@ -0,0 +1,7 @@
+DELETE FROM categories WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_categories_name_live;
+
+ALTER TABLE categories ADD CONSTRAINT categories_name_key UNIQUE (name);
+
+ALTER TABLE categories DROP COLUMN IF EXISTS deleted_at;
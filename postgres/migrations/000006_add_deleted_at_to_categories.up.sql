@@ -0,0 +1,7 @@
+ALTER TABLE categories
+    ADD COLUMN deleted_at TIMESTAMP;
+
+-- Names only have to be unique among live categories, so a deleted name can be reused.
+ALTER TABLE categories DROP CONSTRAINT categories_name_key;
+
+CREATE UNIQUE INDEX idx_categories_name_live ON categories (name) WHERE deleted_at IS NULL;
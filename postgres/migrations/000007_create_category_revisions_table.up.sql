@@ -0,0 +1,14 @@
+-- No foreign key on category_id: the history of a purged category is kept.
+CREATE TABLE category_revisions (
+    seq BIGSERIAL PRIMARY KEY,
+    id TEXT NOT NULL UNIQUE,
+    category_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    before JSONB,
+    after JSONB,
+    request_id TEXT NOT NULL DEFAULT '',
+    actor TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_category_revisions_category_id ON category_revisions (category_id, seq DESC);
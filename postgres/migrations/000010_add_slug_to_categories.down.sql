@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS category_slugs;
+
+DROP INDEX IF EXISTS idx_categories_slug;
+DROP INDEX IF EXISTS idx_categories_slug_live;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS slug;
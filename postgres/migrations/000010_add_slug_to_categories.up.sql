@@ -0,0 +1,29 @@
+ALTER TABLE categories
+    ADD COLUMN slug TEXT;
+
+-- The service transliterates names when it derives slugs; existing rows only keep their ASCII letters and digits.
+UPDATE categories
+SET slug = COALESCE(NULLIF(trim(BOTH '-' FROM regexp_replace(lower(name), '[^a-z0-9]+', '-', 'g')), ''), 'category');
+
+-- Where names map to the same slug, live categories and then older ones keep it as it is.
+UPDATE categories c
+SET slug = c.slug || '-' || c.id
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY slug ORDER BY deleted_at IS NOT NULL, created_at, id) AS n
+    FROM categories
+) ranked
+WHERE ranked.id = c.id AND ranked.n > 1;
+
+ALTER TABLE categories ALTER COLUMN slug SET NOT NULL;
+
+CREATE UNIQUE INDEX idx_categories_slug_live ON categories (slug) WHERE deleted_at IS NULL;
+CREATE INDEX idx_categories_slug ON categories (slug text_pattern_ops);
+
+-- Slugs categories had before, so that old links keep working after a rename.
+CREATE TABLE category_slugs (
+    slug TEXT PRIMARY KEY,
+    category_id TEXT NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_category_slugs_category_id ON category_slugs (category_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_categories_name_key_live;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS name_key;
+
+CREATE UNIQUE INDEX idx_categories_name_live ON categories (name) WHERE deleted_at IS NULL;
+
+DROP FUNCTION IF EXISTS category_name_key(TEXT);
+DROP FUNCTION IF EXISTS category_name_normalize(TEXT);
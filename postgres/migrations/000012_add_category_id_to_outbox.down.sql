@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_outbox_sent_at;
+DROP INDEX IF EXISTS idx_outbox_pending_category;
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS category_id;
@@ -0,0 +1,8 @@
+ALTER TABLE outbox
+    ADD COLUMN category_id TEXT NOT NULL DEFAULT '';
+
+-- Lets the relay hold back a category's events while an earlier one is still pending.
+CREATE INDEX idx_outbox_pending_category ON outbox (category_id, seq) WHERE sent_at IS NULL;
+
+-- Lets the relay delete sent messages once they are past retention.
+CREATE INDEX idx_outbox_sent_at ON outbox (sent_at) WHERE sent_at IS NOT NULL;